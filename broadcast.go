@@ -1,6 +1,14 @@
 package gorgonia
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+
+	"github.com/chewxy/gorgonia/tensor"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/pkg/errors"
+)
 
 const (
 	bcAllowableAxes = 4
@@ -59,6 +67,9 @@ func (bcpat BroadcastPattern) on() (retVal [2][]int) {
 }
 
 // Broadcast works somewhat like Numpy's broadcast, except it's now exposed as a function.
+//
+// Elementwise binary operations such as Add and HadamardProd already broadcast their operands automatically,
+// so Broadcast is only needed when a specific pattern is wanted.
 func Broadcast(binOp ʘBinaryOperatorType, a, b *Node, pattern BroadcastPattern) (retVal *Node, err error) {
 	broadcastOn := pattern.on()
 
@@ -102,3 +113,264 @@ func Broadcast(binOp ʘBinaryOperatorType, a, b *Node, pattern BroadcastPattern)
 	op := newElemBinOp(binOp, x, y)
 	return applyOp(op, x, y)
 }
+
+// autoBroadcast broadcasts a and b to a common shape, the way Numpy does it: the shapes are aligned from the trailing axis,
+// and any axis of size 1 is stretched to match the other operand. There are no limits on the number of axes.
+//
+// Nodes that already have the same shape, scalars, and nodes whose shapes are not yet known are returned as is.
+func autoBroadcast(a, b *Node) (x, y *Node, err error) {
+	x, y = a, b
+	if a.IsScalar() || b.IsScalar() || a.shape == nil || b.shape == nil || a.shape.Eq(b.shape) {
+		return
+	}
+
+	var to types.Shape
+	if to, err = broadcastShape(a.shape, b.shape); err != nil {
+		return
+	}
+
+	if !a.shape.Eq(to) {
+		if x, err = applyOp(newBroadcastOp(a.shape, to), a); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+
+	if !b.shape.Eq(to) {
+		if y, err = applyOp(newBroadcastOp(b.shape, to), b); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	return
+}
+
+// broadcastShape returns the shape that both a and b broadcast to.
+func broadcastShape(a, b types.Shape) (retVal types.Shape, err error) {
+	dims := len(a)
+	if len(b) > dims {
+		dims = len(b)
+	}
+
+	retVal = make(types.Shape, dims)
+	for i := 1; i <= dims; i++ {
+		da, db := 1, 1
+		if i <= len(a) {
+			da = a[len(a)-i]
+		}
+		if i <= len(b) {
+			db = b[len(b)-i]
+		}
+
+		switch {
+		case da == db, db == 1:
+			retVal[dims-i] = da
+		case da == 1:
+			retVal[dims-i] = db
+		default:
+			err = NewError(ShapeError, "Cannot broadcast %v and %v: axis %d has sizes %d and %d", a, b, dims-i, da, db)
+			return
+		}
+	}
+	return
+}
+
+// broadcastAxes returns the axes of `to` along which `from` has to be stretched.
+// `from` is aligned with the trailing axes of `to`.
+func broadcastAxes(from, to types.Shape) (retVal axes) {
+	if from.IsScalar() {
+		from = nil
+	}
+
+	offset := len(to) - len(from)
+	for i, d := range to {
+		if i < offset || (from[i-offset] == 1 && d != 1) {
+			retVal = append(retVal, i)
+		}
+	}
+	return
+}
+
+/* BROADCAST OP */
+
+// broadcastOp stretches a value of shape `from` to shape `to`.
+type broadcastOp struct {
+	from, to types.Shape
+}
+
+func newBroadcastOp(from, to types.Shape) broadcastOp {
+	return broadcastOp{
+		from: from.Clone(),
+		to:   to.Clone(),
+	}
+}
+
+// broadcastOp is a function with this type:
+//		broadcastOp :: (Floats a) ⇒ Tensor d a → Tensor d' a
+// where d' >= d. Scalars can be broadcast too.
+func (op broadcastOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+
+	var in Type = a
+	if d := op.from.Dims(); d > 0 {
+		in = newTensorType(d, a)
+	}
+	return newFunctionType(in, newTensorType(op.to.Dims(), a))
+}
+
+func (op broadcastOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "broadcastOp expects 1 input. Got %d instead", len(inputs))
+		return
+	}
+	return op.to.Clone(), nil
+}
+
+func (op broadcastOp) DiffWRT(inputs int) []bool { return []bool{true} }
+
+// SymDiff sums the gradient along the broadcast axes, and reshapes it back into the shape of the input.
+func (op broadcastOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "broadcastOp expects 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	var n *Node
+	if n, err = Sum(gradNode, broadcastAxes(op.from, op.to)...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	n.setGroup(gradClust)
+
+	if !op.from.IsScalar() {
+		if n, err = Reshape(n, op.from...); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		n.setGroup(gradClust)
+	}
+	return Nodes{n}, nil
+}
+
+// FwdDiff broadcasts the tangent.
+func (op broadcastOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op broadcastOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "broadcastOp expects 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var dy interface{}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+	idx, _ := reductionIndices(op.to, broadcastAxes(op.from, op.to))
+	return accumulateDeriv(xdv, func(dx interface{}) error { return reduceData(dx, dy, idx) })
+}
+
+func (op broadcastOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "broadcastOp expects 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	var src interface{}
+	if src, err = valueData(inputs[0]); err != nil {
+		return
+	}
+
+	idx, _ := reductionIndices(op.to, broadcastAxes(op.from, op.to))
+	t := tensor.Zeroes(dtypeOfData(src), len(idx))
+	if err = broadcastData(t.Data(), src, idx, false); err != nil {
+		return
+	}
+
+	if err = t.Reshape(op.to...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, op.to, t.DataSize())
+		return
+	}
+	retVal = FromTensor(t)
+	return
+}
+
+func (op broadcastOp) returnsPtr() bool    { return false }
+func (op broadcastOp) callsExtern() bool   { return false }
+func (op broadcastOp) overwriteInput() int { return -1 }
+
+func (op broadcastOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("broadcast"))
+	fmt.Fprintf(h, "%v->%v", op.from, op.to)
+}
+
+func (op broadcastOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op broadcastOp) String() string { return fmt.Sprintf("Broadcast%v", op.to) }
+func (op broadcastOp) isUnary() bool  { return true }
+
+// broadcastData writes (or adds, if incr is true) src[idx[i]] into dst[i].
+func broadcastData(dst, src interface{}, idx []int, incr bool) error {
+	switch d := dst.(type) {
+	case []float64:
+		s, ok := src.([]float64)
+		if !ok {
+			return NewError(RuntimeError, "Expected src to be []float64. Got %T instead", src)
+		}
+		for i, j := range idx {
+			if incr {
+				d[i] += s[j]
+			} else {
+				d[i] = s[j]
+			}
+		}
+	case []float32:
+		s, ok := src.([]float32)
+		if !ok {
+			return NewError(RuntimeError, "Expected src to be []float32. Got %T instead", src)
+		}
+		for i, j := range idx {
+			if incr {
+				d[i] += s[j]
+			} else {
+				d[i] = s[j]
+			}
+		}
+	default:
+		return nyi("broadcastData", dst)
+	}
+	return nil
+}
+
+// reduceData adds src[i] into dst[idx[i]]. It is the reverse of broadcastData.
+func reduceData(dst, src interface{}, idx []int) error {
+	switch d := dst.(type) {
+	case []float64:
+		s, ok := src.([]float64)
+		if !ok {
+			return NewError(RuntimeError, "Expected src to be []float64. Got %T instead", src)
+		}
+		for i, j := range idx {
+			d[j] += s[i]
+		}
+	case []float32:
+		s, ok := src.([]float32)
+		if !ok {
+			return NewError(RuntimeError, "Expected src to be []float32. Got %T instead", src)
+		}
+		for i, j := range idx {
+			d[j] += s[i]
+		}
+	default:
+		return nyi("reduceData", dst)
+	}
+	return nil
+}
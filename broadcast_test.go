@@ -1,9 +1,11 @@
 package gorgonia
 
 import (
+	"fmt"
 	"testing"
 
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,3 +80,118 @@ func TestBroadcast2(t *testing.T) {
 	assert.Equal([]float64{100, 101, 102, 203, 204, 205}, extractF64s(z.Value()))
 
 }
+
+// naiveBroadcastIndex returns the index of the element of a tensor of shape `from` that
+// the ith element of the broadcast result of shape `to` comes from.
+func naiveBroadcastIndex(from, to types.Shape, i int) (retVal int) {
+	offset := len(to) - len(from)
+	coords := make([]int, len(to))
+	for j := len(to) - 1; j >= 0; j-- {
+		coords[j] = i % to[j]
+		i /= to[j]
+	}
+
+	stride := 1
+	for j := len(from) - 1; j >= 0; j-- {
+		if from[j] != 1 {
+			retVal += coords[j+offset] * stride
+		}
+		stride *= from[j]
+	}
+	return
+}
+
+func TestAutoBroadcast(t *testing.T) {
+	assert := assert.New(t)
+	var autoBroadcastTests = []struct {
+		a, b, out types.Shape
+		mul       bool
+	}{
+		{types.Shape{2, 3}, types.Shape{3}, types.Shape{2, 3}, false},
+		{types.Shape{2, 3}, types.Shape{1, 3}, types.Shape{2, 3}, true},
+		{types.Shape{2, 3}, types.Shape{2, 1}, types.Shape{2, 3}, true},
+		{types.Shape{2, 1}, types.Shape{2, 3}, types.Shape{2, 3}, false},
+		{types.Shape{2, 3, 4}, types.Shape{3, 1}, types.Shape{2, 3, 4}, true},
+		{types.Shape{2, 1, 4}, types.Shape{3, 1}, types.Shape{2, 3, 4}, true},
+		{types.Shape{4}, types.Shape{2, 3, 1}, types.Shape{2, 3, 4}, false},
+	}
+
+	for i, bct := range autoBroadcastTests {
+		name := fmt.Sprintf("Test %d", i)
+		aBack := tf64.RangeFloat64(1, bct.a.TotalSize()+1)
+		bBack := tf64.RangeFloat64(2, bct.b.TotalSize()+2)
+
+		size := bct.out.TotalSize()
+		correct := make([]float64, size)
+		aGrad := make([]float64, len(aBack))
+		bGrad := make([]float64, len(bBack))
+		for j := range correct {
+			ai := naiveBroadcastIndex(bct.a, bct.out, j)
+			bi := naiveBroadcastIndex(bct.b, bct.out, j)
+			if bct.mul {
+				correct[j] = aBack[ai] * bBack[bi]
+				aGrad[ai] += bBack[bi]
+				bGrad[bi] += aBack[ai]
+			} else {
+				correct[j] = aBack[ai] + bBack[bi]
+				aGrad[ai]++
+				bGrad[bi]++
+			}
+		}
+
+		build := func() (g *ExprGraph, a, b, z, cost *Node) {
+			g = NewGraph()
+			a = NewTensor(g, Float64, bct.a.Dims(), WithName("a"), WithShape(bct.a...), WithValue(tf64.NewTensor(tf64.WithShape(bct.a...), tf64.WithBacking(append([]float64(nil), aBack...)))))
+			b = NewTensor(g, Float64, bct.b.Dims(), WithName("b"), WithShape(bct.b...), WithValue(tf64.NewTensor(tf64.WithShape(bct.b...), tf64.WithBacking(append([]float64(nil), bBack...)))))
+			if bct.mul {
+				z = Must(HadamardProd(a, b))
+			} else {
+				z = Must(Add(a, b))
+			}
+			cost = Must(Sum(z))
+			return
+		}
+
+		g, a, b, z, cost := build()
+		assert.True(bct.out.Eq(z.Shape()), name)
+		if _, err := Grad(cost, a, b); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		m := NewTapeMachine(prog, locMap)
+		if err = m.RunAll(); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		assert.Equal(correct, extractF64s(z.Value()), name)
+		aG, _ := a.Grad()
+		bG, _ := b.Grad()
+		assert.Equal(aGrad, extractF64s(aG), name)
+		assert.Equal(bGrad, extractF64s(bG), name)
+
+		// lisp machine
+		g2, a2, b2, z2, _ := build()
+		m2 := NewLispMachine(g2)
+		if err = m2.RunAll(); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		assert.Equal(correct, extractF64s(z2.Value()), name)
+		a2G, _ := a2.Grad()
+		b2G, _ := b2.Grad()
+		assert.Equal(aGrad, extractF64s(a2G), name)
+		assert.Equal(bGrad, extractF64s(b2G), name)
+	}
+
+	// incompatible shapes
+	g := NewGraph()
+	a := NewMatrix(g, Float64, WithName("a"), WithShape(2, 3))
+	b := NewVector(g, Float64, WithName("b"), WithShape(2))
+	_, err := Add(a, b)
+	assert.NotNil(err)
+}
@@ -72,6 +72,11 @@ func backwardDiffAnalysis(wrt, sortedNodes Nodes) (retVal NodeSet, err error) {
 	for i := len(sortedNodes) - 1; i >= 0; i-- {
 		n := sortedNodes[i]
 		symdiffLogf("working on %v. Has %d children", n, len(n.children))
+		if isStopGradient(n) {
+			// nothing that comes after a StopGradient is affected by its input
+			symdiffLogf("Gradients stop at %v", n)
+			continue
+		}
 		diffs := n.diffWRT()
 
 		symdiffLogf("differentiable WRT: %v", diffs)
@@ -144,6 +149,7 @@ func Backpropagate(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
 	defer leaveLoggingContext()
 
 	g := outputs[0].g
+	unstabilize(wrt)
 
 	// this entire section about removing foreveralone nodes need a rethink
 	symdiffLogf("removing foreveralone nodes")
@@ -227,12 +233,6 @@ func Backpropagate(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
 			continue
 		}
 
-		if node.deriv != nil {
-			symdiffLogf("skipping %x - previously differentiated", node.ID())
-			nodeGradMap[node] = append(nodeGradMap[node], node.deriv)
-			continue
-		}
-
 		symdiffLogf("Working on %x %v", node.ID(), node)
 		enterLoggingContext()
 
@@ -316,3 +316,486 @@ func Backpropagate(outputs, gradOutputs, wrt Nodes) (retVal Nodes, err error) {
 	}
 	return
 }
+
+// JVP computes the Jacobian-vector products of the outputs with the tangents, by forward mode differentiation.
+// tangents[i] is the direction in which inputs[i] is moved, and must have the same type and shape as it.
+// A node is returned for each output. It holds Σ Jᵢtᵢ, where Jᵢ is the Jacobian of the output wrt inputs[i].
+//
+// The tangents are pushed through the graph alongside the values: every op between the inputs and the outputs gets a
+// node that computes the tangent of its output from the tangents of its inputs, which is done by the FwdDiff of its Op.
+// Run the graph on a *tapeMachine, or on a *lispMachine with ExecuteFwdOnly() to compute the products.
+// As only one pass is required per set of tangents, this is much cheaper than reverse mode when there are few inputs and many outputs.
+func JVP(outputs, inputs, tangents Nodes) (retVal Nodes, err error) {
+	symdiffLogf("JVP START")
+	enterLoggingContext()
+	defer leaveLoggingContext()
+
+	if len(outputs) == 0 {
+		err = NewError(SymbDiffError, "JVP requires at least one output")
+		return
+	}
+	if len(inputs) != len(tangents) {
+		err = NewError(SymbDiffError, "JVP expects a tangent for each input. Got %d inputs and %d tangents", len(inputs), len(tangents))
+		return
+	}
+
+	g := outputs[0].g
+	nodeTangentMap := make(map[*Node]*Node)
+	for i, n := range inputs {
+		t := tangents[i]
+		if !n.isInput() {
+			err = NewError(SymbDiffError, "JVP can only differentiate with regards to input nodes. %v isn't one", n)
+			return
+		}
+		if !typeEq(n.t, t.t) || !n.shape.Eq(t.shape) {
+			err = NewError(SymbDiffError, "Expected the tangent of %v to be a %v of %v. Got a %v of %v instead", n, n.t, n.shape, t.t, t.shape)
+			return
+		}
+		nodeTangentMap[n] = g.AddNode(t)
+	}
+
+	var sortedNodes Nodes
+	if sortedNodes, err = Sort(g); err != nil {
+		err = errors.Wrap(err, sortFail)
+		return
+	}
+
+	var affectsOutput NodeSet
+	if affectsOutput, err = forwardDiffAnalysis(outputs, sortedNodes); err != nil {
+		err = errors.Wrap(err, "Failed during forward differentiation analysis")
+		return
+	}
+
+	// the nodes are sorted with the outputs first, so the tangents are pushed from the back
+	for i := len(sortedNodes) - 1; i >= 0; i-- {
+		node := sortedNodes[i]
+		if node.isInput() || !affectsOutput.Contains(node) {
+			continue
+		}
+
+		diffs := node.diffWRT()
+		childTangents := make(Nodes, len(node.children))
+		var moved bool
+		for j, child := range node.children {
+			if j < len(diffs) && diffs[j] {
+				childTangents[j] = nodeTangentMap[child]
+				moved = moved || childTangents[j] != nil
+			}
+		}
+		if !moved {
+			continue
+		}
+
+		fdo, ok := node.op.(FwdDiffOp)
+		if !ok {
+			err = NewError(SymbDiffError, "%v does not support forward mode differentiation", node.op)
+			return
+		}
+
+		symdiffLogf("pushing tangents %v through %x (%v)", childTangents, node.ID(), node.op)
+		var t *Node
+		if t, err = fdo.FwdDiff(node.children, node, childTangents); err != nil {
+			err = errors.Wrapf(err, "FwdDiff for %v. OpType: %v. Node Type: %v. Children: %#v", node.op, node.op.Type(), node.t, node.children)
+			return
+		}
+		if t == nil {
+			continue
+		}
+
+		// scalar tangents of ops that broadcast their inputs are broadcast too
+		if t.IsScalar() && !node.IsScalar() {
+			if t, err = applyOp(newBroadcastOp(scalarShape, node.shape), t); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+		if !t.shape.Eq(node.shape) {
+			err = NewError(SymbDiffError, "The tangent of %v has shape %v. Expected %v", node, t.shape, node.shape)
+			return
+		}
+		nodeTangentMap[node] = t
+	}
+
+	// outputs that aren't moved by the inputs have tangents of zeroes
+	for _, n := range outputs {
+		t, ok := nodeTangentMap[n]
+		if !ok {
+			if t, err = zeroesLike(n); err != nil {
+				return
+			}
+			t = g.AddNode(t)
+		}
+		retVal = append(retVal, t)
+	}
+	return
+}
+
+// HVP computes the Hessian-vector products of the cost with v. v[i] is the direction in which wrt[i] is moved, and must
+// have the same type and shape as it. A node is returned for each node in wrt. It holds Σ Hᵢⱼvⱼ, where Hᵢⱼ is the block of
+// the Hessian of the cost wrt wrt[i] and wrt[j].
+//
+// The gradients of the cost are differentiated again: the products are the gradients of Σ ∇ᵢcost·vᵢ, so the Hessian
+// is never built.
+func HVP(cost *Node, wrt, v Nodes) (retVal Nodes, err error) {
+	if len(wrt) != len(v) {
+		err = NewError(SymbDiffError, "HVP expects a vector for each node it differentiates with regards to. Got %d nodes and %d vectors", len(wrt), len(v))
+		return
+	}
+	for i, n := range wrt {
+		if !typeEq(n.t, v[i].t) || !n.shape.Eq(v[i].shape) {
+			err = NewError(SymbDiffError, "Expected the vector of %v to be a %v of %v. Got a %v of %v instead", n, n.t, n.shape, v[i].t, v[i].shape)
+			return
+		}
+	}
+
+	var grads Nodes
+	if grads, err = Grad(cost, wrt...); err != nil {
+		return
+	}
+
+	g := cost.g
+	dots := make(Nodes, len(grads))
+	for i, grad := range grads {
+		if dots[i], err = HadamardProd(grad, g.AddNode(v[i])); err == nil {
+			dots[i], err = Sum(dots[i])
+		}
+		if err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+
+	var dot *Node
+	if dot, err = ReduceAdd(dots); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return gradOrZeroes(dot, wrt)
+}
+
+// Hessian returns the Hessian of the cost wrt the input node wrt. If wrt is a scalar, the second derivative is returned.
+// Otherwise the Hessian is a n×n matrix, where n is the number of elements of wrt, in row major order.
+//
+// Each row is the gradient of an element of the gradient of the cost, so the graph grows with n.
+// For large inputs, the Hessian-vector products of HVP are much cheaper.
+func Hessian(cost *Node, wrt *Node) (retVal *Node, err error) {
+	var grads, rows Nodes
+	if grads, err = Grad(cost, wrt); err != nil {
+		return
+	}
+
+	if wrt.IsScalar() {
+		if rows, err = gradOrZeroes(grads[0], Nodes{wrt}); err != nil {
+			return
+		}
+		return rows[0], nil
+	}
+
+	s := wrt.shape
+	n := s.TotalSize()
+	coords := make([]int, len(s))
+	for k := 0; k < n; k++ {
+		for d, r := len(s)-1, k; d >= 0; d-- {
+			coords[d] = r % s[d]
+			r /= s[d]
+		}
+
+		var elem *Node
+		var row Nodes
+		if elem, err = At(grads[0], coords...); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		if row, err = gradOrZeroes(elem, Nodes{wrt}); err != nil {
+			return
+		}
+		if len(row[0].shape) != 1 {
+			if row[0], err = Reshape(row[0], n); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+		rows = append(rows, row[0])
+	}
+
+	if retVal, err = Stack(0, rows...); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+// Jacobian returns the Jacobians of the outputs wrt the input nodes in wrt, as blocks in row major order:
+// retVal[i*len(wrt)+j] is the Jacobian of outputs[i] wrt wrt[j]. Each block is a m×n matrix, where m is the number of
+// elements of the output and n the number of elements of the input, both in row major order. If the output is a scalar,
+// the gradient (of n elements) is returned. If the input is a scalar, the derivative (of m elements) is returned.
+//
+// Each block is built a column at a time by forward mode differentiation (see JVP) when the input has fewer elements than
+// the output, and a row at a time by reverse mode differentiation otherwise. Either way the graph grows with min(m, n).
+func Jacobian(outputs, wrt Nodes) (retVal Nodes, err error) {
+	if len(outputs) == 0 || len(wrt) == 0 {
+		err = NewError(SymbDiffError, "Jacobian requires at least one output and one input. Got %d outputs and %d inputs", len(outputs), len(wrt))
+		return
+	}
+	for _, n := range wrt {
+		if !n.isInput() {
+			err = NewError(SymbDiffError, "Jacobian can only differentiate with regards to input nodes. %v isn't one", n)
+			return
+		}
+	}
+
+	for _, output := range outputs {
+		var sortedNodes Nodes
+		if sortedNodes, err = Sort(output.g); err != nil {
+			err = errors.Wrap(err, sortFail)
+			return
+		}
+
+		var affectsOutput NodeSet
+		if affectsOutput, err = forwardDiffAnalysis(Nodes{output}, sortedNodes); err != nil {
+			err = errors.Wrap(err, "Failed during forward differentiation analysis")
+			return
+		}
+
+		for _, n := range wrt {
+			var block *Node
+			if block, err = jacobian(output, n, affectsOutput); err != nil {
+				return
+			}
+			retVal = append(retVal, block)
+		}
+	}
+	return
+}
+
+// jacobian returns the Jacobian of output wrt the input node wrt. affectsOutput is the set of nodes that affect output.
+func jacobian(output, wrt *Node, affectsOutput NodeSet) (retVal *Node, err error) {
+	m, n := 1, 1
+	if !output.IsScalar() {
+		m = output.shape.TotalSize()
+	}
+	if !wrt.IsScalar() {
+		n = wrt.shape.TotalSize()
+	}
+
+	// an output that doesn't depend on wrt has a Jacobian of zeroes
+	if !affectsOutput.Contains(wrt) {
+		var dt Dtype
+		if dt, err = dtypeOf(wrt.t); err != nil {
+			err = errors.Wrapf(err, dtypeExtractionFail, wrt.t)
+			return
+		}
+
+		var shape []int
+		for _, d := range []int{m, n} {
+			if d > 1 {
+				shape = append(shape, d)
+			}
+		}
+		return output.g.AddNode(NewConstant(ones(dt, shape...).zero())), nil
+	}
+
+	var parts Nodes
+	axis := 0
+	if n < m {
+		axis = 1
+		for j := 0; j < n; j++ {
+			var tangent *Node
+			var jvps Nodes
+			if tangent, err = oneHot(wrt, j); err != nil {
+				return
+			}
+			if jvps, err = JVP(Nodes{output}, Nodes{wrt}, Nodes{tangent}); err != nil {
+				return
+			}
+			parts = append(parts, jvps[0])
+		}
+	} else {
+		for i := 0; i < m; i++ {
+			var gradOut *Node
+			var vjps Nodes
+			if gradOut, err = oneHot(output, i); err != nil {
+				return
+			}
+			if vjps, err = Backpropagate(Nodes{output}, Nodes{output.g.AddNode(gradOut)}, Nodes{wrt}); err != nil {
+				return
+			}
+			parts = append(parts, vjps[0])
+		}
+	}
+
+	// the columns (or rows) are flattened. Matrix-vector products are column vectors, for instance
+	for i, p := range parts {
+		if !p.IsScalar() && len(p.shape) != 1 {
+			if parts[i], err = Reshape(p, p.shape.TotalSize()); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+	}
+
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	if retVal, err = Stack(axis, parts...); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+// gradOrZeroes is Grad, except that the nodes in wrt that the cost does not depend on get gradients of zeroes instead of an error.
+// The gradient of a cost that is linear in a node does not depend on it, so its second derivatives are 0.
+func gradOrZeroes(cost *Node, wrt Nodes) (retVal Nodes, err error) {
+	g := cost.g
+	var sortedNodes Nodes
+	if sortedNodes, err = Sort(g); err != nil {
+		err = errors.Wrap(err, sortFail)
+		return
+	}
+
+	var affectsCost NodeSet
+	if affectsCost, err = forwardDiffAnalysis(Nodes{cost}, sortedNodes); err != nil {
+		err = errors.Wrap(err, "Failed during forward differentiation analysis")
+		return
+	}
+
+	var live Nodes
+	for _, n := range wrt {
+		if affectsCost.Contains(n) {
+			live = append(live, n)
+		}
+	}
+
+	var grads Nodes
+	if len(live) > 0 {
+		if grads, err = Grad(cost, live...); err != nil {
+			return
+		}
+	}
+
+	retVal = make(Nodes, len(wrt))
+	for i, n := range wrt {
+		if len(live) > 0 && live[0] == n {
+			retVal[i], grads, live = grads[0], grads[1:], live[1:]
+			continue
+		}
+		if retVal[i], err = zeroGrad(n); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// linearFwdDiff is the forward derivative of an op that is linear in its differentiable inputs: the op is applied to their tangents instead.
+func linearFwdDiff(op Op, inputs, tangents Nodes) (retVal *Node, err error) {
+	diffs := op.DiffWRT(len(inputs))
+	children := make(Nodes, len(inputs))
+	for i, in := range inputs {
+		switch {
+		case !diffs[i]:
+			children[i] = in
+		case tangents[i] != nil:
+			children[i] = tangents[i]
+		default:
+			if children[i], err = zeroesLike(in); err != nil {
+				return
+			}
+		}
+	}
+
+	if retVal, err = applyOp(op, children...); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+// reductionFwdDiff is the forward derivative of a reduction where every element of the input is reduced into exactly one element of the output.
+// partials holds the derivative of the output element that each element of the input is reduced into, wrt that input element.
+func reductionFwdDiff(partials, tangent *Node, along axes) (retVal *Node, err error) {
+	if retVal, err = HadamardProd(partials, tangent); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if retVal, err = Sum(retVal, along...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	// a summed matrix keeps its reduced axis (a (2, 3) matrix summed along 0 is (1, 3)), but the reduction it is the tangent of does not
+	if s := reduceShape(partials.shape, along); !s.IsScalar() && len(s) != len(retVal.shape) {
+		if retVal, err = Reshape(retVal, s...); err != nil {
+			err = errors.Wrap(err, operationError)
+		}
+	}
+	return
+}
+
+// addTangents sums the tangents, skipping the nil ones. If all of them are nil, nil is returned.
+func addTangents(tangents ...*Node) (retVal *Node, err error) {
+	for _, t := range tangents {
+		switch {
+		case t == nil:
+		case retVal == nil:
+			retVal = t
+		default:
+			if retVal, err = Add(retVal, t); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+	}
+	return
+}
+
+// onesLike returns a constant of ones, with the same type and shape as n
+func onesLike(n *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(n.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, n.t)
+		return
+	}
+	if n.IsScalar() {
+		return NewConstant(ones(dt)), nil
+	}
+	return NewConstant(ones(dt, n.shape...)), nil
+}
+
+// zeroesLike returns a constant of zeroes, with the same type and shape as n
+func zeroesLike(n *Node) (retVal *Node, err error) {
+	if retVal, err = onesLike(n); err != nil {
+		return
+	}
+	return NewConstant(retVal.Value().zero()), nil
+}
+
+// oneHot returns a constant with the same type and shape as n, that is one at the flat index i and zero everywhere else
+func oneHot(n *Node, i int) (retVal *Node, err error) {
+	if retVal, err = onesLike(n); err != nil || n.IsScalar() {
+		return
+	}
+
+	v := retVal.Value().zero()
+	var data interface{}
+	if data, err = valueData(v); err != nil {
+		return
+	}
+
+	switch d := data.(type) {
+	case []float64:
+		d[i] = 1
+	case []float32:
+		d[i] = 1
+	default:
+		err = nyi("oneHot", data)
+		return
+	}
+	return NewConstant(v), nil
+}
+
+// zeroGrad returns a gradient of zeroes for n. Unlike the constant of zeroesLike, it is in the graph of n,
+// so it can be summed with the other gradients of n.
+func zeroGrad(n *Node) (retVal *Node, err error) {
+	if retVal, err = zeroesLike(n); err != nil {
+		return
+	}
+	return n.g.AddNode(retVal), nil
+}
@@ -1,8 +1,13 @@
 package gorgonia
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	ti "github.com/chewxy/gorgonia/tensor/i"
+	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/gonum/graph/topo"
 	"github.com/stretchr/testify/assert"
 )
@@ -159,3 +164,626 @@ func TestCompoundOpDiff(t *testing.T) {
 	}
 
 }
+
+func TestStopGradient(t *testing.T) {
+	assert := assert.New(t)
+
+	// cost = Σ(x ⊙ stop(x) ⊙ stop(y²)) + Σy, so ∂cost/∂x = x ⊙ y² and ∂cost/∂y = 1
+	build := func() (g *ExprGraph, x, y, stopped, cost *Node) {
+		g = NewGraph()
+		x = NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3}))))
+		y = NewVector(g, Float64, WithName("y"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{4, 5, 6}))))
+		stopped = Must(StopGradient(Must(Square(y))))
+		prod := Must(HadamardProd(Must(HadamardProd(Must(Identity(x)), Must(StopGradient(x)))), stopped))
+		cost = Must(Add(Must(Sum(prod)), Must(Sum(y))))
+		return
+	}
+
+	// the analysis stops at StopGradient
+	g, x, y, stopped, _ := build()
+	sorted, err := Sort(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	affectedByOutput, err := backwardDiffAnalysis(Nodes{x, y}, sorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(affectedByOutput.Contains(stopped.children[0]))
+	assert.False(affectedByOutput.Contains(stopped))
+
+	correctX := []float64{16, 50, 108}
+	correctY := []float64{1, 1, 1}
+
+	// symbolic differentiation
+	g, x, y, _, cost := build()
+	if _, err = Grad(cost, x, y); err != nil {
+		t.Fatal(err)
+	}
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	xG, _ := x.Grad()
+	yG, _ := y.Grad()
+	assert.Equal(correctX, extractF64s(xG))
+	assert.Equal(correctY, extractF64s(yG))
+
+	// automatic differentiation
+	g, x, y, _, cost = build()
+	if err = NewLispMachine(g).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(455.0, extractF64(cost.Value()))
+	xG, _ = x.Grad()
+	yG, _ = y.Grad()
+	assert.Equal(correctX, extractF64s(xG))
+	assert.Equal(correctY, extractF64s(yG))
+
+	// a wrt that can only be reached through a StopGradient is not differentiable
+	g = NewGraph()
+	x = NewVector(g, Float64, WithName("x"), WithShape(3))
+	if _, err = Grad(Must(Sum(Must(StopGradient(x)))), x); err == nil {
+		t.Error("Expected an error differentiating through a StopGradient")
+	}
+}
+
+var jvpTests = []struct {
+	name   string
+	shapes []types.Shape
+	fn     func(g *ExprGraph, ns Nodes) (*Node, error)
+}{
+	{"Arithmetic", []types.Shape{{3}, {3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		a, b := ns[0], ns[1]
+		pow := Must(binOpNode(newElemBinOp(powOpType, a, b), Must(Exp(a)), b))
+		quo := Must(HadamardDiv(Must(HadamardProd(a, b)), Must(Add(Must(Square(b)), onef64))))
+		return Sub(Must(Add(pow, Must(Atan2(a, b)))), Must(Sub(quo, Must(Tanh(a)))))
+	}},
+	{"Scalar broadcast", []types.Shape{scalarShape, {3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		c := NewConstant(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3})))
+		return Add(Must(Add(Must(HadamardProd(ns[0], ns[1])), ns[0])), Must(Sub(ns[0], c)))
+	}},
+	{"MatMul", []types.Shape{{2, 3}, {3, 2}, {3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return Mul(Must(Mul(Must(Mul(ns[0], ns[1])), ns[0])), ns[2])
+	}},
+	{"Reductions", []types.Shape{{2, 3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		x := ns[0]
+		extrema := Must(Add(Must(Max(x, 1)), Must(Min(x, 1))))
+		moments := Must(Add(Must(Norm(x, 2, 1)), Must(Var(x, 1, 1))))
+		lse := Must(Add(Must(LogSumExp(x, 1)), Must(Sum(Must(HadamardProd(x, Must(LogSoftMax(x, 1)))), 1))))
+		return Add(Must(Add(extrema, moments)), lse)
+	}},
+	{"Tensor ops", []types.Shape{{2, 3}, {2, 3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		idx := NewVector(g, Int, WithName("idx"), WithShape(3), WithValue(ti.NewTensor(ti.WithShape(3), ti.WithBacking([]int{2, 0, 2}))))
+		shuffled := Must(Reshape(Must(Transpose(Must(Concat(0, ns[0], ns[1])))), 2, 6))
+		gathered := Must(Reshape(Must(Gather(ns[0], idx, 1)), 6))
+		return Add(Must(Slice(shuffled, S(1))), Must(HadamardProd(gathered, Must(Identity(gathered)))))
+	}},
+	{"Clamp and Where", []types.Shape{{4}, scalarShape, scalarShape}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		// the bounds are 0.45 and 0.64, and x² is 0.71, 0.57, 0.43 and 0.3
+		x := ns[0]
+		lo := Must(HadamardProd(ns[1], NewConstant(0.5)))
+		hi := Must(Add(ns[2], NewConstant(0.5)))
+		clamped := Must(Clamp(Must(Square(x)), lo, hi))
+		return Add(clamped, Must(Where(Must(Gt(x, lo, false)), Must(Square(x)), hi)))
+	}},
+	{"Dense linear algebra", []types.Shape{{2, 2}, {2}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		a, b := ns[0], ns[1]
+		solved := Must(Add(Must(Solve(a, b)), Must(TriangularSolve(a, b, true))))
+		return Add(Must(Add(solved, Must(Mul(Must(MatInverse(a)), b)))), Must(LogDet(a)))
+	}},
+	{"Cholesky", []types.Shape{{2, 2}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		id := NewConstant(tf64.NewTensor(tf64.WithShape(2, 2), tf64.WithBacking([]float64{1, 0, 0, 1})))
+		a := Must(Add(Must(Mul(ns[0], Must(Transpose(ns[0])))), id))
+		return Cholesky(a)
+	}},
+	{"Einsum", []types.Shape{{2, 3}, {3, 4}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return Einsum("ij,jk->ki", ns[0], ns[1])
+	}},
+	{"Pooling", []types.Shape{{1, 2, 4, 4}, {2, 2, 2, 2}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		pooled := Must(Add(Must(MaxPool2D(ns[0], []int{2, 2}, []int{2, 2}, []int{1, 1})), Must(AvgPool2D(ns[0], []int{2, 2}, []int{2, 2}, []int{1, 1}))))
+		return Conv2d(pooled, ns[1], []int{1, 1}, []int{0, 0}, []int{1, 1})
+	}},
+	{"SoftmaxCrossEntropy", []types.Shape{{2, 3}, {2, 3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return SoftmaxCrossEntropy(ns[0], Must(SoftMax(ns[1])))
+	}},
+	{"LayerNorm", []types.Shape{{2, 3}, {3}, {3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return LayerNorm(ns[0], ns[1], ns[2], []int{1}, 1e-5)
+	}},
+	{"BatchNorm", []types.Shape{{4, 2}, {2}, {2}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		y, err := BatchNorm(ns[0], ns[1], ns[2], 0.9, 1e-5)
+		return y, err
+	}},
+}
+
+// jvpBacking fills a tensor of shape s with values that differ for each input i, offset by off
+func jvpBacking(i, off int, s types.Shape) []float64 {
+	retVal := make([]float64, s.TotalSize())
+	if s.IsScalar() {
+		retVal = make([]float64, 1)
+	}
+	for j := range retVal {
+		retVal[j] = math.Sin(float64(3*j+i+1) + float64(off))
+	}
+	return retVal
+}
+
+func jvpNode(g *ExprGraph, name string, s types.Shape, data []float64) *Node {
+	if s.IsScalar() {
+		return NewScalar(g, Float64, WithName(name), WithValue(data[0]))
+	}
+	T := tf64.NewTensor(tf64.WithShape(s...), tf64.WithBacking(append([]float64{}, data...)))
+	return NewTensor(g, Float64, len(s), WithName(name), WithShape(s...), WithValue(T))
+}
+
+func jvpFloats(v Value) []float64 {
+	if s, ok := v.(Scalar); ok {
+		return []float64{s.v.(float64)}
+	}
+	return extractF64s(v)
+}
+
+func TestJVP(t *testing.T) {
+	assert := assert.New(t)
+	for _, jt := range jvpTests {
+		data := make([][]float64, len(jt.shapes))
+		tangents := make([][]float64, len(jt.shapes))
+		for i, s := range jt.shapes {
+			data[i] = jvpBacking(i, 0, s)
+			tangents[i] = jvpBacking(i, 5, s)
+		}
+
+		build := func(inputs [][]float64) (g *ExprGraph, y *Node, xs Nodes) {
+			g = NewGraph()
+			for i, s := range jt.shapes {
+				xs = append(xs, jvpNode(g, fmt.Sprintf("x%d", i), s, inputs[i]))
+			}
+			y = Must(jt.fn(g, xs))
+			return
+		}
+
+		forward := func(inputs [][]float64) []float64 {
+			g, y, _ := build(inputs)
+			if err := NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+				t.Fatalf("%s: %v", jt.name, err)
+			}
+			return jvpFloats(y.Value())
+		}
+
+		// the central finite difference in the direction of the tangents
+		moved := func(eps float64) []float64 {
+			inputs := make([][]float64, len(data))
+			for i := range data {
+				inputs[i] = make([]float64, len(data[i]))
+				for j, v := range data[i] {
+					inputs[i][j] = v + eps*tangents[i][j]
+				}
+			}
+			return forward(inputs)
+		}
+		plus, minus := moved(1e-6), moved(-1e-6)
+		correct := make([]float64, len(plus))
+		for i := range plus {
+			correct[i] = (plus[i] - minus[i]) / 2e-6
+		}
+
+		buildJVP := func() (g *ExprGraph, y, jvp *Node) {
+			var xs Nodes
+			g, y, xs = build(data)
+			ts := make(Nodes, len(xs))
+			for i, s := range jt.shapes {
+				ts[i] = jvpNode(g, fmt.Sprintf("t%d", i), s, tangents[i])
+			}
+			jvps, err := JVP(Nodes{y}, xs, ts)
+			if err != nil {
+				t.Fatalf("%s: %v", jt.name, err)
+			}
+			return g, y, jvps[0]
+		}
+
+		g, y, jvp := buildJVP()
+		assert.True(y.Shape().Eq(jvp.Shape()), jt.name)
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Errorf("%s: %v", jt.name, err)
+			continue
+		}
+		if err = NewTapeMachine(prog, locMap, TraceExec()).RunAll(); err != nil {
+			t.Errorf("%s: %v", jt.name, err)
+			continue
+		}
+		for i, v := range jvpFloats(jvp.Value()) {
+			assert.InDelta(correct[i], v, 1e-5, jt.name+": TapeMachine")
+		}
+
+		g, _, jvp = buildJVP()
+		if err = NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+			t.Errorf("%s: %v", jt.name, err)
+			continue
+		}
+		for i, v := range jvpFloats(jvp.Value()) {
+			assert.InDelta(correct[i], v, 1e-5, jt.name+": LispMachine")
+		}
+	}
+
+	// outputs that the inputs don't move have tangents of zeroes
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3}))))
+	tx := NewVector(g, Float64, WithName("tx"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 1, 1}))))
+	y := NewVector(g, Float64, WithName("y"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking([]float64{4, 5}))))
+	stopped := Must(Add(Must(Square(y)), Must(Sum(Must(StopGradient(x))))))
+	jvps, err := JVP(Nodes{Must(Square(x)), stopped}, Nodes{x}, Nodes{tx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{2, 4, 6}, extractF64s(jvps[0].Value()))
+	assert.Equal([]float64{0, 0}, extractF64s(jvps[1].Value()))
+
+	/* Idiots */
+
+	if _, err = JVP(Nodes{stopped}, Nodes{x}, Nodes{y}); err == nil {
+		t.Error("Expected an error with a tangent of the wrong shape")
+	}
+	if _, err = JVP(Nodes{stopped}, Nodes{stopped}, Nodes{y}); err == nil {
+		t.Error("Expected an error with a tangent of a node that isn't an input")
+	}
+	if _, err = JVP(Nodes{stopped}, Nodes{x}, nil); err == nil {
+		t.Error("Expected an error with a missing tangent")
+	}
+}
+
+var hvpTests = []struct {
+	name   string
+	shapes []types.Shape
+	fn     func(g *ExprGraph, ns Nodes) (*Node, error)
+}{
+	{"Arithmetic", []types.Shape{{3}, {3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		a, b := ns[0], ns[1]
+		pow := Must(binOpNode(newElemBinOp(powOpType, a, b), Must(Exp(a)), b))
+		quo := Must(HadamardDiv(Must(HadamardProd(a, b)), Must(Add(Must(Square(b)), onef64))))
+		return Sum(Must(Sub(Must(Add(pow, Must(Atan2(a, b)))), Must(Sub(quo, Must(Tanh(a)))))))
+	}},
+	{"Tensor ops", []types.Shape{{2, 3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		x := ns[0]
+		sliced := Must(Sum(Must(Cube(Must(Slice(x, S(1)))))))
+		at := Must(HadamardProd(Must(At(x, 0, 2)), Must(Sum(Must(Square(x))))))
+		return Add(Must(Add(sliced, at)), Must(Sum(Must(Square(Must(Mean(x, 0)))))))
+	}},
+	{"Reductions", []types.Shape{{2, 3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		x := ns[0]
+		extrema := Must(Add(Must(Sum(Must(Square(Must(Max(x, 1)))))), Must(Sum(Must(Cube(Must(Min(x, 0))))))))
+		norms := Must(Add(Must(Sum(Must(Norm(x, 2, 1)))), Must(Sum(Must(Norm(x, 3, 0))))))
+		moments := Must(Add(Must(Sum(Must(Square(Must(Std(x, 1, 1)))))), Must(Sum(Must(Var(x, 0, 0))))))
+		return Add(Must(Add(extrema, norms)), moments)
+	}},
+	{"Linear algebra", []types.Shape{{2, 2}, {2}, {3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		a, b, u := ns[0], ns[1], ns[2]
+		id := NewConstant(tf64.NewTensor(tf64.WithShape(2, 2), tf64.WithBacking([]float64{3, 0, 0, 3})))
+		solved := Must(Sum(Must(Square(Must(Solve(Must(Add(a, id)), b))))))
+		outer := Must(Sum(Must(Square(Must(OuterProd(u, b))))))
+		return Add(Must(Add(solved, outer)), Must(Sum(Must(Cube(Must(Mul(a, b)))))))
+	}},
+	{"SoftmaxCrossEntropy", []types.Shape{{2, 3}, {2, 3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return Sum(Must(SoftmaxCrossEntropy(ns[0], Must(SoftMax(ns[1])))))
+	}},
+	{"MaxPool", []types.Shape{{1, 1, 4, 4}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return Sum(Must(Cube(Must(MaxPool2D(ns[0], []int{2, 2}, []int{2, 2}, []int{0, 0})))))
+	}},
+	{"LayerNorm", []types.Shape{{2, 3}, {3}, {3}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return Sum(Must(Cube(Must(LayerNorm(ns[0], ns[1], ns[2], []int{1}, 1e-5)))))
+	}},
+	{"BatchNorm", []types.Shape{{4, 2}, {2}, {2}}, func(g *ExprGraph, ns Nodes) (*Node, error) {
+		return Sum(Must(Cube(Must(BatchNorm(ns[0], ns[1], ns[2], 0.9, 1e-5)))))
+	}},
+}
+
+// hvpGrads computes the gradients of the cost built by fn wrt its inputs
+func hvpGrads(t *testing.T, shapes []types.Shape, fn func(*ExprGraph, Nodes) (*Node, error), inputs [][]float64) (retVal [][]float64) {
+	g := NewGraph()
+	var xs Nodes
+	for i, s := range shapes {
+		xs = append(xs, jvpNode(g, fmt.Sprintf("x%d", i), s, inputs[i]))
+	}
+	grads, err := Grad(Must(fn(g, xs)), xs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	for _, grad := range grads {
+		retVal = append(retVal, jvpFloats(grad.Value()))
+	}
+	return
+}
+
+func TestHVP(t *testing.T) {
+	assert := assert.New(t)
+	for _, ht := range hvpTests {
+		data := make([][]float64, len(ht.shapes))
+		vs := make([][]float64, len(ht.shapes))
+		for i, s := range ht.shapes {
+			data[i] = jvpBacking(i, 0, s)
+			vs[i] = jvpBacking(i, 5, s)
+		}
+
+		// the central finite difference of the gradients in the direction of the vectors
+		moved := func(eps float64) [][]float64 {
+			inputs := make([][]float64, len(data))
+			for i := range data {
+				inputs[i] = make([]float64, len(data[i]))
+				for j, v := range data[i] {
+					inputs[i][j] = v + eps*vs[i][j]
+				}
+			}
+			return hvpGrads(t, ht.shapes, ht.fn, inputs)
+		}
+		plus, minus := moved(1e-6), moved(-1e-6)
+
+		buildHVP := func() (g *ExprGraph, hvps Nodes) {
+			g = NewGraph()
+			var xs, ns Nodes
+			for i, s := range ht.shapes {
+				xs = append(xs, jvpNode(g, fmt.Sprintf("x%d", i), s, data[i]))
+				ns = append(ns, jvpNode(g, fmt.Sprintf("v%d", i), s, vs[i]))
+			}
+			var err error
+			if hvps, err = HVP(Must(ht.fn(g, xs)), xs, ns); err != nil {
+				t.Fatalf("%s: %v", ht.name, err)
+			}
+			for i, hvp := range hvps {
+				assert.True(xs[i].Shape().Eq(hvp.Shape()), ht.name)
+			}
+			return
+		}
+
+		check := func(hvps Nodes, machine string) {
+			for i, hvp := range hvps {
+				for j, v := range jvpFloats(hvp.Value()) {
+					assert.InDelta((plus[i][j]-minus[i][j])/2e-6, v, 1e-4, fmt.Sprintf("%s: %s: x%d[%d]", ht.name, machine, i, j))
+				}
+			}
+		}
+
+		g, hvps := buildHVP()
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Errorf("%s: %v", ht.name, err)
+			continue
+		}
+		if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+			t.Errorf("%s: %v", ht.name, err)
+			continue
+		}
+		check(hvps, "TapeMachine")
+
+		g, hvps = buildHVP()
+		if err = NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+			t.Errorf("%s: %v", ht.name, err)
+			continue
+		}
+		check(hvps, "LispMachine")
+	}
+
+	/* Idiots */
+
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3}))))
+	y := NewVector(g, Float64, WithName("y"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking([]float64{4, 5}))))
+	cost := Must(Sum(Must(Cube(x))))
+	if _, err := HVP(cost, Nodes{x}, Nodes{y}); err == nil {
+		t.Error("Expected an error with a vector of the wrong shape")
+	}
+	if _, err := HVP(cost, Nodes{x}, nil); err == nil {
+		t.Error("Expected an error with a missing vector")
+	}
+}
+
+func TestHessian(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3}))))
+	w := NewMatrix(g, Float64, WithName("w"), WithShape(2, 2), WithValue(tf64.NewTensor(tf64.WithShape(2, 2), tf64.WithBacking([]float64{1, 2, 3, 4}))))
+	b := NewScalar(g, Float64, WithName("b"), WithValue(2.0))
+
+	// Σx³ + x₀x₁b² + Σx: the last term is linear, and so does not contribute
+	cubes := Must(Sum(Must(Cube(x))))
+	cross := Must(HadamardProd(Must(HadamardProd(Must(At(x, 0)), Must(At(x, 1)))), Must(Square(b))))
+	cost := Must(Add(Must(Add(cubes, cross)), Must(Sum(x))))
+	hx, err := Hessian(cost, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hb, err := Hessian(cost, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Σ(ww)²: the Hessian is 4×4
+	hw, err := Hessian(Must(Sum(Must(Square(Must(Mul(w, w)))))), w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a cost that is linear in the node has a Hessian of zeroes
+	hl, err := Hessian(Must(Add(Must(Sum(Must(Square(x)))), b)), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(types.Shape{3, 3}, hx.Shape())
+	assert.Equal([]float64{6, 4, 0, 4, 12, 0, 0, 0, 18}, extractF64s(hx.Value()))
+	assert.Equal(4.0, hb.Value().(Scalar).v)
+	assert.Equal(0.0, hl.Value().(Scalar).v)
+
+	// each row of the Hessian of w is the finite difference of the gradient when an element of w is moved
+	f := func(ns Nodes) (*Node, error) { return Sum(Must(Square(Must(Mul(ns[0], ns[0]))))) }
+	wData := []float64{1, 2, 3, 4}
+	assert.Equal(types.Shape{4, 4}, hw.Shape())
+	h := extractF64s(hw.Value())
+	for k := range wData {
+		plus := append([]float64{}, wData...)
+		minus := append([]float64{}, wData...)
+		plus[k] += 1e-6
+		minus[k] -= 1e-6
+		gp := hvpGrads(t, []types.Shape{{2, 2}}, func(g *ExprGraph, ns Nodes) (*Node, error) { return f(ns) }, [][]float64{plus})
+		gm := hvpGrads(t, []types.Shape{{2, 2}}, func(g *ExprGraph, ns Nodes) (*Node, error) { return f(ns) }, [][]float64{minus})
+		for j := range wData {
+			assert.InDelta((gp[0][j]-gm[0][j])/2e-6, h[4*k+j], 1e-4)
+		}
+	}
+
+	/* Idiots */
+
+	if _, err = Hessian(Must(Cube(x)), x); err == nil {
+		t.Error("Expected an error with a cost that isn't a scalar")
+	}
+}
+
+var jacobianTests = []struct {
+	name    string
+	shape   types.Shape
+	fn      func(x *Node) (*Node, error)
+	correct types.Shape // the shape of the Jacobian
+}{
+	// more outputs than inputs, so the Jacobian is built by forward mode differentiation
+	{"Forward mode", types.Shape{2}, func(x *Node) (*Node, error) {
+		return Concat(0, Must(Square(x)), Must(HadamardProd(x, Must(Sin(x)))))
+	}, types.Shape{4, 2}},
+	{"Scalar input", scalarShape, func(x *Node) (*Node, error) {
+		c := NewConstant(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3})))
+		return Square(Must(HadamardProd(x, c)))
+	}, types.Shape{3}},
+
+	// as many outputs as inputs, or fewer, so the Jacobian is built by reverse mode differentiation
+	{"Reverse mode", types.Shape{2, 3}, func(x *Node) (*Node, error) {
+		c := NewConstant(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, -2, 0.5})))
+		return Tanh(Must(Mul(x, c)))
+	}, types.Shape{2, 6}},
+	{"Square", types.Shape{2, 2}, func(x *Node) (*Node, error) { return Mul(x, Must(Exp(x))) }, types.Shape{4, 4}},
+	{"Scalar output", types.Shape{3}, func(x *Node) (*Node, error) { return Sum(Must(Cube(x))) }, types.Shape{3}},
+}
+
+func TestJacobian(t *testing.T) {
+	assert := assert.New(t)
+	for _, jt := range jacobianTests {
+		data := jvpBacking(0, 0, jt.shape)
+		forward := func(d []float64) []float64 {
+			g := NewGraph()
+			y := Must(jt.fn(jvpNode(g, "x", jt.shape, d)))
+			if err := NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+				t.Fatalf("%s: %v", jt.name, err)
+			}
+			return jvpFloats(y.Value())
+		}
+
+		// the central finite differences, a column at a time
+		cols := make([][]float64, len(data))
+		for j := range data {
+			d := append([]float64{}, data...)
+			d[j] += 1e-6
+			plus := forward(d)
+			d[j] -= 2e-6
+			minus := forward(d)
+			for i := range plus {
+				cols[j] = append(cols[j], (plus[i]-minus[i])/2e-6)
+			}
+		}
+
+		build := func() (g *ExprGraph, jac *Node) {
+			g = NewGraph()
+			x := jvpNode(g, "x", jt.shape, data)
+			jacs, err := Jacobian(Nodes{Must(jt.fn(x))}, Nodes{x})
+			if err != nil {
+				t.Fatalf("%s: %v", jt.name, err)
+			}
+			return g, jacs[0]
+		}
+
+		check := func(jac *Node, machine string) {
+			assert.Equal(jt.correct, jac.Shape(), jt.name)
+			vals := jvpFloats(jac.Value())
+			for j, col := range cols {
+				for i, v := range col {
+					assert.InDelta(v, vals[i*len(cols)+j], 1e-5, fmt.Sprintf("%s: %s: J[%d][%d]", jt.name, machine, i, j))
+				}
+			}
+		}
+
+		g, jac := build()
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Errorf("%s: %v", jt.name, err)
+			continue
+		}
+		if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+			t.Errorf("%s: %v", jt.name, err)
+			continue
+		}
+		check(jac, "TapeMachine")
+
+		g, jac = build()
+		if err = NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+			t.Errorf("%s: %v", jt.name, err)
+			continue
+		}
+		check(jac, "LispMachine")
+	}
+
+	// many outputs and inputs. An output that doesn't depend on an input has a block of zeroes
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3}))))
+	y := NewVector(g, Float64, WithName("y"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking([]float64{4, 5}))))
+	stopped := Must(Add(Must(Square(y)), Must(Sum(Must(StopGradient(x))))))
+	jacs, err := Jacobian(Nodes{stopped, Must(Square(x))}, Nodes{x, y})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := []struct {
+		shape   types.Shape
+		correct []float64
+	}{
+		{types.Shape{2, 3}, []float64{0, 0, 0, 0, 0, 0}},
+		{types.Shape{2, 2}, []float64{8, 0, 0, 10}},
+		{types.Shape{3, 3}, []float64{2, 0, 0, 0, 4, 0, 0, 0, 6}},
+		{types.Shape{3, 2}, []float64{0, 0, 0, 0, 0, 0}},
+	}
+	if assert.Equal(len(blocks), len(jacs)) {
+		for i, b := range blocks {
+			name := fmt.Sprintf("block %d", i)
+			assert.Equal(b.shape, jacs[i].Shape(), name)
+			assert.Equal(b.correct, extractF64s(jacs[i].Value()), name)
+		}
+	}
+
+	/* Idiots */
+
+	if _, err = Jacobian(Nodes{stopped}, Nodes{stopped}); err == nil {
+		t.Error("Expected an error differentiating wrt a node that isn't an input")
+	}
+	if _, err = Jacobian(Nodes{stopped}, nil); err == nil {
+		t.Error("Expected an error without any input")
+	}
+}
@@ -0,0 +1,260 @@
+package gorgonia
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GradCheckResult holds the gradients of the cost wrt one element of a node, as computed by GradCheck
+type GradCheckResult struct {
+	Node  string // the name of the node
+	Index int    // the index of the element in the flattened node
+
+	Numerical float64 // the central finite difference
+	Symbolic  float64 // computed by a *tapeMachine, from the gradient nodes that Grad() created
+	Automatic float64 // computed by a *lispMachine
+
+	SymbolicErr  float64 // the relative error of Symbolic
+	AutomaticErr float64 // the relative error of Automatic
+}
+
+func (r GradCheckResult) String() string {
+	return fmt.Sprintf("%s[%d]: numerical %v, symbolic %v (relative error %v), automatic %v (relative error %v)",
+		r.Node, r.Index, r.Numerical, r.Symbolic, r.SymbolicErr, r.Automatic, r.AutomaticErr)
+}
+
+// GradCheck checks the gradients of cost wrt the input nodes in wrt. The gradients are computed three ways:
+//		symbolically: with Grad(), and running the gradient nodes on a *tapeMachine
+//		automatically: by running the graph on a *lispMachine
+//		numerically: with the central finite difference (cost(x+eps) - cost(x-eps)) / 2eps of each element, by rerunning the graph
+// The relative error of a gradient g is |g - numerical| / max(|g|, |numerical|, 1), so gradients close to 0 are in effect compared absolutely.
+//
+// A result is returned for every element of every node in wrt. If the relative error of any of them is greater than tol,
+// an error listing the offending elements (and the names of their nodes) is returned as well. Use this to verify the
+// SymDiff and DoDiff implementations of new Ops and compositions.
+//
+// GradCheck adds the gradient nodes to the graph, and runs it, overwriting the values bound to the nodes.
+// The values of the nodes in wrt are restored before it returns.
+func GradCheck(cost *Node, wrt Nodes, eps, tol float64) (retVal []GradCheckResult, err error) {
+	if !cost.IsScalar() {
+		err = NewError(AutoDiffError, "GradCheck expects the cost to be a scalar. Got %v with shape %v instead", cost, cost.Shape())
+		return
+	}
+	if eps <= 0 || tol < 0 {
+		err = NewError(RuntimeError, "GradCheck expects a positive eps and a non negative tol. Got %v and %v instead", eps, tol)
+		return
+	}
+
+	originals := make([]Value, len(wrt))
+	for i, n := range wrt {
+		if !n.isInput() {
+			err = NewError(AutoDiffError, "GradCheck can only check the gradients wrt input nodes. %v isn't one", n)
+			return
+		}
+		if n.boundTo == nil {
+			err = NewError(RuntimeError, "No value bound to %v", n)
+			return
+		}
+		if originals[i], err = n.Value().clone(); err != nil {
+			return
+		}
+	}
+	defer func() {
+		for i, n := range wrt {
+			if lerr := Let(n, originals[i]); lerr != nil && err == nil {
+				err = lerr
+			}
+		}
+	}()
+
+	// the forward passes only need the part of the graph that computes the cost
+	costGraph := cost.g.SubgraphRoots(cost)
+
+	// numerical gradients
+	numerical := make([][]float64, len(wrt))
+	for i, n := range wrt {
+		var data []float64
+		if data, err = gradCheckFloats(originals[i]); err != nil {
+			return
+		}
+
+		numerical[i] = make([]float64, len(data))
+		for j := range data {
+			var plus, minus float64
+			if plus, err = gradCheckCost(costGraph, cost, n, originals[i], j, eps); err != nil {
+				return
+			}
+			if minus, err = gradCheckCost(costGraph, cost, n, originals[i], j, -eps); err != nil {
+				return
+			}
+			numerical[i][j] = (plus - minus) / (2 * eps)
+		}
+
+		if err = gradCheckLet(n, originals[i]); err != nil {
+			return
+		}
+	}
+
+	// automatic differentiation. The derivatives are zeroed first, as they are accumulated into.
+	for _, n := range wrt {
+		if dv, ok := n.boundTo.(*dualValue); ok && dv.d != nil {
+			if err = dv.SetDeriv(dv.d.zero()); err != nil {
+				return
+			}
+		}
+	}
+	if err = NewLispMachine(costGraph).RunAll(); err != nil {
+		err = errors.Wrap(err, "GradCheck failed to run the *lispMachine")
+		return
+	}
+	automatic := make([][]float64, len(wrt))
+	for i, n := range wrt {
+		var grad Value
+		if grad, err = n.Grad(); err != nil {
+			return
+		}
+		if automatic[i], err = gradCheckFloats(grad); err != nil {
+			return
+		}
+	}
+
+	// symbolic differentiation
+	var grads Nodes
+	if grads, err = Grad(cost, wrt...); err != nil {
+		err = errors.Wrap(err, "GradCheck failed to differentiate the cost symbolically")
+		return
+	}
+	for i, n := range wrt {
+		if err = gradCheckLet(n, originals[i]); err != nil {
+			return
+		}
+	}
+	prog, locMap, err := Compile(cost.g.SubgraphRoots(grads...))
+	if err != nil {
+		err = errors.Wrap(err, "GradCheck failed to compile the gradients")
+		return
+	}
+	if err = NewTapeMachine(prog, locMap, TraceExec()).RunAll(); err != nil {
+		err = errors.Wrap(err, "GradCheck failed to run the *tapeMachine")
+		return
+	}
+	symbolic := make([][]float64, len(wrt))
+	for i, grad := range grads {
+		if symbolic[i], err = gradCheckFloats(grad.Value()); err != nil {
+			return
+		}
+	}
+
+	var bad []string
+	for i, n := range wrt {
+		for j, num := range numerical[i] {
+			r := GradCheckResult{
+				Node:      n.Name(),
+				Index:     j,
+				Numerical: num,
+				Symbolic:  symbolic[i][j],
+				Automatic: automatic[i][j],
+			}
+			r.SymbolicErr = relativeError(r.Symbolic, num)
+			r.AutomaticErr = relativeError(r.Automatic, num)
+			retVal = append(retVal, r)
+
+			// NaNs fail the check too
+			if !(r.SymbolicErr <= tol && r.AutomaticErr <= tol) {
+				bad = append(bad, r.String())
+			}
+		}
+	}
+
+	if len(bad) > 0 {
+		err = NewError(AutoDiffError, "The gradients of %d elements differ from their finite differences by more than %v:\n\t%s", len(bad), tol, strings.Join(bad, "\n\t"))
+	}
+	return
+}
+
+// gradCheckCost runs the forward pass of the cost, with the jth element of v moved by delta bound to n
+func gradCheckCost(g *ExprGraph, cost, n *Node, v Value, j int, delta float64) (retVal float64, err error) {
+	var p Value
+	if p, err = v.clone(); err != nil {
+		return
+	}
+
+	switch pt := p.(type) {
+	case Tensor:
+		t := pt.Materialize()
+		switch data := t.Data().(type) {
+		case []float64:
+			data[j] += delta
+		case []float32:
+			data[j] += float32(delta)
+		default:
+			err = nyi("GradCheck", pt.Dtype())
+			return
+		}
+		p = FromTensor(t)
+	case Scalar:
+		switch s := pt.v.(type) {
+		case float64:
+			p, err = anyToValue(s + delta)
+		case float32:
+			p, err = anyToValue(s + float32(delta))
+		default:
+			err = nyi("GradCheck", pt.t)
+		}
+		if err != nil {
+			return
+		}
+	default:
+		err = nyi("GradCheck", v)
+		return
+	}
+
+	if err = Let(n, p); err != nil {
+		return
+	}
+	if err = NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+		err = errors.Wrap(err, "GradCheck failed to run the forward pass")
+		return
+	}
+
+	var c []float64
+	if c, err = gradCheckFloats(cost.Value()); err != nil {
+		return
+	}
+	return c[0], nil
+}
+
+// gradCheckLet binds a copy of v to n, so that v is never overwritten by the VMs
+func gradCheckLet(n *Node, v Value) (err error) {
+	var c Value
+	if c, err = v.clone(); err != nil {
+		return
+	}
+	return Let(n, c)
+}
+
+// gradCheckFloats returns a copy of the data of v as float64s
+func gradCheckFloats(v Value) (retVal []float64, err error) {
+	var data interface{}
+	if data, err = valueData(v); err != nil {
+		return
+	}
+
+	switch d := data.(type) {
+	case []float64:
+		retVal = append([]float64{}, d...)
+	case []float32:
+		retVal = f32sTof64s(d)
+	default:
+		err = nyi("GradCheck", v.Dtype())
+	}
+	return
+}
+
+// relativeError is |a - b| / max(|a|, |b|, 1)
+func relativeError(a, b float64) float64 {
+	return math.Abs(a-b) / math.Max(math.Max(math.Abs(a), math.Abs(b)), 1)
+}
@@ -0,0 +1,80 @@
+package gorgonia
+
+import (
+	"strings"
+	"testing"
+
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/stretchr/testify/assert"
+)
+
+// wrongSquareOp is a square with the gradient of the identity function
+type wrongSquareOp struct {
+	elemUnaryOp
+}
+
+func (op wrongSquareOp) SymDiff(inputs Nodes, output, gradNode *Node) (Nodes, error) {
+	return Nodes{gradNode}, nil
+}
+
+func (op wrongSquareOp) DoDiff(inputs Nodes, output *Node) error {
+	return addInto(inputs[0], output.boundTo.(*dualValue).d)
+}
+
+func TestGradCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	g := NewGraph()
+	wT := tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking([]float64{0.1, -0.2, 0.3, 0.4, 0.5, -0.6}))
+	xT := tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3}))
+	w := NewMatrix(g, Float64, WithName("w"), WithShape(2, 3), WithValue(wT))
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(xT))
+	b := NewScalar(g, Float64, WithName("b"), WithValue(0.5))
+
+	// cost = Σtanh(wx + b)²
+	cost := Must(Sum(Must(Square(Must(Tanh(Must(Add(Must(Mul(w, x)), b))))))))
+	results, err := GradCheck(cost, Nodes{w, x, b}, 1e-6, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(6+3+1, len(results))
+	for _, r := range results {
+		assert.True(r.SymbolicErr < 1e-6 && r.AutomaticErr < 1e-6, r.String())
+	}
+	assert.Equal("b", results[9].Node)
+	assert.Equal(0, results[9].Index)
+
+	// the values are restored
+	assert.Equal([]float64{0.1, -0.2, 0.3, 0.4, 0.5, -0.6}, extractF64s(w.Value()))
+	assert.Equal([]float64{1, 2, 3}, extractF64s(x.Value()))
+	assert.Equal(0.5, extractF64(b.Value()))
+
+	// a wrong gradient is reported, along with the node it is wrt
+	g = NewGraph()
+	x = NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{1, 2, 3}))))
+	y := NewVector(g, Float64, WithName("y"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{4, 5, 6}))))
+	sq := Must(applyOp(wrongSquareOp{newElemUnaryOp(squareOpType, x)}, x))
+	cost = Must(Sum(Must(HadamardProd(sq, y))))
+	if results, err = GradCheck(cost, Nodes{x, y}, 1e-6, 1e-6); err == nil {
+		t.Fatal("Expected the wrong gradients to be reported")
+	}
+	assert.True(strings.Contains(err.Error(), "x[0]"))
+	assert.False(strings.Contains(err.Error(), "y[0]"))
+	for i, r := range results[:3] {
+		assert.InDelta(2*float64(i+1)*float64(i+4), r.Numerical, 1e-6)
+		assert.Equal(float64(i+4), r.Symbolic)
+		assert.Equal(float64(i+4), r.Automatic)
+	}
+
+	/* Idiots */
+
+	if _, err = GradCheck(sq, Nodes{x}, 1e-6, 1e-6); err == nil {
+		t.Error("Expected an error checking a cost that isn't a scalar")
+	}
+	if _, err = GradCheck(cost, Nodes{sq}, 1e-6, 1e-6); err == nil {
+		t.Error("Expected an error checking the gradient wrt a node that isn't an input")
+	}
+	if _, err = GradCheck(cost, Nodes{x}, 0, 1e-6); err == nil {
+		t.Error("Expected an error with a zero eps")
+	}
+}
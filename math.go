@@ -87,3 +87,215 @@ func _softplusf32(x float32) float32 {
 	}
 	return float32(math.Log1p(math.Exp(float64(x))))
 }
+
+/* ACTIVATION FUNCTIONS */
+
+const (
+	defaultLeakyReluAlpha = 0.01
+	defaultEluAlpha       = 1.0
+
+	// constants from Klambauer et al. (2017) - Self-Normalizing Neural Networks
+	seluAlpha = 1.6732632423543772848170429916717
+	seluScale = 1.0507009873554804934193349852946
+)
+
+// leaky ReLU and ELU are parameterized by alpha. The operator tables hold the versions with the default alpha.
+// elemUnaryOp binds its own alpha (see elemUnaryOp.f64() and elemUnaryOp.f32())
+func _defaultLeakyReluf64(x float64) float64 { return _leakyReluf64(x, defaultLeakyReluAlpha) }
+func _defaultLeakyReluf32(x float32) float32 { return _leakyReluf32(x, defaultLeakyReluAlpha) }
+func _defaultEluf64(x float64) float64       { return _eluf64(x, defaultEluAlpha) }
+func _defaultEluf32(x float32) float32       { return _eluf32(x, defaultEluAlpha) }
+
+func _leakyReluf64(x, alpha float64) float64 {
+	if x > 0 {
+		return x
+	}
+	return alpha * x
+}
+
+func _leakyReluf32(x, alpha float32) float32 {
+	if x > 0 {
+		return x
+	}
+	return alpha * x
+}
+
+func _eluf64(x, alpha float64) float64 {
+	if x > 0 {
+		return x
+	}
+	return alpha * math.Expm1(x)
+}
+
+func _eluf32(x, alpha float32) float32 {
+	if x > 0 {
+		return x
+	}
+	return alpha * math32.Expm1(x)
+}
+
+func _seluf64(x float64) float64 { return seluScale * _eluf64(x, seluAlpha) }
+func _seluf32(x float32) float32 { return float32(_seluf64(float64(x))) }
+
+// _normCDFf64 is the cumulative distribution function of the standard normal distribution, Φ(x).
+// erfc is used instead of 1+erf so that the tails do not lose precision.
+func _normCDFf64(x float64) float64 { return 0.5 * math.Erfc(-x/math.Sqrt2) }
+func _normCDFf32(x float32) float32 { return float32(_normCDFf64(float64(x))) }
+
+// _normPDFf64 is the probability density function of the standard normal distribution, φ(x)
+func _normPDFf64(x float64) float64 { return math.Exp(-0.5*x*x) / math.Sqrt(2*math.Pi) }
+
+// this is the exact GELU, x·Φ(x), and not the tanh approximation
+func _geluf64(x float64) float64 { return x * _normCDFf64(x) }
+func _geluf32(x float32) float32 { return float32(_geluf64(float64(x))) }
+
+func _swishf64(x float64) float64 { return x * _sigmoidf64(x) }
+func _swishf32(x float32) float32 { return x * _sigmoidf32(x) }
+
+func _mishf64(x float64) float64 { return x * math.Tanh(_softplusf64(x)) }
+func _mishf32(x float32) float32 { return float32(_mishf64(float64(x))) }
+
+// hard sigmoid is relu6(x+3)/6, the same definition used by MobileNetV3
+func _hardSigmoidf64(x float64) float64 {
+	switch {
+	case x <= -3:
+		return 0
+	case x >= 3:
+		return 1
+	}
+	return x/6 + 0.5
+}
+
+func _hardSigmoidf32(x float32) float32 {
+	switch {
+	case x <= -3:
+		return 0
+	case x >= 3:
+		return 1
+	}
+	return x/6 + 0.5
+}
+
+/* derivatives of the activation functions, used by the autodiff functions */
+
+func _leakyReluDiff(x, alpha float64) float64 {
+	if x > 0 {
+		return 1
+	}
+	return alpha
+}
+
+func _eluDiff(x, alpha float64) float64 {
+	if x > 0 {
+		return 1
+	}
+	return alpha * math.Exp(x)
+}
+
+func _seluDiff(x float64) float64 { return seluScale * _eluDiff(x, seluAlpha) }
+func _geluDiff(x float64) float64 { return _normCDFf64(x) + x*_normPDFf64(x) }
+
+func _swishDiff(x float64) float64 {
+	s := _sigmoidf64(x)
+	return s + x*s*(1-s)
+}
+
+func _mishDiff(x float64) float64 {
+	t := math.Tanh(_softplusf64(x))
+	return t + x*(1-t*t)*_sigmoidf64(x)
+}
+
+func _hardSigmoidDiff(x float64) float64 {
+	if x > -3 && x < 3 {
+		return 1.0 / 6.0
+	}
+	return 0
+}
+
+/* TRIGONOMETRIC, HYPERBOLIC AND SPECIAL FUNCTIONS */
+
+func _lgammaf64(x float64) float64 {
+	r, _ := math.Lgamma(x)
+	return r
+}
+
+func _lgammaf32(x float32) float32 {
+	r, _ := math32.Lgamma(x)
+	return r
+}
+
+// _digammaf64 is the digamma function ψ(x), the derivative of lgamma. The poles (0, -1, -2...) are NaN.
+// It uses the recurrence ψ(x) = ψ(x+1) - 1/x until x ≥ 6, then the asymptotic expansion.
+// Negative values use the reflection formula ψ(x) = ψ(1-x) - π/tan(πx).
+func _digammaf64(x float64) float64 {
+	switch {
+	case math.IsNaN(x) || math.IsInf(x, -1):
+		return math.NaN()
+	case math.IsInf(x, 1):
+		return x
+	case x <= 0 && x == math.Floor(x):
+		return math.NaN()
+	}
+
+	var retVal float64
+	if x < 0 {
+		retVal = -math.Pi / math.Tan(math.Pi*x)
+		x = 1 - x
+	}
+	for x < 6 {
+		retVal -= 1 / x
+		x++
+	}
+
+	f := 1 / (x * x)
+	retVal += math.Log(x) - 0.5/x - f*(1.0/12-f*(1.0/120-f*(1.0/252-f*(1.0/240-f/132))))
+	return retVal
+}
+
+func _digammaf32(x float32) float32 { return float32(_digammaf64(float64(x))) }
+
+// _trigammaf64 is the trigamma function ψ₁(x), the derivative of digamma. The poles (0, -1, -2...) are +Inf.
+// Like digamma, it uses the recurrence ψ₁(x) = ψ₁(x+1) + 1/x² and then the asymptotic expansion.
+// Negative values use the reflection formula ψ₁(x) = π²/sin²(πx) - ψ₁(1-x).
+func _trigammaf64(x float64) float64 {
+	switch {
+	case math.IsNaN(x) || math.IsInf(x, -1):
+		return math.NaN()
+	case math.IsInf(x, 1):
+		return 0
+	case x <= 0 && x == math.Floor(x):
+		return math.Inf(1)
+	}
+
+	if x < 0 {
+		s := math.Sin(math.Pi * x)
+		return math.Pi*math.Pi/(s*s) - _trigammaf64(1-x)
+	}
+
+	var retVal float64
+	for x < 6 {
+		retVal += 1 / (x * x)
+		x++
+	}
+
+	f := 1 / (x * x)
+	retVal += 1/x + f/2 + f/x*(1.0/6-f*(1.0/30-f*(1.0/42-f/30)))
+	return retVal
+}
+
+func _trigammaf32(x float32) float32 { return float32(_trigammaf64(float64(x))) }
+
+/* derivatives of the trigonometric, hyperbolic and special functions, used by the autodiff functions */
+
+func _tanDiff(x float64) float64 {
+	t := math.Tan(x)
+	return 1 + t*t
+}
+
+func _asinDiff(x float64) float64  { return 1 / math.Sqrt(1-x*x) }
+func _acosDiff(x float64) float64  { return -1 / math.Sqrt(1-x*x) }
+func _atanDiff(x float64) float64  { return 1 / (1 + x*x) }
+func _atanhDiff(x float64) float64 { return 1 / (1 - x*x) }
+func _log10Diff(x float64) float64 { return 1 / (x * math.Ln10) }
+func _erfDiff(x float64) float64   { return 2 / math.SqrtPi * math.Exp(-x*x) }
+func _erfcDiff(x float64) float64  { return -2 / math.SqrtPi * math.Exp(-x*x) }
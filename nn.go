@@ -2,7 +2,9 @@ package gorgonia
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/pkg/errors"
 )
 
@@ -65,6 +67,374 @@ func BinaryXent(output, target *Node) (retVal *Node, err error) {
 	return Neg(retVal)
 }
 
+// SoftmaxCrossEntropy computes the cross entropy between softmax(logits) and the targets, for each example.
+// The logits are either a vector of class scores, or a matrix with one example per row.
+// The targets are either Int class labels (a scalar or a vector with one label per row), or a Float tensor
+// of the same shape as the logits, such as one-hot vectors.
+//
+// The softmax, log and sum are fused into one op, which is numerically stable, and whose gradient wrt the logits is
+//		softmax(logits) - onehot(labels)
+// The result is a scalar for a vector of logits, and a vector of losses otherwise. Use Mean or Sum to get a cost.
+func SoftmaxCrossEntropy(logits, targets *Node) (retVal *Node, err error) {
+	if _, ok := logits.t.(*TensorType); !ok || logits.IsScalar() {
+		err = NewError(TypeError, "SoftmaxCrossEntropy expects the logits to be a Tensor. Got %v instead", logits.t)
+		return
+	}
+	if logits.shape == nil || targets.shape == nil {
+		err = NewError(ShapeError, "SoftmaxCrossEntropy cannot infer the shape of the loss of %v and %v", logits, targets)
+		return
+	}
+	if logits.Dims() > 2 {
+		err = NewError(ShapeError, "SoftmaxCrossEntropy expects the logits to be a vector or matrix. Got %v instead", logits.shape)
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(targets.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, targets.t)
+		return
+	}
+
+	sparse := dt == Int
+	if sparse {
+		rows := reduceShape(logits.shape, axes{len(logits.shape) - 1})
+		if !targets.shape.Eq(rows) {
+			err = NewError(ShapeError, "SoftmaxCrossEntropy expects one label for each row of the logits %v. Got labels of shape %v", logits.shape, targets.shape)
+			return
+		}
+	} else if !targets.shape.Eq(logits.shape) {
+		err = NewError(ShapeError, "SoftmaxCrossEntropy expects the targets to have the same shape as the logits %v. Got %v", logits.shape, targets.shape)
+		return
+	}
+
+	op := newSoftmaxXentOp(logits.shape, sparse)
+	return applyOp(op, logits, targets)
+}
+
+// LossReduction is how the losses of the individual elements are combined by the loss functions
+type LossReduction byte
+
+const (
+	// MeanReduction averages the losses over all the elements. This is the default.
+	MeanReduction LossReduction = iota
+	// SumReduction sums up the losses of all the elements
+	SumReduction
+	// NoReduction keeps the loss of each element
+	NoReduction
+)
+
+// LossOpt is an option for the loss functions
+type LossOpt func(*lossConfig)
+
+type lossConfig struct {
+	reduction LossReduction
+	weights   *Node
+}
+
+// WithReduction sets how the losses of the elements are combined.
+func WithReduction(r LossReduction) LossOpt {
+	f := func(c *lossConfig) {
+		c.reduction = r
+	}
+	return f
+}
+
+// WithSampleWeights weighs the loss of each sample before the losses are reduced.
+// The weights are either a scalar, a vector with one weight per sample (the samples are along the first axis),
+// or a tensor with one weight for each element of the loss.
+func WithSampleWeights(w *Node) LossOpt {
+	f := func(c *lossConfig) {
+		c.weights = w
+	}
+	return f
+}
+
+// MSE is the mean squared error loss:
+//		(output - target)²
+func MSE(output, target *Node, opts ...LossOpt) (retVal *Node, err error) {
+	var diff *Node
+	if diff, err = Sub(output, target); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if retVal, err = Square(diff); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reduceLoss(retVal, opts)
+}
+
+// MAE is the mean absolute error loss:
+//		|output - target|
+func MAE(output, target *Node, opts ...LossOpt) (retVal *Node, err error) {
+	var diff *Node
+	if diff, err = Sub(output, target); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if retVal, err = Abs(diff); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reduceLoss(retVal, opts)
+}
+
+// Huber is the Huber loss. It is quadratic for small errors and linear for large ones, making it less sensitive to outliers than MSE:
+//		0.5 * (output - target)²                if |output - target| <= delta
+//		delta * (|output - target| - 0.5*delta) otherwise
+func Huber(output, target *Node, delta float64, opts ...LossOpt) (retVal *Node, err error) {
+	if delta <= 0 {
+		err = NewError(RuntimeError, "Huber expects delta to be positive. Got %v", delta)
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(output.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, output.t)
+		return
+	}
+
+	var half, d, halfD *Node
+	if half, err = floatConstant(dt, 0.5); err != nil {
+		return
+	}
+	if d, err = floatConstant(dt, delta); err != nil {
+		return
+	}
+	if halfD, err = floatConstant(dt, 0.5*delta); err != nil {
+		return
+	}
+
+	var diff, abs, small, quad, lin *Node
+	if diff, err = Sub(output, target); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if abs, err = Abs(diff); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if small, err = Lte(abs, d, false); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	if quad, err = Square(diff); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if quad, err = HadamardProd(half, quad); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	if lin, err = Sub(abs, halfD); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if lin, err = HadamardProd(d, lin); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	if retVal, err = Where(small, quad, lin); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reduceLoss(retVal, opts)
+}
+
+// SmoothL1 is the smooth L1 loss, which is the Huber loss divided by beta:
+//		0.5 * (output - target)² / beta   if |output - target| < beta
+//		|output - target| - 0.5*beta      otherwise
+func SmoothL1(output, target *Node, beta float64, opts ...LossOpt) (retVal *Node, err error) {
+	if retVal, err = Huber(output, target, beta, WithReduction(NoReduction)); err != nil {
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(output.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, output.t)
+		return
+	}
+
+	var b *Node
+	if b, err = floatConstant(dt, beta); err != nil {
+		return
+	}
+	if retVal, err = HadamardDiv(retVal, b); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reduceLoss(retVal, opts)
+}
+
+// Hinge is the hinge loss. The targets are expected to be -1 or 1:
+//		max(0, 1 - output * target)
+func Hinge(output, target *Node, opts ...LossOpt) (retVal *Node, err error) {
+	if retVal, err = hingeMargin(output, target); err != nil {
+		return
+	}
+	return reduceLoss(retVal, opts)
+}
+
+// SquaredHinge is the squared hinge loss. The targets are expected to be -1 or 1:
+//		max(0, 1 - output * target)²
+func SquaredHinge(output, target *Node, opts ...LossOpt) (retVal *Node, err error) {
+	if retVal, err = hingeMargin(output, target); err != nil {
+		return
+	}
+	if retVal, err = Square(retVal); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reduceLoss(retVal, opts)
+}
+
+// KLDivergence is the Kullback-Leibler divergence of the output distribution from the target distribution.
+// Both are expected to be probabilities. Elements where the target is 0 do not contribute to the loss, even if the output is 0 too:
+//		target * (log(target) - log(output))
+func KLDivergence(output, target *Node, opts ...LossOpt) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(target.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, target.t)
+		return
+	}
+
+	var zero, one *Node
+	if zero, err = floatConstant(dt, 0); err != nil {
+		return
+	}
+	if one, err = floatConstant(dt, 1); err != nil {
+		return
+	}
+
+	// where the target is 0, both the target and the output are replaced with 1, so that the whole term is 0 * (log(1) - log(1)).
+	// Otherwise 0 * log(0) would be NaN, and so would the gradient of log(output) at an output of 0
+	var mask, safeT, safeO, logT, logO *Node
+	if mask, err = Gt(target, zero, false); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if safeT, err = Where(mask, target, one); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if safeO, err = Where(mask, output, one); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if logT, err = Log(safeT); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if logO, err = Log(safeO); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	if retVal, err = Sub(logT, logO); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if retVal, err = HadamardProd(target, retVal); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reduceLoss(retVal, opts)
+}
+
+// hingeMargin computes max(0, 1 - output * target)
+func hingeMargin(output, target *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(output.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, output.t)
+		return
+	}
+
+	var zero, one *Node
+	if zero, err = floatConstant(dt, 0); err != nil {
+		return
+	}
+	if one, err = floatConstant(dt, 1); err != nil {
+		return
+	}
+
+	var margin, positive *Node
+	if margin, err = HadamardProd(output, target); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if margin, err = Sub(one, margin); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if positive, err = Gt(margin, zero, false); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return Where(positive, margin, zero)
+}
+
+// reduceLoss weighs and reduces the elementwise losses according to the options
+func reduceLoss(loss *Node, opts []LossOpt) (retVal *Node, err error) {
+	var c lossConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	retVal = loss
+	if w := c.weights; w != nil {
+		if !w.IsScalar() && !loss.IsScalar() && !w.shape.Eq(loss.shape) {
+			if !w.IsVector() || w.shape.TotalSize() != loss.shape[0] {
+				err = NewError(ShapeError, "Expected one weight per sample of the loss %v. Got weights of shape %v", loss.shape, w.shape)
+				return
+			}
+
+			// one weight per sample: the weights are broadcast across the other axes
+			s := make(types.Shape, len(loss.shape))
+			for i := range s {
+				s[i] = 1
+			}
+			s[0] = loss.shape[0]
+			if w, err = Reshape(w, s...); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+
+		if retVal, err = HadamardProd(retVal, w); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+
+	switch c.reduction {
+	case MeanReduction:
+		return Mean(retVal)
+	case SumReduction:
+		return Sum(retVal)
+	case NoReduction:
+		return
+	default:
+		err = NewError(RuntimeError, "Unknown loss reduction %v", c.reduction)
+	}
+	return
+}
+
+// floatConstant creates a scalar constant of the given Dtype
+func floatConstant(dt Dtype, v float64) (retVal *Node, err error) {
+	switch dt {
+	case Float64:
+		retVal = NewConstant(v)
+	case Float32:
+		retVal = NewConstant(float32(v))
+	default:
+		err = nyi("floatConstant", dt)
+	}
+	return
+}
+
 // Dropout is a convenience function to implement dropout.
 // It uses randomly zeroes out a *Tensor with a probabilty drawn from
 // a uniform distribution
@@ -137,3 +507,211 @@ func Rectify(x *Node) (retVal *Node, err error) {
 
 	return HadamardProd(x, retVal)
 }
+
+// Conv2d performs a 2D convolution of an image in NCHW format with a filter of shape (F, C, KH, KW).
+// stride, pad and dilation are (H, W) pairs. A nil stride or dilation defaults to 1, a nil pad to 0.
+//
+// The convolution is done by unrolling the windows of the image into a matrix (im2col), and then multiplying it with the filter.
+func Conv2d(im, filter *Node, stride, pad, dilation []int) (retVal *Node, err error) {
+	if im.shape == nil || filter.shape == nil {
+		err = NewError(ShapeError, "Conv2d requires the shapes of the image and filter to be known")
+		return
+	}
+
+	is, fs := im.shape, filter.shape
+	if len(is) != 4 || len(fs) != 4 {
+		err = NewError(ShapeError, "Conv2d expects a 4D image and a 4D filter. Got %v and %v instead", is, fs)
+		return
+	}
+
+	if is[1] != fs[1] {
+		err = NewError(ShapeError, "Conv2d expects the image and filter to have the same number of channels. Got %v and %v", is, fs)
+		return
+	}
+
+	var w window
+	if w, err = makeWindow(fs[2:], stride, pad, dilation); err != nil {
+		return
+	}
+
+	var cols *Node
+	if cols, err = applyOp(im2colOp{w}, im); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	n, oh, ow, rowSize := cols.shape[0], cols.shape[1], cols.shape[2], cols.shape[3]
+	f := fs[0]
+
+	// (N*OH*OW, C*KH*KW) × (C*KH*KW, F)
+	var flatFilter *Node
+	if cols, err = Reshape(cols, n*oh*ow, rowSize); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if flatFilter, err = Reshape(filter, f, rowSize); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if flatFilter, err = Transpose(flatFilter); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if retVal, err = Mul(cols, flatFilter); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	// (N, OH, OW, F) → (N, F, OH, OW)
+	if retVal, err = Reshape(retVal, n, oh, ow, f); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return Transpose(retVal, 0, 3, 1, 2)
+}
+
+// MaxPool2D takes the maximum value of every window of an image in NCHW format.
+// kernel, stride and pad are (H, W) pairs. A nil stride defaults to the kernel size, and a nil pad to 0.
+func MaxPool2D(x *Node, kernel, stride, pad []int) (retVal *Node, err error) {
+	var w window
+	if w, err = poolWindow(x, kernel, stride, pad); err != nil {
+		return
+	}
+	return applyOp(maxPoolOp{w}, x)
+}
+
+// AvgPool2D takes the average value of every window of an image in NCHW format. Padding counts towards the average.
+// kernel, stride and pad are (H, W) pairs. A nil stride defaults to the kernel size, and a nil pad to 0.
+func AvgPool2D(x *Node, kernel, stride, pad []int) (retVal *Node, err error) {
+	var w window
+	if w, err = poolWindow(x, kernel, stride, pad); err != nil {
+		return
+	}
+	return applyOp(avgPoolOp{w}, x)
+}
+
+func poolWindow(x *Node, kernel, stride, pad []int) (w window, err error) {
+	if x.shape == nil || len(x.shape) != 4 {
+		err = NewError(ShapeError, "Pooling expects a 4D image in NCHW format. Got %v instead", x.shape)
+		return
+	}
+
+	if stride == nil {
+		stride = kernel
+	}
+	return makeWindow(kernel, stride, pad, nil)
+}
+
+func makeWindow(kernel, stride, pad, dilation []int) (w window, err error) {
+	if stride == nil {
+		stride = []int{1, 1}
+	}
+	if pad == nil {
+		pad = []int{0, 0}
+	}
+	if dilation == nil {
+		dilation = []int{1, 1}
+	}
+
+	if len(kernel) != 2 || len(stride) != 2 || len(pad) != 2 || len(dilation) != 2 {
+		err = NewError(ShapeError, "Expected (H, W) pairs for the kernel, stride, pad and dilation. Got %v, %v, %v and %v", kernel, stride, pad, dilation)
+		return
+	}
+
+	w = window{
+		kh: kernel[0], kw: kernel[1],
+		padH: pad[0], padW: pad[1],
+		strideH: stride[0], strideW: stride[1],
+		dilH: dilation[0], dilW: dilation[1],
+	}
+
+	if w.kh <= 0 || w.kw <= 0 || w.strideH <= 0 || w.strideW <= 0 || w.dilH <= 0 || w.dilW <= 0 || w.padH < 0 || w.padW < 0 {
+		err = NewError(ShapeError, "Invalid window %v", w)
+	}
+	return
+}
+
+// BatchNorm normalizes each feature (axis 1) of x to a mean of 0 and a variance of 1 over the rest of the axes, then scales and shifts it:
+//		y = scale * (x - mean) / √(var + eps) + bias
+// scale and bias are vectors with one element per feature.
+//
+// In training mode (the default) the means and variances are those of the batch, and the running statistics are updated with
+//		running = momentum * running + (1 - momentum) * batch
+// In inference mode the running statistics are used instead. The mode is a VMOpt (TrainingMode or InferenceMode), so the graph
+// does not have to be rebuilt to switch between them. The running statistics can be read with BatchNormStatsOf.
+func BatchNorm(x, scale, bias *Node, momentum, eps float64) (retVal *Node, err error) {
+	if x.shape == nil || x.shape.Dims() < 2 {
+		err = NewError(ShapeError, "BatchNorm expects the input to have at least 2 dimensions. Got %v instead", x.shape)
+		return
+	}
+	if momentum < 0 || momentum > 1 {
+		err = NewError(RuntimeError, "BatchNorm expects a momentum between 0 and 1. Got %v instead", momentum)
+		return
+	}
+	if eps < 0 {
+		err = NewError(RuntimeError, "BatchNorm expects a non negative epsilon. Got %v instead", eps)
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	along := axes{0}
+	along = append(along, intRange(2, x.shape.Dims())...)
+	op := normalizeOp{
+		along:      along,
+		params:     axes{1},
+		eps:        eps,
+		inputShape: x.shape.Clone(),
+		stats:      newBatchNormStats(dt, x.shape[1], momentum),
+	}
+	return normalize(op, x, scale, bias)
+}
+
+// BatchNormStatsOf returns the running statistics of n, which has to be the result of a BatchNorm.
+func BatchNormStatsOf(n *Node) (*BatchNormStats, error) {
+	if op, ok := n.op.(normalizeOp); ok && op.stats != nil {
+		return op.stats, nil
+	}
+	return nil, NewError(GraphError, "%v is not the result of a BatchNorm", n)
+}
+
+// LayerNorm normalizes x to a mean of 0 and a variance of 1 over the given axes (all of them, if none are given), then scales and shifts it:
+//		y = scale * (x - mean) / √(var + eps) + bias
+// scale and bias have the shape of x along those axes. The statistics don't depend on the rest of the batch,
+// so unlike BatchNorm, LayerNorm behaves the same way in training and inference.
+func LayerNorm(x, scale, bias *Node, along []int, eps float64) (retVal *Node, err error) {
+	if x.shape == nil || x.IsScalar() {
+		err = NewError(ShapeError, "LayerNorm expects a tensor input. Got %v instead", x.shape)
+		return
+	}
+	if eps < 0 {
+		err = NewError(RuntimeError, "LayerNorm expects a non negative epsilon. Got %v instead", eps)
+		return
+	}
+	if along, err = reductionAxes(x, along); err != nil {
+		return
+	}
+
+	sorted := make(axes, len(along))
+	copy(sorted, along)
+	sort.Ints(sorted)
+	op := normalizeOp{
+		along:      sorted,
+		params:     sorted,
+		eps:        eps,
+		inputShape: x.shape.Clone(),
+	}
+	return normalize(op, x, scale, bias)
+}
+
+func normalize(op normalizeOp, x, scale, bias *Node) (retVal *Node, err error) {
+	ps := op.paramShape()
+	if !ps.Eq(scale.shape) || !ps.Eq(bias.shape) {
+		err = NewError(ShapeError, "%v expects the scale and bias to be of shape %v. Got %v and %v instead", op, ps, scale.shape, bias.shape)
+		return
+	}
+	return applyOp(op, x, scale, bias)
+}
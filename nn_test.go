@@ -0,0 +1,158 @@
+package gorgonia
+
+import (
+	"math"
+	"testing"
+
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLosses(t *testing.T) {
+	assert := assert.New(t)
+	outputs := []float64{0.5, -1, 2, 0.2}
+	targets := []float64{1, -1, 1, -1}
+	probs := []float64{0.1, 0.2, 0.3, 0.4}
+	dist := []float64{0, 0.5, 0.25, 0.25}
+
+	var lossTests = []struct {
+		name    string
+		loss    func(o, t *Node, opts ...LossOpt) (*Node, error)
+		o, t    []float64
+		correct func(o, t float64) float64
+	}{
+		{"MSE", MSE, outputs, targets, func(o, t float64) float64 { return (o - t) * (o - t) }},
+		{"MAE", MAE, outputs, targets, func(o, t float64) float64 { return math.Abs(o - t) }},
+		{"Huber",
+			func(o, t *Node, opts ...LossOpt) (*Node, error) { return Huber(o, t, 1, opts...) },
+			outputs, targets,
+			func(o, t float64) float64 {
+				if d := math.Abs(o - t); d > 1 {
+					return d - 0.5
+				}
+				return 0.5 * (o - t) * (o - t)
+			}},
+		{"SmoothL1",
+			func(o, t *Node, opts ...LossOpt) (*Node, error) { return SmoothL1(o, t, 2, opts...) },
+			outputs, targets,
+			func(o, t float64) float64 {
+				if d := math.Abs(o - t); d > 2 {
+					return d - 1
+				}
+				return 0.25 * (o - t) * (o - t)
+			}},
+		{"Hinge", Hinge, outputs, targets, func(o, t float64) float64 { return math.Max(0, 1-o*t) }},
+		{"SquaredHinge", SquaredHinge, outputs, targets, func(o, t float64) float64 { return math.Pow(math.Max(0, 1-o*t), 2) }},
+		{"KLDivergence", KLDivergence, probs, dist, func(o, t float64) float64 {
+			if t == 0 {
+				return 0
+			}
+			return t * (math.Log(t) - math.Log(o))
+		}},
+		// the output is 0 where the target is 0
+		{"KLDivergence of zeroes", KLDivergence, []float64{0, 0.5, 0.3, 0.2}, dist, func(o, t float64) float64 {
+			if t == 0 {
+				return 0
+			}
+			return t * (math.Log(t) - math.Log(o))
+		}},
+	}
+
+	for _, lt := range lossTests {
+		correct := make([]float64, len(lt.o))
+		var sum float64
+		for i := range lt.o {
+			correct[i] = lt.correct(lt.o[i], lt.t[i])
+			sum += correct[i]
+		}
+
+		g := NewGraph()
+		o := NewVector(g, Float64, WithName("o"), WithShape(len(lt.o)), WithValue(tf64.NewTensor(tf64.WithShape(len(lt.o)), tf64.WithBacking(lt.o))))
+		tg := NewVector(g, Float64, WithName("t"), WithShape(len(lt.t)), WithValue(tf64.NewTensor(tf64.WithShape(len(lt.t)), tf64.WithBacking(lt.t))))
+		none := Must(lt.loss(o, tg, WithReduction(NoReduction)))
+		total := Must(lt.loss(o, tg, WithReduction(SumReduction)))
+		mean := Must(lt.loss(o, tg))
+
+		m := NewLispMachine(g, ExecuteFwdOnly())
+		if err := m.RunAll(); err != nil {
+			t.Fatalf("%v: %v", lt.name, err)
+		}
+
+		noneData := extractF64s(none.Value())
+		for i, v := range correct {
+			assert.InDelta(v, noneData[i], 1e-10, lt.name)
+		}
+		assert.InDelta(sum, total.Value().(Scalar).v, 1e-10, lt.name)
+		assert.InDelta(sum/float64(len(correct)), mean.Value().(Scalar).v, 1e-10, lt.name)
+	}
+}
+
+func TestKLDivergenceZeroes(t *testing.T) {
+	assert := assert.New(t)
+	outputs := []float64{0, 0.5, 0.3, 0.2}
+	targets := []float64{0, 0.5, 0.25, 0.25}
+
+	g := NewGraph()
+	o := NewVector(g, Float64, WithName("o"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking(outputs))))
+	tg := NewVector(g, Float64, WithName("t"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking(targets))))
+	Must(KLDivergence(o, tg, WithReduction(SumReduction)))
+
+	if err := NewLispMachine(g).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the gradient wrt the outputs is -target/output, and 0 where the target is 0
+	oG, _ := o.Grad()
+	oGData := extractF64s(oG)
+	assert.Equal(0.0, oGData[0])
+	for i := 1; i < len(outputs); i++ {
+		assert.InDelta(-targets[i]/outputs[i], oGData[i], 1e-10)
+	}
+}
+
+func TestLossWeights(t *testing.T) {
+	assert := assert.New(t)
+	outputs := []float64{1, 2, 3, 4, 5, 6}
+	targets := []float64{0, 2, 1, 4, 4, 8}
+	weights := []float64{1, 0.5}
+
+	// the weighted mean of the squared errors, and its gradient wrt the outputs
+	var correct float64
+	correctGrad := make([]float64, len(outputs))
+	for i := range outputs {
+		w := weights[i/3]
+		d := outputs[i] - targets[i]
+		correct += w * d * d / 6
+		correctGrad[i] = 2 * w * d / 6
+	}
+
+	g := NewGraph()
+	o := NewMatrix(g, Float64, WithName("o"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(outputs))))
+	tg := NewMatrix(g, Float64, WithName("t"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(targets))))
+	w := NewVector(g, Float64, WithName("w"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking(weights))))
+	cost := Must(MSE(o, tg, WithSampleWeights(w)))
+	if _, err := Grad(cost, o); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.InDelta(correct, cost.Value().(Scalar).v, 1e-10)
+	oG, _ := o.Grad()
+	oGData := extractF64s(oG)
+	for i, v := range correctGrad {
+		assert.InDelta(v, oGData[i], 1e-10)
+	}
+
+	// one weight per sample is required
+	bad := NewVector(g, Float64, WithName("bad"), WithShape(3))
+	_, err = MSE(o, tg, WithSampleWeights(bad))
+	assert.NotNil(err)
+}
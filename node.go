@@ -620,6 +620,16 @@ func (n *Node) diffWRT() []bool {
 	return n.op.DiffWRT(len(n.children))
 }
 
+// isDifferentiable returns true if the node's op is differentiable with regards to any of its inputs
+func isDifferentiable(n *Node) bool {
+	for _, d := range n.diffWRT() {
+		if d {
+			return true
+		}
+	}
+	return false
+}
+
 // dfs but does not use channels. useful for extracting paths. used particularly in test
 func (n *Node) seqWalk() Nodes {
 	retVal := Nodes{n}
@@ -89,6 +89,14 @@ type AdOp interface {
 	DoDiff(inputs Nodes, output *Node) error
 }
 
+// A FwdDiffOp is an Op that supports forward mode differentiation. Given the tangents of its inputs, FwdDiff returns
+// the tangent of the output. A nil tangent is a tangent of zeroes.
+type FwdDiffOp interface {
+	Op
+
+	FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error)
+}
+
 // a ReductionOp changes the shape of the node
 type ReductionOp interface {
 	Op
@@ -111,6 +119,14 @@ type UnsafeDoer interface {
 	UnsafeDo(inputs ...Value) (Value, error)
 }
 
+// a modalOp is an Op that behaves differently when training and when doing inference (e.g. batch normalization).
+// The VMs tell it which mode they are in before executing it.
+type modalOp interface {
+	Op
+
+	setTraining(bool)
+}
+
 // a constant is an unchanging value. I think everyone would know what a constant is
 // a constant op is an op that creates a constant. It is also a Value of a constant value
 type constant interface {
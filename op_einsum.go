@@ -0,0 +1,415 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/chewxy/gorgonia/tensor"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/pkg/errors"
+)
+
+/*
+This file holds the einsumOp, and the parsing and planning of the Einstein summation subscripts used by Einsum.
+
+An einsumOp only ever contracts two operands, and every letter of its operands appears either in the other operand or in
+the output. Einsum sums out the other letters, and folds more than two operands into a chain of einsumOps.
+*/
+
+// einsumOp contracts two tensors according to the subscripts a, b and out (e.g. "bij", "bjk" and "bik").
+// It is planned as a transpose and reshape of each operand into (batch, m, k) and (batch, k, n) tensors,
+// a batched matrix multiplication, and a reshape and transpose of the result.
+type einsumOp struct {
+	a, b, out string
+}
+
+func newEinsumOp(a, b, out string) einsumOp {
+	return einsumOp{a: a, b: b, out: out}
+}
+
+// einsumOp has this type:
+//
+//	einsum :: (Floats a) ⇒ Tensor d a → Tensor e a → Tensor f a
+//
+// where d, e and f are the number of subscripts of each. If there are no output subscripts, the result is a scalar.
+func (op einsumOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	var out Type = a
+	if len(op.out) > 0 {
+		out = newTensorType(len(op.out), a)
+	}
+	return newFunctionType(newTensorType(len(op.a), a), newTensorType(len(op.b), a), out)
+}
+
+func (op einsumOp) inferShape(retType Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "einsumOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var sizes map[rune]int
+	if sizes, err = einsumSizes([]string{op.a, op.b}, []types.Shape{inputs[0].shape, inputs[1].shape}); err != nil {
+		return
+	}
+	if len(op.out) == 0 {
+		return scalarShape, nil
+	}
+	return einsumShape(op.out, sizes), nil
+}
+
+func (op einsumOp) DiffWRT(inputs int) []bool { return []bool{true, true} }
+
+// SymDiff derives the transposed einsum for each operand: the gradient of a is einsum(out, b → a) of the gradient and b.
+func (op einsumOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "einsumOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	retVal = make(Nodes, 2)
+	if retVal[0], err = einsumGradNode(op.out, op.b, op.a, gradNode, inputs[1]); err != nil {
+		err = errors.Wrapf(err, "Unable to differentiate %v with regards to its first input", op)
+		return
+	}
+	if retVal[1], err = einsumGradNode(op.out, op.a, op.b, gradNode, inputs[0]); err != nil {
+		err = errors.Wrapf(err, "Unable to differentiate %v with regards to its second input", op)
+	}
+	return
+}
+
+// FwdDiff uses the product rule: an einsum is bilinear, so each tangent is contracted with the other operand.
+func (op einsumOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "einsumOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var da, db *Node
+	if tangents[0] != nil {
+		if da, err = applyOp(op, tangents[0], inputs[1]); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	if tangents[1] != nil {
+		if db, err = applyOp(op, inputs[0], tangents[1]); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	return addTangents(da, db)
+}
+
+func (op einsumOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "einsumOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	dy := output.boundTo.(*dualValue).d
+	pairs := []struct {
+		sub, other string
+		x, y       *Node
+	}{
+		{op.a, op.b, inputs[0], inputs[1]},
+		{op.b, op.a, inputs[1], inputs[0]},
+	}
+
+	for _, p := range pairs {
+		var d Value
+		if d, err = einsumGrad(op.out, p.other, p.sub, dy, p.y.Value()); err != nil {
+			err = errors.Wrapf(err, "Unable to differentiate %v", op)
+			return
+		}
+
+		xdv := p.x.boundTo.(*dualValue)
+		add := newEBOByType(addOpType, xdv.d.Type(), d.Type())
+		if _, err = add.UnsafeDo(xdv.d, d); err != nil {
+			err = errors.Wrapf(err, unsafeDoFail, add)
+			return
+		}
+	}
+	return
+}
+
+func (op einsumOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "einsumOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var a, b types.Tensor
+	if a, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+	if b, err = tensorOf(op, inputs[1]); err != nil {
+		return
+	}
+
+	var sizes map[rune]int
+	if sizes, err = einsumSizes([]string{op.a, op.b}, []types.Shape{a.Shape(), b.Shape()}); err != nil {
+		return
+	}
+
+	batch, free, contracted, freeB := op.plan()
+	nb, na, nc, nn := einsumSize(batch, sizes), einsumSize(free, sizes), einsumSize(contracted, sizes), einsumSize(freeB, sizes)
+
+	// a is arranged into (batch, m, k), and b into (batch, k, n)
+	if a, err = einsumArrange(a, op.a, batch+free+contracted, nb, na, nc); err != nil {
+		return
+	}
+	if b, err = einsumArrange(b, op.b, batch+contracted+freeB, nb, nc, nn); err != nil {
+		return
+	}
+
+	mm := linAlgBinOp{āBinaryOperator: batchedMatMulOperator}
+	var c Value
+	if c, err = mm.batchedMatMul(a, b); err != nil {
+		err = errors.Wrapf(err, doFail, mm)
+		return
+	}
+
+	ct := c.(Tensor).Tensor
+	if len(op.out) == 0 {
+		switch data := ct.Data().(type) {
+		case []float64:
+			return anyToValue(data[0])
+		case []float32:
+			return anyToValue(data[0])
+		}
+		return nil, nyi("einsumOp", ct.Dtype())
+	}
+
+	mid := batch + free + freeB
+	if err = ct.Reshape(einsumShape(mid, sizes)...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, einsumShape(mid, sizes), ct.DataSize())
+		return
+	}
+	if pattern, identity := einsumPattern(mid, op.out); !identity {
+		return newTransposeOp(pattern, len(pattern)).transpose(ct)
+	}
+	return FromTensor(ct), nil
+}
+
+func (op einsumOp) returnsPtr() bool      { return false }
+func (op einsumOp) callsExtern() bool     { return false }
+func (op einsumOp) overwriteInput() int   { return -1 }
+func (op einsumOp) WriteHash(h hash.Hash) { fmt.Fprintf(h, "einsum%v", op) }
+
+func (op einsumOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op einsumOp) String() string { return fmt.Sprintf("Einsum(%s,%s->%s)", op.a, op.b, op.out) }
+
+// fulfils BinaryOp interface
+func (op einsumOp) isBinary() bool { return true }
+
+// plan classifies the letters of the op. The batch letters are in both operands and the output, the free letters
+// are in only one of the operands and the output, and the contracted letters are in both operands but not the output.
+func (op einsumOp) plan() (batch, free, contracted, freeB string) {
+	for _, r := range op.out {
+		inA, inB := strings.ContainsRune(op.a, r), strings.ContainsRune(op.b, r)
+		switch {
+		case inA && inB:
+			batch += string(r)
+		case inA:
+			free += string(r)
+		default:
+			freeB += string(r)
+		}
+	}
+	for _, r := range op.a {
+		if strings.ContainsRune(op.b, r) && !strings.ContainsRune(op.out, r) {
+			contracted += string(r)
+		}
+	}
+	return
+}
+
+// einsumArrange transposes a copy of t from the subscripts from into the subscripts to, and reshapes it into a 3-tensor
+func einsumArrange(t types.Tensor, from, to string, shape ...int) (retVal types.Tensor, err error) {
+	retVal = tensor.Clone(t)
+	if pattern, identity := einsumPattern(from, to); !identity {
+		var v Value
+		if v, err = newTransposeOp(pattern, len(pattern)).transpose(retVal); err != nil {
+			return
+		}
+		retVal = v.(Tensor).Tensor
+	}
+
+	if err = retVal.Reshape(shape...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, shape, retVal.DataSize())
+	}
+	return
+}
+
+// einsumGradNode creates the gradient of the operand with subscripts wrt, given the gradient of the output
+// and the other operand. When the output is a scalar, every letter of the operand is in the other operand,
+// so the gradient is the other operand (transposed) scaled by the gradient.
+func einsumGradNode(out, other, wrt string, grad, y *Node) (retVal *Node, err error) {
+	if len(out) == 0 {
+		if retVal, err = Einsum(other+"->"+wrt, y); err != nil {
+			return
+		}
+		WithGroupName(gradClust)(retVal)
+		return HadamardProd(retVal, grad)
+	}
+	return applyOp(newEinsumOp(out, other, wrt), grad, y)
+}
+
+// einsumGrad is the Value equivalent of einsumGradNode
+func einsumGrad(out, other, wrt string, grad, y Value) (retVal Value, err error) {
+	if len(out) > 0 {
+		return newEinsumOp(out, other, wrt).Do(grad, y)
+	}
+
+	var t types.Tensor
+	if t, err = tensorOf(einsumOp{}, y); err != nil {
+		return
+	}
+	if pattern, identity := einsumPattern(other, wrt); !identity {
+		if y, err = newTransposeOp(pattern, len(pattern)).Do(y); err != nil {
+			return
+		}
+	} else {
+		y = FromTensor(tensor.Clone(t))
+	}
+
+	mul := newEBOByType(mulOpType, y.Type(), grad.Type())
+	return mul.UnsafeDo(y, grad)
+}
+
+/* PARSING AND PLANNING */
+
+// parseEinsum parses subscripts such as "bij,bjk->bik" into the subscripts of each operand and of the output.
+// If there is no "->", the output is the letters that appear exactly once, in alphabetical order.
+func parseEinsum(subscripts string, operands int) (ins []string, out string, err error) {
+	subscripts = strings.Replace(subscripts, " ", "", -1)
+
+	lhs := subscripts
+	explicit := strings.Contains(subscripts, "->")
+	if explicit {
+		parts := strings.Split(subscripts, "->")
+		if len(parts) != 2 {
+			err = NewError(GraphError, "Einsum subscripts %q have more than one \"->\"", subscripts)
+			return
+		}
+		lhs, out = parts[0], parts[1]
+	}
+
+	ins = strings.Split(lhs, ",")
+	if len(ins) != operands {
+		err = NewError(GraphError, "Einsum subscripts %q are for %d operands. Got %d operands instead", subscripts, len(ins), operands)
+		return
+	}
+
+	counts := make(map[rune]int)
+	for _, in := range ins {
+		if err = checkSubscripts(in); err != nil {
+			return
+		}
+		for _, r := range in {
+			counts[r]++
+		}
+	}
+
+	if !explicit {
+		var once []string
+		for r, c := range counts {
+			if c == 1 {
+				once = append(once, string(r))
+			}
+		}
+		sort.Strings(once)
+		out = strings.Join(once, "")
+		return
+	}
+
+	if err = checkSubscripts(out); err != nil {
+		return
+	}
+	for _, r := range out {
+		if counts[r] == 0 {
+			err = NewError(GraphError, "Einsum output subscript %q does not appear in the inputs %q", r, lhs)
+			return
+		}
+	}
+	return
+}
+
+// checkSubscripts checks that the subscripts are letters, and that no letter is repeated.
+// Repeated letters (i.e. diagonals and traces such as "ii->i") are not supported.
+func checkSubscripts(s string) error {
+	for i, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return NewError(GraphError, "Einsum subscripts have to be letters. Got %q in %q", r, s)
+		}
+		if strings.ContainsRune(s[i+1:], r) {
+			return NewError(GraphError, "Einsum does not support repeated subscripts. %q is repeated in %q", r, s)
+		}
+	}
+	return nil
+}
+
+// einsumSizes returns the size of each letter, checking that the sizes agree between the operands
+func einsumSizes(subs []string, shapes []types.Shape) (retVal map[rune]int, err error) {
+	retVal = make(map[rune]int)
+	for i, sub := range subs {
+		if len(sub) != len(shapes[i]) {
+			err = NewError(ShapeError, "Einsum subscripts %q do not match the shape %v", sub, shapes[i])
+			return
+		}
+		for j, r := range sub {
+			size, ok := retVal[r]
+			if ok && size != shapes[i][j] {
+				err = NewError(ShapeError, "Einsum subscript %q has sizes %d and %d", r, size, shapes[i][j])
+				return
+			}
+			retVal[r] = shapes[i][j]
+		}
+	}
+	return
+}
+
+func einsumShape(sub string, sizes map[rune]int) types.Shape {
+	retVal := make(types.Shape, 0, len(sub))
+	for _, r := range sub {
+		retVal = append(retVal, sizes[r])
+	}
+	return retVal
+}
+
+func einsumSize(sub string, sizes map[rune]int) int {
+	retVal := 1
+	for _, r := range sub {
+		retVal *= sizes[r]
+	}
+	return retVal
+}
+
+// einsumPattern returns the transpose pattern that rearranges the subscripts from into the subscripts to,
+// and whether the pattern is the identity
+func einsumPattern(from, to string) (pattern []int, identity bool) {
+	identity = true
+	for i, r := range to {
+		pattern = append(pattern, strings.IndexRune(from, r))
+		identity = identity && pattern[i] == i
+	}
+	return
+}
+
+// einsumSubscripts returns the letters of s that are in keep, in the order of their first appearance in s
+func einsumSubscripts(s, keep string) (retVal string) {
+	for _, r := range s {
+		if strings.ContainsRune(keep, r) && !strings.ContainsRune(retVal, r) {
+			retVal += string(r)
+		}
+	}
+	return
+}
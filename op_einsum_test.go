@@ -0,0 +1,196 @@
+package gorgonia
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var einsumTests = []struct {
+	subscripts string
+	shapes     []types.Shape
+	correct    types.Shape
+}{
+	{"ij,jk->ik", []types.Shape{{2, 3}, {3, 4}}, types.Shape{2, 4}},
+	{"bij,bjk->bik", []types.Shape{{2, 2, 3}, {2, 3, 4}}, types.Shape{2, 2, 4}},
+	{"ijk,jl->lik", []types.Shape{{2, 3, 4}, {3, 2}}, types.Shape{2, 2, 4}},
+	{"i,j->ij", []types.Shape{{3}, {2}}, types.Shape{3, 2}},
+	{"ij,ij->", []types.Shape{{2, 3}, {2, 3}}, scalarShape},
+	{"ij,ij->ji", []types.Shape{{2, 3}, {2, 3}}, types.Shape{3, 2}},
+	{"ij,k->k", []types.Shape{{2, 3}, {4}}, types.Shape{4}},
+	{"ij,jk", []types.Shape{{2, 3}, {3, 4}}, types.Shape{2, 4}},
+	{"ij->ji", []types.Shape{{2, 3}}, types.Shape{3, 2}},
+	{"ijk->j", []types.Shape{{2, 3, 4}}, types.Shape{3}},
+	{"ij->j", []types.Shape{{2, 3}}, types.Shape{3}},
+	{"ij->i", []types.Shape{{2, 3}}, types.Shape{2}},
+	{"ij,jk->k", []types.Shape{{2, 3}, {3, 4}}, types.Shape{4}},
+	{"ij,jk,kl->il", []types.Shape{{2, 3}, {3, 4}, {4, 2}}, types.Shape{2, 2}},
+	{"ij,jk,ki->", []types.Shape{{2, 3}, {3, 4}, {4, 2}}, scalarShape},
+}
+
+// naiveEinsum loops over every combination of the letters
+func naiveEinsum(subscripts string, shapes []types.Shape, data [][]float64) (retVal []float64) {
+	ins, out, err := parseEinsum(subscripts, len(shapes))
+	if err != nil {
+		panic(err)
+	}
+	sizes, err := einsumSizes(ins, shapes)
+	if err != nil {
+		panic(err)
+	}
+
+	letters := einsumSubscripts(strings.Join(ins, ""), strings.Join(ins, ""))
+	retVal = make([]float64, einsumSize(out, sizes))
+	idx := make(map[rune]int)
+	var loop func(int)
+	loop = func(l int) {
+		if l == len(letters) {
+			prod := 1.0
+			for i, in := range ins {
+				prod *= data[i][einsumOffset(in, shapes[i], idx)]
+			}
+			retVal[einsumOffset(out, einsumShape(out, sizes), idx)] += prod
+			return
+		}
+		r := rune(letters[l])
+		for i := 0; i < sizes[r]; i++ {
+			idx[r] = i
+			loop(l + 1)
+		}
+	}
+	loop(0)
+	return
+}
+
+func einsumOffset(sub string, shape types.Shape, idx map[rune]int) (retVal int) {
+	for i, r := range sub {
+		retVal = retVal*shape[i] + idx[r]
+	}
+	return
+}
+
+func einsumBacking(i int, shape types.Shape) []float64 {
+	retVal := make([]float64, shape.TotalSize())
+	for j := range retVal {
+		retVal[j] = math.Sin(float64(3*j+i+1)) * 2
+	}
+	return retVal
+}
+
+func TestEinsum(t *testing.T) {
+	assert := assert.New(t)
+	for _, et := range einsumTests {
+		data := make([][]float64, len(et.shapes))
+		for i, s := range et.shapes {
+			data[i] = einsumBacking(i, s)
+		}
+		correct := naiveEinsum(et.subscripts, et.shapes, data)
+
+		// the cost is Σz², so the gradients are those of the einsum, with 2z as the gradient of z
+		correctGrads := make([][]float64, len(data))
+		for i := range data {
+			correctGrads[i] = make([]float64, len(data[i]))
+			for j := range data[i] {
+				orig := data[i][j]
+				data[i][j] = orig + 1e-6
+				plus := naiveEinsum(et.subscripts, et.shapes, data)
+				data[i][j] = orig - 1e-6
+				minus := naiveEinsum(et.subscripts, et.shapes, data)
+				data[i][j] = orig
+				for k := range plus {
+					correctGrads[i][j] += (plus[k]*plus[k] - minus[k]*minus[k]) / 2e-6
+				}
+			}
+		}
+
+		build := func() (g *ExprGraph, z *Node, operands Nodes) {
+			g = NewGraph()
+			for i, s := range et.shapes {
+				T := tf64.NewTensor(tf64.WithShape(s...), tf64.WithBacking(append([]float64{}, data[i]...)))
+				operands = append(operands, NewTensor(g, Float64, len(s), WithName(string(rune('a'+i))), WithShape(s...), WithValue(T)))
+			}
+			z = Must(Einsum(et.subscripts, operands...))
+			Must(Sum(Must(Square(z))))
+			return
+		}
+
+		checkGrads := func(machine string, operands Nodes) {
+			for i, n := range operands {
+				grad, err := n.Grad()
+				if err != nil {
+					t.Errorf("%v: %s: %v", et.subscripts, machine, err)
+					continue
+				}
+				for j, v := range extractF64s(grad) {
+					assert.InDelta(correctGrads[i][j], v, 1e-5, et.subscripts+": "+machine)
+				}
+			}
+		}
+
+		// symbolic differentiation
+		g, z, operands := build()
+		if _, err := Grad(g.Roots()[0], operands...); err != nil {
+			t.Errorf("%v: %v", et.subscripts, err)
+			continue
+		}
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Errorf("%v: %v", et.subscripts, err)
+			continue
+		}
+		if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+			t.Errorf("%v: %v", et.subscripts, err)
+			continue
+		}
+		// Eq holds for (1, 3) and (3), so the number of dimensions is checked too
+		if !et.correct.Eq(z.Shape()) || len(et.correct) != len(z.Shape()) {
+			t.Errorf("%v: expected shape %v. Got %v", et.subscripts, et.correct, z.Shape())
+		}
+		checkGrads("TapeMachine", operands)
+
+		// automatic differentiation
+		g, z, operands = build()
+		if err = NewLispMachine(g).RunAll(); err != nil {
+			t.Errorf("%v: %v", et.subscripts, err)
+			continue
+		}
+		var zs []float64
+		if z.IsScalar() {
+			zs = []float64{extractF64(z.Value())}
+		} else {
+			zs = extractF64s(z.Value())
+		}
+		for i, v := range zs {
+			assert.InDelta(correct[i], v, 1e-12, et.subscripts)
+		}
+		checkGrads("LispMachine", operands)
+	}
+
+	/* Idiots */
+
+	g := NewGraph()
+	a := NewMatrix(g, Float64, WithShape(2, 3))
+	b := NewMatrix(g, Float64, WithShape(3, 4))
+	bad := []struct {
+		subscripts string
+		operands   Nodes
+	}{
+		{"ij,jk->ik", Nodes{a}},       // too few operands
+		{"ij,ik->jk", Nodes{a, b}},    // the sizes of i disagree
+		{"ij,jk->iz", Nodes{a, b}},    // z is not in the inputs
+		{"ii,jk->ik", Nodes{a, b}},    // repeated subscript
+		{"i1,jk->ik", Nodes{a, b}},    // not a letter
+		{"ijk,jk->ik", Nodes{a, b}},   // too many subscripts
+		{"ij,jk->ii", Nodes{a, b}},    // repeated output subscript
+		{"ij,jk->ik->i", Nodes{a, b}}, // too many arrows
+	}
+	for _, bt := range bad {
+		if _, err := Einsum(bt.subscripts, bt.operands...); err == nil {
+			t.Errorf("Expected an error for %q", bt.subscripts)
+		}
+	}
+}
@@ -0,0 +1,772 @@
+package gorgonia
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+
+	tf32 "github.com/chewxy/gorgonia/tensor/f32"
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/gonum/matrix/mat64"
+	"github.com/pkg/errors"
+)
+
+/*
+This file holds the dense linear algebra ops that are not simple multiplications: inverses, determinants,
+decompositions and solves.
+
+The work is done by gonum's mat64, through the bridge in tensor/f64 (FromMat64 and ToMat64).
+Float32 values are converted to Float64 and back.
+*/
+
+type denseLinAlgOperator byte
+
+const (
+	matInverseOperator denseLinAlgOperator = iota // A⁻¹
+	logDetOperator                                // log|det(A)|
+	choleskyOperator                              // L, where A = LLᵀ
+	triSolveOperator                              // X, where AX = B and A is triangular
+	solveOperator                                 // X, where AX = B
+
+	maxDenseLinAlgOperator // delimits the end of all possible denseLinAlgOperator
+)
+
+var denseLinAlgOperatorStrs = [maxDenseLinAlgOperator]string{
+	"MatInverse",
+	"LogDet",
+	"Cholesky",
+	"TriangularSolve",
+	"Solve",
+}
+
+func (op denseLinAlgOperator) String() string { return denseLinAlgOperatorStrs[op] }
+
+// denseLinAlgOp represents the dense linear algebra operations. The first input is always a square matrix.
+type denseLinAlgOp struct {
+	denseLinAlgOperator
+
+	lower bool // for triSolveOperator: is the matrix lower triangular?
+	d     int  // for the solves: the dimensions of the right hand side (a vector or a matrix)
+}
+
+// denseLinAlgOp has these types:
+//		MatInverse, Cholesky :: (Floats a) ⇒ Tensor 2 a → Tensor 2 a
+//		LogDet :: (Floats a) ⇒ Tensor 2 a → a
+//		TriangularSolve, Solve :: (Floats a) ⇒ Tensor 2 a → Tensor d a → Tensor d a
+func (op denseLinAlgOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	m := newTensorType(2, a)
+
+	switch op.denseLinAlgOperator {
+	case logDetOperator:
+		return newFunctionType(m, a)
+	case triSolveOperator, solveOperator:
+		b := newTensorType(op.d, a)
+		return newFunctionType(m, b, b)
+	}
+	return newFunctionType(m, m)
+}
+
+func (op denseLinAlgOp) inferShape(retType Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "%v expects %d inputs. Got %d instead", op, op.arity(), len(inputs))
+		return
+	}
+
+	var n int
+	if n, err = squareSize(op, inputs[0].shape); err != nil {
+		return
+	}
+
+	switch op.denseLinAlgOperator {
+	case logDetOperator:
+		return scalarShape, nil
+	case triSolveOperator, solveOperator:
+		b := inputs[1].shape
+		if len(b) != op.d || b[0] != n {
+			err = NewError(ShapeError, "%v cannot solve a %v matrix with a right hand side of shape %v", op, inputs[0].shape, b)
+			return
+		}
+		return b.Clone(), nil
+	}
+	return inputs[0].shape.Clone(), nil
+}
+
+func (op denseLinAlgOp) DiffWRT(inputs int) []bool {
+	if op.arity() == 2 {
+		return []bool{true, true}
+	}
+	return []bool{true}
+}
+
+func (op denseLinAlgOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "%v expects %d inputs. Got %d instead", op, op.arity(), len(inputs))
+		return
+	}
+
+	switch op.denseLinAlgOperator {
+	case matInverseOperator:
+		retVal, err = matInverseDiffExpr(inputs[0], output, gradNode)
+	case logDetOperator:
+		retVal, err = logDetDiffExpr(inputs[0], output, gradNode)
+	case choleskyOperator:
+		retVal, err = choleskyDiffExpr(inputs[0], output, gradNode)
+	case triSolveOperator, solveOperator:
+		retVal, err = op.solveDiffExpr(inputs[0], inputs[1], output, gradNode)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "Unable to differentiate %v", op)
+		return
+	}
+
+	for _, n := range retVal {
+		WithGroupName(gradClust)(n)
+	}
+	return
+}
+
+func (op denseLinAlgOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "%v expects %d inputs. Got %d instead", op, op.arity(), len(inputs))
+		return
+	}
+
+	switch op.denseLinAlgOperator {
+	case matInverseOperator:
+		retVal, err = matInverseFwdDiffExpr(inputs[0], output, tangents[0])
+	case logDetOperator:
+		retVal, err = logDetFwdDiffExpr(inputs[0], output, tangents[0])
+	case choleskyOperator:
+		retVal, err = choleskyFwdDiffExpr(inputs[0], output, tangents[0])
+	case triSolveOperator, solveOperator:
+		retVal, err = op.solveFwdDiffExpr(inputs[0], output, tangents[0], tangents[1])
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "Unable to differentiate %v", op)
+	}
+	return
+}
+
+func (op denseLinAlgOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "%v expects %d inputs. Got %d instead", op, op.arity(), len(inputs))
+		return
+	}
+
+	ydv := output.boundTo.(*dualValue)
+	var a, y *mat64.Dense
+	if a, err = toMat64(op, inputs[0].Value()); err != nil {
+		return
+	}
+	if op.denseLinAlgOperator != logDetOperator {
+		if y, err = toMat64(op, ydv.Value); err != nil {
+			return
+		}
+	}
+
+	var grads []*mat64.Dense
+	switch op.denseLinAlgOperator {
+	case matInverseOperator:
+		var g *mat64.Dense
+		if g, err = toMat64(op, ydv.d); err != nil {
+			return
+		}
+		grads = []*mat64.Dense{matInverseDiff(y, g)}
+	case logDetOperator:
+		var g float64
+		if g, err = scalarFloat(op, ydv.d); err != nil {
+			return
+		}
+		var da *mat64.Dense
+		if da, err = logDetDiff(a, g); err != nil {
+			return
+		}
+		grads = []*mat64.Dense{da}
+	case choleskyOperator:
+		var g, da *mat64.Dense
+		if g, err = toMat64(op, ydv.d); err != nil {
+			return
+		}
+		if da, err = choleskyDiff(y, g); err != nil {
+			return
+		}
+		grads = []*mat64.Dense{da}
+	case triSolveOperator, solveOperator:
+		var g *mat64.Dense
+		if g, err = toMat64(op, ydv.d); err != nil {
+			return
+		}
+		var da, db *mat64.Dense
+		if da, db, err = op.solveDiff(a, y, g); err != nil {
+			return
+		}
+		grads = []*mat64.Dense{da, db}
+	}
+
+	for i, g := range grads {
+		xdv := inputs[i].boundTo.(*dualValue)
+
+		var d Value
+		if d, err = fromMat64(g, xdv.d); err != nil {
+			return
+		}
+		add := newEBOByType(addOpType, xdv.d.Type(), d.Type())
+		if _, err = add.UnsafeDo(xdv.d, d); err != nil {
+			err = errors.Wrapf(err, unsafeDoFail, add)
+			return
+		}
+	}
+	return
+}
+
+func (op denseLinAlgOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "%v expects %d inputs. Got %d instead", op, op.arity(), len(inputs))
+		return
+	}
+
+	var a *mat64.Dense
+	if a, err = toMat64(op, inputs[0]); err != nil {
+		return
+	}
+
+	var y *mat64.Dense
+	switch op.denseLinAlgOperator {
+	case matInverseOperator:
+		y = new(mat64.Dense)
+		if err = y.Inverse(a); err != nil {
+			err = errors.Wrapf(err, "Unable to invert %v", inputs[0])
+			return
+		}
+	case logDetOperator:
+		// the log determinant of a singular matrix is -Inf. Its sign is ±1, so it can't be used to tell
+		det, _ := mat64.LogDet(a)
+		if math.IsInf(det, -1) {
+			err = NewError(RuntimeError, "LogDet: %v is singular", inputs[0])
+			return
+		}
+		if inputs[0].Dtype() == Float32 {
+			return anyToValue(float32(det))
+		}
+		return anyToValue(det)
+	case choleskyOperator:
+		if y, err = cholesky(a); err != nil {
+			return
+		}
+	case triSolveOperator, solveOperator:
+		var b *mat64.Dense
+		if b, err = toMat64(op, inputs[1]); err != nil {
+			return
+		}
+		if op.denseLinAlgOperator == triSolveOperator {
+			a = triangle(a, op.lower, 1)
+		}
+		y = new(mat64.Dense)
+		if err = y.Solve(a, b); err != nil {
+			err = errors.Wrapf(err, "Unable to solve %v", op)
+			return
+		}
+		return fromMat64(y, inputs[1])
+	}
+	return fromMat64(y, inputs[0])
+}
+
+func (op denseLinAlgOp) returnsPtr() bool    { return false }
+func (op denseLinAlgOp) callsExtern() bool   { return false }
+func (op denseLinAlgOp) overwriteInput() int { return -1 }
+
+func (op denseLinAlgOp) WriteHash(h hash.Hash) {
+	fmt.Fprintf(h, "%v%t%d", op.denseLinAlgOperator, op.lower, op.d)
+}
+
+func (op denseLinAlgOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op denseLinAlgOp) String() string {
+	if op.denseLinAlgOperator == triSolveOperator {
+		if op.lower {
+			return "TriangularSolve(lower)"
+		}
+		return "TriangularSolve(upper)"
+	}
+	return op.denseLinAlgOperator.String()
+}
+
+func (op denseLinAlgOp) arity() int {
+	switch op.denseLinAlgOperator {
+	case triSolveOperator, solveOperator:
+		return 2
+	}
+	return 1
+}
+
+/* SYMBOLIC DIFFERENTIATION */
+
+// matInverseDiffExpr: if Y = A⁻¹, then dA = -Yᵀ dY Yᵀ
+func matInverseDiffExpr(a, y, grad *Node) (retVal Nodes, err error) {
+	var yTg, da *Node
+	if yTg, err = binOpNode(linAlgBinOp{āBinaryOperator: matMulOperator, transA: true}, y, grad); err != nil {
+		return
+	}
+	if da, err = binOpNode(linAlgBinOp{āBinaryOperator: matMulOperator, transB: true}, yTg, y); err != nil {
+		return
+	}
+	if da, err = Neg(da); err != nil {
+		return
+	}
+	return Nodes{da}, nil
+}
+
+// logDetDiffExpr: if y = log|det(A)|, then dA = A⁻ᵀ dy
+func logDetDiffExpr(a, y, grad *Node) (retVal Nodes, err error) {
+	var inv, da *Node
+	if inv, err = MatInverse(a); err != nil {
+		return
+	}
+	if inv, err = Transpose(inv); err != nil {
+		return
+	}
+	if da, err = HadamardProd(inv, grad); err != nil {
+		return
+	}
+	return Nodes{da}, nil
+}
+
+// choleskyDiffExpr: if L is the Cholesky factor of A, then dA is the symmetric part of L⁻ᵀ Φ(Lᵀ dL) L⁻¹,
+// where Φ takes the lower triangle and halves the diagonal.
+func choleskyDiffExpr(a, l, grad *Node) (retVal Nodes, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(l.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, l.t)
+		return
+	}
+
+	var phi, half *Node
+	if phi, err = triangleConstant(dt, l.shape[0], true, 0.5); err != nil {
+		return
+	}
+	if half, err = floatConstant(dt, 0.5); err != nil {
+		return
+	}
+
+	var lT, p, z, sT, s, da *Node
+	if lT, err = Transpose(l); err != nil {
+		return
+	}
+	if p, err = binOpNode(linAlgBinOp{āBinaryOperator: matMulOperator, transA: true}, l, grad); err != nil {
+		return
+	}
+	if p, err = HadamardProd(p, phi); err != nil {
+		return
+	}
+
+	// z = L⁻ᵀ P, and sᵀ = L⁻ᵀ zᵀ = (L⁻ᵀ P L⁻¹)ᵀ
+	if z, err = TriangularSolve(lT, p, false); err != nil {
+		return
+	}
+	if z, err = Transpose(z); err != nil {
+		return
+	}
+	if sT, err = TriangularSolve(lT, z, false); err != nil {
+		return
+	}
+	if s, err = Transpose(sT); err != nil {
+		return
+	}
+	if da, err = Add(s, sT); err != nil {
+		return
+	}
+	if da, err = HadamardProd(da, half); err != nil {
+		return
+	}
+	return Nodes{da}, nil
+}
+
+// solveDiffExpr: if AX = B, then dB = A⁻ᵀ dX, and dA = -dB Xᵀ (restricted to the triangle of A for triangular solves)
+func (op denseLinAlgOp) solveDiffExpr(a, b, x, grad *Node) (retVal Nodes, err error) {
+	var aT, da, db *Node
+	if aT, err = Transpose(a); err != nil {
+		return
+	}
+
+	// the gradient is a column vector when it comes from a matrix-vector product
+	if op.d == 1 && len(grad.shape) != 1 {
+		if grad, err = Reshape(grad, x.shape...); err != nil {
+			return
+		}
+	}
+
+	if op.denseLinAlgOperator == triSolveOperator {
+		db, err = TriangularSolve(aT, grad, !op.lower)
+	} else {
+		db, err = Solve(aT, grad)
+	}
+	if err != nil {
+		return
+	}
+
+	if op.d == 1 {
+		da, err = OuterProd(db, x)
+	} else {
+		da, err = binOpNode(linAlgBinOp{āBinaryOperator: matMulOperator, transB: true}, db, x)
+	}
+	if err != nil {
+		return
+	}
+	if da, err = Neg(da); err != nil {
+		return
+	}
+
+	if op.denseLinAlgOperator == triSolveOperator {
+		var dt Dtype
+		if dt, err = dtypeOf(a.t); err != nil {
+			err = errors.Wrapf(err, dtypeExtractionFail, a.t)
+			return
+		}
+
+		var mask *Node
+		if mask, err = triangleConstant(dt, a.shape[0], op.lower, 1); err != nil {
+			return
+		}
+		if da, err = HadamardProd(da, mask); err != nil {
+			return
+		}
+	}
+	return Nodes{da, db}, nil
+}
+
+// matInverseFwdDiffExpr: if Y = A⁻¹, then dY = -Y dA Y
+func matInverseFwdDiffExpr(a, y, da *Node) (retVal *Node, err error) {
+	if retVal, err = Mul(y, da); err != nil {
+		return
+	}
+	if retVal, err = Mul(retVal, y); err != nil {
+		return
+	}
+	return Neg(retVal)
+}
+
+// logDetFwdDiffExpr: if y = log|det(A)|, then dy = tr(A⁻¹ dA) = Σ A⁻ᵀ ⊙ dA
+func logDetFwdDiffExpr(a, y, da *Node) (retVal *Node, err error) {
+	var inv *Node
+	if inv, err = MatInverse(a); err != nil {
+		return
+	}
+	if inv, err = Transpose(inv); err != nil {
+		return
+	}
+	if retVal, err = HadamardProd(inv, da); err != nil {
+		return
+	}
+	return Sum(retVal)
+}
+
+// choleskyFwdDiffExpr: if L is the Cholesky factor of A, then dL = L Φ(L⁻¹ dA L⁻ᵀ), where Φ takes the lower triangle and halves the diagonal.
+// Only the symmetric part of dA moves A, just as only the symmetric part of the gradient is taken by choleskyDiffExpr.
+func choleskyFwdDiffExpr(a, l, da *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(l.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, l.t)
+		return
+	}
+
+	var phi, half *Node
+	if phi, err = triangleConstant(dt, l.shape[0], true, 0.5); err != nil {
+		return
+	}
+	if half, err = floatConstant(dt, 0.5); err != nil {
+		return
+	}
+
+	var daT, s, z *Node
+	if daT, err = Transpose(da); err != nil {
+		return
+	}
+	if s, err = Add(da, daT); err != nil {
+		return
+	}
+	if s, err = HadamardProd(s, half); err != nil {
+		return
+	}
+
+	// z = L⁻¹ S, and L⁻¹ zᵀ = L⁻¹ S L⁻ᵀ as S is symmetric
+	if z, err = TriangularSolve(l, s, true); err != nil {
+		return
+	}
+	if z, err = Transpose(z); err != nil {
+		return
+	}
+	if z, err = TriangularSolve(l, z, true); err != nil {
+		return
+	}
+	if z, err = HadamardProd(z, phi); err != nil {
+		return
+	}
+	return Mul(l, z)
+}
+
+// solveFwdDiffExpr: if AX = B, then dX = A⁻¹(dB - dA X) (with dA restricted to the triangle of A for triangular solves)
+func (op denseLinAlgOp) solveFwdDiffExpr(a, x, da, db *Node) (retVal *Node, err error) {
+	var rhs *Node
+	if da != nil {
+		if op.denseLinAlgOperator == triSolveOperator {
+			var dt Dtype
+			if dt, err = dtypeOf(a.t); err != nil {
+				err = errors.Wrapf(err, dtypeExtractionFail, a.t)
+				return
+			}
+
+			var mask *Node
+			if mask, err = triangleConstant(dt, a.shape[0], op.lower, 1); err != nil {
+				return
+			}
+			if da, err = HadamardProd(da, mask); err != nil {
+				return
+			}
+		}
+		if rhs, err = Mul(da, x); err != nil {
+			return
+		}
+		// matrix-vector products are column vectors
+		if op.d == 1 {
+			if rhs, err = Reshape(rhs, x.shape...); err != nil {
+				return
+			}
+		}
+		if rhs, err = Neg(rhs); err != nil {
+			return
+		}
+	}
+	if rhs, err = addTangents(rhs, db); err != nil {
+		return
+	}
+
+	if op.denseLinAlgOperator == triSolveOperator {
+		return TriangularSolve(a, rhs, op.lower)
+	}
+	return Solve(a, rhs)
+}
+
+/* AUTOMATIC DIFFERENTIATION */
+
+func matInverseDiff(y, g *mat64.Dense) *mat64.Dense {
+	var yTg, retVal mat64.Dense
+	yTg.Mul(y.T(), g)
+	retVal.Mul(&yTg, y.T())
+	return scaleMat64(&retVal, -1)
+}
+
+func logDetDiff(a *mat64.Dense, g float64) (retVal *mat64.Dense, err error) {
+	var inv mat64.Dense
+	if err = inv.Inverse(a); err != nil {
+		err = errors.Wrap(err, "Unable to invert the matrix to differentiate LogDet")
+		return
+	}
+	return scaleMat64(denseOf(inv.T()), g), nil
+}
+
+func choleskyDiff(l, g *mat64.Dense) (retVal *mat64.Dense, err error) {
+	var p, lInv, z, s mat64.Dense
+	p.Mul(l.T(), g)
+	phi := triangle(&p, true, 0.5)
+	if err = lInv.Inverse(l); err != nil {
+		err = errors.Wrap(err, "Unable to invert the Cholesky factor to differentiate Cholesky")
+		return
+	}
+	z.Mul(lInv.T(), phi)
+	s.Mul(&z, &lInv)
+
+	n, _ := s.Dims()
+	retVal = mat64.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			retVal.Set(i, j, (s.At(i, j)+s.At(j, i))/2)
+		}
+	}
+	return
+}
+
+func (op denseLinAlgOp) solveDiff(a, x, g *mat64.Dense) (da, db *mat64.Dense, err error) {
+	if op.denseLinAlgOperator == triSolveOperator {
+		a = triangle(a, op.lower, 1)
+	}
+
+	db = new(mat64.Dense)
+	if err = db.Solve(a.T(), g); err != nil {
+		err = errors.Wrapf(err, "Unable to solve the transposed system to differentiate %v", op)
+		return
+	}
+
+	da = new(mat64.Dense)
+	da.Mul(db, x.T())
+	da = scaleMat64(da, -1)
+	if op.denseLinAlgOperator == triSolveOperator {
+		da = triangle(da, op.lower, 1)
+	}
+	return
+}
+
+/* UTILITY FUNCTIONS */
+
+func squareSize(op Op, s types.Shape) (n int, err error) {
+	if len(s) != 2 || s[0] != s[1] {
+		err = NewError(ShapeError, "%v expects a square matrix. Got a shape of %v instead", op, s)
+		return
+	}
+	return s[0], nil
+}
+
+// cholesky computes the lower triangular Cholesky factor of a. Only the lower triangle of a is used.
+func cholesky(a *mat64.Dense) (retVal *mat64.Dense, err error) {
+	n, _ := a.Dims()
+	sym := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			sym[i*n+j] = a.At(j, i)
+			sym[j*n+i] = a.At(j, i)
+		}
+	}
+
+	var chol mat64.Cholesky
+	if ok := chol.Factorize(mat64.NewSymDense(n, sym)); !ok {
+		err = NewError(RuntimeError, "Cholesky: the matrix is not positive definite")
+		return
+	}
+
+	var l mat64.TriDense
+	l.LFromCholesky(&chol)
+	return denseOf(&l), nil
+}
+
+// triangle returns a copy of the lower (or upper) triangle of m, with the diagonal scaled by diag.
+func triangle(m *mat64.Dense, lower bool, diag float64) *mat64.Dense {
+	r, c := m.Dims()
+	retVal := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			switch {
+			case i == j:
+				retVal.Set(i, j, m.At(i, j)*diag)
+			case i > j == lower:
+				retVal.Set(i, j, m.At(i, j))
+			}
+		}
+	}
+	return retVal
+}
+
+// triangleConstant creates a n×n constant that is 1 in the lower (or upper) triangle and diag on the diagonal.
+func triangleConstant(dt Dtype, n int, lower bool, diag float64) (retVal *Node, err error) {
+	ones := mat64.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			ones.Set(i, j, 1)
+		}
+	}
+
+	var v Value
+	switch dt {
+	case Float64:
+		v = FromTensor(tf64.FromMat64(triangle(ones, lower, diag), false))
+	case Float32:
+		v = FromTensor(tf32.NewTensor(tf32.WithShape(n, n), tf32.WithBacking(f64sTof32s(triangle(ones, lower, diag).RawMatrix().Data))))
+	default:
+		err = nyi("triangleConstant", dt)
+		return
+	}
+	return NewConstant(v), nil
+}
+
+func denseOf(m mat64.Matrix) *mat64.Dense {
+	r, c := m.Dims()
+	retVal := mat64.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			retVal.Set(i, j, m.At(i, j))
+		}
+	}
+	return retVal
+}
+
+func scaleMat64(m *mat64.Dense, s float64) *mat64.Dense {
+	r, c := m.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			m.Set(i, j, m.At(i, j)*s)
+		}
+	}
+	return m
+}
+
+// toMat64 copies a Float64 or Float32 matrix or vector into a *mat64.Dense. Vectors become column vectors.
+func toMat64(op Op, v Value) (retVal *mat64.Dense, err error) {
+	var t types.Tensor
+	if t, err = tensorOf(op, v); err != nil {
+		return
+	}
+
+	var data []float64
+	switch d := t.Data().(type) {
+	case []float64:
+		data = make([]float64, len(d))
+		copy(data, d)
+	case []float32:
+		data = f32sTof64s(d)
+	default:
+		err = nyi("toMat64", t.Dtype())
+		return
+	}
+
+	s := t.Shape()
+	switch len(s) {
+	case 1:
+		s = types.Shape{s[0], 1}
+	case 2:
+	default:
+		err = NewError(ShapeError, "%v expects matrices or vectors. Got a shape of %v instead", op, s)
+		return
+	}
+	return tf64.ToMat64(tf64.NewTensor(tf64.WithShape(s...), tf64.WithBacking(data)), false)
+}
+
+// fromMat64 converts m into a Value with the same Dtype and shape as like
+func fromMat64(m *mat64.Dense, like Value) (retVal Value, err error) {
+	t := tf64.FromMat64(denseOf(m), false)
+	shape := like.Shape()
+	if err = t.Reshape(shape...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, shape, t.DataSize())
+		return
+	}
+
+	switch like.Dtype() {
+	case Float64:
+		retVal = FromTensor(t)
+	case Float32:
+		retVal = FromTensor(tf32.NewTensor(tf32.WithShape(shape...), tf32.WithBacking(f64sTof32s(t.Data().([]float64)))))
+	default:
+		err = nyi("fromMat64", like.Dtype())
+	}
+	return
+}
+
+func f32sTof64s(a []float32) []float64 {
+	retVal := make([]float64, len(a))
+	for i, v := range a {
+		retVal[i] = float64(v)
+	}
+	return retVal
+}
+
+func f64sTof32s(a []float64) []float32 {
+	retVal := make([]float32, len(a))
+	for i, v := range a {
+		retVal[i] = float32(v)
+	}
+	return retVal
+}
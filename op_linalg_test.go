@@ -0,0 +1,233 @@
+package gorgonia
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	tf32 "github.com/chewxy/gorgonia/tensor/f32"
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var denseLinAlgTests = []struct {
+	name    string
+	fn      func(Nodes) (*Node, error)
+	inputs  [][]float64
+	shapes  []types.Shape
+	correct []float64
+
+	symmetric bool // the first input is symmetric, so the entries are perturbed in pairs
+}{
+	{"MatInverse", func(ns Nodes) (*Node, error) { return MatInverse(ns[0]) },
+		[][]float64{{4, 7, 2, 6}}, []types.Shape{{2, 2}}, []float64{0.6, -0.7, -0.2, 0.4}, false},
+	{"LogDet", func(ns Nodes) (*Node, error) { return LogDet(ns[0]) },
+		[][]float64{{4, 7, 2, 6}}, []types.Shape{{2, 2}}, []float64{math.Log(10)}, false},
+	{"LogDet (negative determinant)", func(ns Nodes) (*Node, error) { return LogDet(ns[0]) },
+		[][]float64{{2, 7, 4, 6}}, []types.Shape{{2, 2}}, []float64{math.Log(16)}, false},
+	{"Cholesky", func(ns Nodes) (*Node, error) { return Cholesky(ns[0]) },
+		[][]float64{{4, 2, 0.4, 2, 3, 0.5, 0.4, 0.5, 2}}, []types.Shape{{3, 3}}, []float64{2, 0, 0, 1, math.Sqrt2, 0, 0.2, 0.5/math.Sqrt2 - 0.2/math.Sqrt2, math.Sqrt(2 - 0.04 - math.Pow(0.3/math.Sqrt2, 2))}, true},
+	{"TriangularSolve (lower, vector)", func(ns Nodes) (*Node, error) { return TriangularSolve(ns[0], ns[1], true) },
+		[][]float64{{2, 100, 1, 4}, {2, 9}}, []types.Shape{{2, 2}, {2}}, []float64{1, 2}, false},
+	{"TriangularSolve (upper, matrix)", func(ns Nodes) (*Node, error) { return TriangularSolve(ns[0], ns[1], false) },
+		[][]float64{{2, 1, 100, 4}, {4, 2, 8, 4}}, []types.Shape{{2, 2}, {2, 2}}, []float64{1, 0.5, 2, 1}, false},
+	{"Solve (vector)", func(ns Nodes) (*Node, error) { return Solve(ns[0], ns[1]) },
+		[][]float64{{3, 1, 1, 2}, {9, 8}}, []types.Shape{{2, 2}, {2}}, []float64{2, 3}, false},
+	{"Solve (matrix)", func(ns Nodes) (*Node, error) { return Solve(ns[0], ns[1]) },
+		[][]float64{{3, 1, 0, 1, 2, 1, 0, 1, 4}, {4, 1, 4, 2, 5, 1}}, []types.Shape{{3, 3}, {3, 2}}, []float64{1, 0, 1, 1, 1, 0}, false},
+}
+
+// linAlgCost is Σ wᵢyᵢ, with weights that differ for each element
+func linAlgCost(y []float64) (retVal float64) {
+	for i, v := range y {
+		retVal += float64(i+1) * v
+	}
+	return
+}
+
+func TestDenseLinAlg(t *testing.T) {
+	assert := assert.New(t)
+	for _, lat := range denseLinAlgTests {
+		build := func() (g *ExprGraph, y *Node, inputs Nodes) {
+			g = NewGraph()
+			for i, data := range lat.inputs {
+				T := tf64.NewTensor(tf64.WithShape(lat.shapes[i]...), tf64.WithBacking(append([]float64{}, data...)))
+				inputs = append(inputs, NewTensor(g, Float64, len(lat.shapes[i]), WithName(fmt.Sprintf("x%d", i)), WithShape(lat.shapes[i]...), WithValue(T)))
+			}
+			y = Must(lat.fn(inputs))
+
+			if y.IsScalar() {
+				Must(Add(y, NewConstant(0.0)))
+				return
+			}
+			w := make([]float64, y.Shape().TotalSize())
+			for i := range w {
+				w[i] = float64(i + 1)
+			}
+			W := NewConstant(tf64.NewTensor(tf64.WithShape(y.Shape()...), tf64.WithBacking(w)))
+			Must(Sum(Must(HadamardProd(y, W))))
+			return
+		}
+
+		// forward pass, and the finite differences of the cost
+		forward := func(inputs [][]float64) []float64 {
+			_, y, _ := build()
+			vals := make([]Value, len(inputs))
+			for i, data := range inputs {
+				vals[i] = FromTensor(tf64.NewTensor(tf64.WithShape(lat.shapes[i]...), tf64.WithBacking(append([]float64{}, data...))))
+			}
+			v, err := y.op.Do(vals...)
+			if err != nil {
+				t.Fatalf("%s: %v", lat.name, err)
+			}
+			if y.IsScalar() {
+				return []float64{extractF64(v)}
+			}
+			return extractF64s(v)
+		}
+
+		for i, v := range forward(lat.inputs) {
+			assert.InDelta(lat.correct[i], v, 1e-12, lat.name)
+		}
+
+		correctGrads := make([][]float64, len(lat.inputs))
+		for i, data := range lat.inputs {
+			correctGrads[i] = make([]float64, len(data))
+			for j := range data {
+				// the transposed entry of a symmetric input is perturbed too
+				k := j
+				if i == 0 && lat.symmetric {
+					n := lat.shapes[0][0]
+					k = (j%n)*n + j/n
+				}
+				orig, origK := data[j], data[k]
+				data[j], data[k] = orig+1e-6, origK+1e-6
+				plus := linAlgCost(forward(lat.inputs))
+				data[j], data[k] = orig-1e-6, origK-1e-6
+				minus := linAlgCost(forward(lat.inputs))
+				data[j], data[k] = orig, origK
+				correctGrads[i][j] = (plus - minus) / 2e-6
+			}
+		}
+
+		checkGrads := func(machine string, inputs Nodes) {
+			for i, n := range inputs {
+				grad, err := n.Grad()
+				if err != nil {
+					t.Errorf("%s: %s: %v", lat.name, machine, err)
+					continue
+				}
+				gs := extractF64s(grad)
+				for j, v := range gs {
+					// the gradient of a symmetric input is symmetric, so the off diagonal gradients are halves of the finite differences
+					if i == 0 && lat.symmetric {
+						n := lat.shapes[0][0]
+						if k := (j%n)*n + j/n; k != j {
+							v += gs[k]
+						}
+					}
+					assert.InDelta(correctGrads[i][j], v, 1e-5, lat.name+": "+machine)
+				}
+			}
+		}
+
+		// symbolic differentiation
+		g, _, inputs := build()
+		if _, err := Grad(g.Roots()[0], inputs...); err != nil {
+			t.Errorf("%s: %v", lat.name, err)
+			continue
+		}
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Errorf("%s: %v", lat.name, err)
+			continue
+		}
+		if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+			t.Errorf("%s: %v", lat.name, err)
+			continue
+		}
+		checkGrads("TapeMachine", inputs)
+
+		// automatic differentiation
+		g, _, inputs = build()
+		if err = NewLispMachine(g).RunAll(); err != nil {
+			t.Errorf("%s: %v", lat.name, err)
+			continue
+		}
+		checkGrads("LispMachine", inputs)
+	}
+}
+
+func TestDenseLinAlgFloat32(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	A := tf32.NewTensor(tf32.WithShape(2, 2), tf32.WithBacking([]float32{4, 2, 2, 3}))
+	B := tf32.NewTensor(tf32.WithShape(2), tf32.WithBacking([]float32{8, 7}))
+	a := NewMatrix(g, Float32, WithShape(2, 2), WithName("a"), WithValue(A))
+	b := NewVector(g, Float32, WithShape(2), WithName("b"), WithValue(B))
+	ld := Must(LogDet(a))
+	x := Must(Solve(a, b))
+	Must(Add(ld, Must(Sum(x))))
+
+	if err := NewLispMachine(g).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDelta(math.Log(8), ld.Value().(Scalar).v.(float32), 1e-6)
+	for i, v := range x.Value().(Tensor).Data().([]float32) {
+		assert.InDelta([]float64{1.25, 1.5}[i], v, 1e-6)
+	}
+
+	// d(log|A| + Σx)/dA = A⁻ᵀ - A⁻ᵀ1xᵀ, and d/db = A⁻ᵀ1
+	aG, _ := a.Grad()
+	bG, _ := b.Grad()
+	for i, v := range aG.(Tensor).Data().([]float32) {
+		assert.InDelta([]float64{0.375 - 0.125*1.25, -0.25 - 0.125*1.5, -0.25 - 0.25*1.25, 0.5 - 0.25*1.5}[i], v, 1e-6)
+	}
+	for i, v := range bG.(Tensor).Data().([]float32) {
+		assert.InDelta([]float64{0.125, 0.25}[i], v, 1e-6)
+	}
+
+	l, err := denseLinAlgOp{denseLinAlgOperator: choleskyOperator}.Do(FromTensor(A))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range l.(Tensor).Data().([]float32) {
+		assert.InDelta([]float64{2, 0, 1, math.Sqrt2}[i], v, 1e-6)
+	}
+}
+
+func TestDenseLinAlgErrors(t *testing.T) {
+	g := NewGraph()
+	rect := NewMatrix(g, Float64, WithShape(2, 3))
+	square := NewMatrix(g, Float64, WithShape(2, 2))
+	v3 := NewVector(g, Float64, WithShape(3))
+
+	if _, err := MatInverse(rect); err == nil {
+		t.Error("Expected an error inverting a non square matrix")
+	}
+	if _, err := LogDet(rect); err == nil {
+		t.Error("Expected an error for the determinant of a non square matrix")
+	}
+	if _, err := Cholesky(rect); err == nil {
+		t.Error("Expected an error for the Cholesky decomposition of a non square matrix")
+	}
+	if _, err := Solve(square, v3); err == nil {
+		t.Error("Expected a shape error solving with a right hand side of the wrong size")
+	}
+	if _, err := TriangularSolve(square, NewScalar(g, Float64), true); err == nil {
+		t.Error("Expected an error solving with a scalar right hand side")
+	}
+
+	// runtime failures
+	singular := FromTensor(tf64.NewTensor(tf64.WithShape(2, 2), tf64.WithBacking([]float64{1, 2, 2, 4})))
+	if _, err := (denseLinAlgOp{denseLinAlgOperator: matInverseOperator}).Do(singular); err == nil {
+		t.Error("Expected an error inverting a singular matrix")
+	}
+	if _, err := (denseLinAlgOp{denseLinAlgOperator: logDetOperator}).Do(singular); err == nil {
+		t.Error("Expected an error for the log determinant of a singular matrix")
+	}
+	if _, err := (denseLinAlgOp{denseLinAlgOperator: choleskyOperator}).Do(singular); err == nil {
+		t.Error("Expected an error for the Cholesky decomposition of a matrix that is not positive definite")
+	}
+}
@@ -0,0 +1,514 @@
+package gorgonia
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+
+	"github.com/chewxy/gorgonia/tensor"
+	tb "github.com/chewxy/gorgonia/tensor/b"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/pkg/errors"
+)
+
+/*
+This file holds the logical ops, which operate on Bool scalars and tensors.
+They're typically used to combine masks created with the comparison ops (with retSame set to false).
+*/
+
+type logicalOpType byte
+
+const (
+	andOpType logicalOpType = iota
+	orOpType
+	xorOpType
+	notOpType
+)
+
+var logicalOpStrs = [...]string{"∧", "∨", "⊕", "¬"}
+
+func (o logicalOpType) String() string { return logicalOpStrs[o] }
+
+// logicalOp is a pointwise logical operation. notOpType is the only unary logical op
+type logicalOp struct {
+	logicalOpType
+	args []Type // pruned types only plz
+}
+
+func newLogicalOp(ot logicalOpType, children ...*Node) logicalOp {
+	args := make([]Type, len(children))
+	for i, child := range children {
+		args[i] = prune(child.t)
+	}
+	return logicalOp{
+		logicalOpType: ot,
+		args:          args,
+	}
+}
+
+func (op logicalOp) arity() int {
+	if op.logicalOpType == notOpType {
+		return 1
+	}
+	return 2
+}
+
+// logicalOp has these types:
+//		op :: Bool → Bool → Bool
+//		op :: Tensor Bool → Bool → Tensor Bool
+//		op :: Bool → Tensor Bool → Tensor Bool
+//		op :: Tensor Bool → Tensor Bool → Tensor Bool
+//		not :: Bool → Bool
+//		not :: Tensor Bool → Tensor Bool
+func (op logicalOp) Type() Type {
+	var ret Type = Bool
+	ts := make(Types, 0, len(op.args)+1)
+	for _, arg := range op.args {
+		if tt, ok := arg.(*TensorType); ok {
+			t := newTensorType(tt.d, Bool)
+			ts = append(ts, t)
+			ret = t
+			continue
+		}
+		ts = append(ts, Bool)
+	}
+	ts = append(ts, ret)
+	return newFunctionType(ts...)
+}
+
+func (op logicalOp) inferShape(retType Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "%v expects %d inputs. Got %d instead", op, op.arity(), len(inputs))
+		return
+	}
+
+	retVal = scalarShape
+	for _, in := range inputs {
+		if in.IsScalar() {
+			continue
+		}
+
+		if in.shape == nil {
+			err = NewError(ShapeError, "%v cannot infer the shape: %v has no shape", op, in)
+			return
+		}
+
+		if !retVal.IsScalar() && !retVal.Eq(in.shape) {
+			err = NewError(ShapeError, "Conflicting shapes: %v and %v", retVal, in.shape)
+			return
+		}
+		retVal = in.shape
+	}
+	return
+}
+
+func (op logicalOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op logicalOp) SymDiff(inputs Nodes, output, gradNode *Node) (Nodes, error) {
+	return nil, nondiffErr(op)
+}
+
+func (op logicalOp) Do(inputs ...Value) (retVal Value, err error) {
+	return op.do(inputs)
+}
+
+func (op logicalOp) returnsPtr() bool {
+	for _, arg := range op.args {
+		if _, ok := arg.(*TensorType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (op logicalOp) callsExtern() bool { return false }
+
+func (op logicalOp) overwriteInput() int {
+	for i, arg := range op.args {
+		if _, ok := arg.(*TensorType); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+func (op logicalOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("logical"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.logicalOpType)); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%v", op.args)
+}
+
+func (op logicalOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op logicalOp) String() string { return op.logicalOpType.String() }
+
+// Fulfils UnsafeDoer interface
+func (op logicalOp) UnsafeDo(inputs ...Value) (retVal Value, err error) {
+	return op.do(inputs, types.UseUnsafe())
+}
+
+func (op logicalOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "%v expects %d inputs. Got %d instead", op, op.arity(), len(inputs))
+		return
+	}
+
+	// extract the values
+	vals := make([]interface{}, len(inputs))
+	for i, in := range inputs {
+		switch v := in.(type) {
+		case Scalar:
+			b, ok := v.v.(bool)
+			if !ok {
+				err = NewError(RuntimeError, "%v expects Bool inputs. Got %v instead", op, v.t)
+				return
+			}
+			vals[i] = b
+		case Tensor:
+			t, ok := v.Tensor.(*tb.Tensor)
+			if !ok {
+				err = NewError(RuntimeError, "%v expects Bool inputs. Got %v instead", op, v.Dtype())
+				return
+			}
+			vals[i] = t
+		default:
+			err = nyi("logicalOp.do()", in)
+			return
+		}
+	}
+
+	if op.logicalOpType == notOpType {
+		switch v := vals[0].(type) {
+		case bool:
+			return anyToValue(!v)
+		case *tb.Tensor:
+			var t *tb.Tensor
+			if t, err = tb.Not(v, opts...); err != nil {
+				err = errors.Wrapf(err, doFail, op)
+				return
+			}
+			return anyToValue(t)
+		}
+	}
+
+	// both scalars
+	a, aok := vals[0].(bool)
+	b, bok := vals[1].(bool)
+	if aok && bok {
+		switch op.logicalOpType {
+		case andOpType:
+			return anyToValue(a && b)
+		case orOpType:
+			return anyToValue(a || b)
+		case xorOpType:
+			return anyToValue(a != b)
+		}
+	}
+
+	var t *tb.Tensor
+	switch op.logicalOpType {
+	case andOpType:
+		t, err = tb.And(vals[0], vals[1], opts...)
+	case orOpType:
+		t, err = tb.Or(vals[0], vals[1], opts...)
+	case xorOpType:
+		t, err = tb.Xor(vals[0], vals[1], opts...)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, doFail, op)
+		return
+	}
+	return anyToValue(t)
+}
+
+/* WHERE OP */
+
+// whereOp selects elements from its second or third input depending on the Bool mask that is its first input.
+// The second and third inputs either have the same shape as the mask, or are scalars.
+type whereOp struct {
+	shape   types.Shape
+	scalars [2]bool // whether a and b are scalars
+}
+
+func newWhereOp(s types.Shape, a, b *Node) whereOp {
+	return whereOp{
+		shape:   s.Clone(),
+		scalars: [2]bool{a.IsScalar(), b.IsScalar()},
+	}
+}
+
+// whereOp has these types:
+//		where :: (Floats a) ⇒ Bool → a → a → a
+//		where :: (Floats a) ⇒ Tensor d Bool → Tensor d a → Tensor d a → Tensor d a
+// Either a or b may be a scalar when the mask is a Tensor.
+func (op whereOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	d := op.shape.Dims()
+	if d == 0 {
+		return newFunctionType(Bool, a, a, a)
+	}
+
+	t := newTensorType(d, a)
+	ts := Types{newTensorType(d, Bool), t, t, t}
+	for i, scalar := range op.scalars {
+		if scalar {
+			ts[i+1] = a
+		}
+	}
+	return newFunctionType(ts...)
+}
+
+func (op whereOp) inferShape(retType Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "whereOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+	return op.shape.Clone(), nil
+}
+
+func (op whereOp) DiffWRT(inputs int) []bool { return []bool{false, true, true} }
+
+// SymDiff routes the gradient to a where the mask is true, and to b where the mask is false
+func (op whereOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "whereOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(gradNode.t); err != nil {
+		return
+	}
+
+	var zero *Node
+	switch dt {
+	case Float64:
+		zero = zerof64
+	case Float32:
+		zero = zerof32
+	default:
+		err = nyi("whereOp.SymDiff", dt)
+		return
+	}
+
+	cond := inputs[0]
+	retVal = make(Nodes, 3)
+	if retVal[1], err = Where(cond, gradNode, zero); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if retVal[2], err = Where(cond, zero, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	// scalars receive the sum of the gradients of the elements they were selected for
+	for i := 1; i < 3; i++ {
+		if inputs[i].IsScalar() && !retVal[i].IsScalar() {
+			if retVal[i], err = Sum(retVal[i]); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+		retVal[i].setGroup(gradClust)
+	}
+	return
+}
+
+// FwdDiff picks the tangents with the same mask. A nil tangent picks zeroes.
+func (op whereOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "whereOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(output.t); err != nil {
+		return
+	}
+
+	branches := make(Nodes, 2)
+	for i, t := range tangents[1:] {
+		if branches[i] = t; t == nil {
+			if branches[i], err = floatConstant(dt, 0); err != nil {
+				return
+			}
+		}
+	}
+
+	if retVal, err = Where(inputs[0], branches[0], branches[1]); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+func (op whereOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "whereOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var mask []bool
+	if mask, err = maskOf(op, inputs[0].Value()); err != nil {
+		return
+	}
+
+	ydv := output.boundTo.(*dualValue)
+	var dy interface{}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	adv := inputs[1].boundTo.(*dualValue)
+	if err = accumulateDeriv(adv, func(d interface{}) error { return maskedAdd(d, dy, mask, true) }); err != nil {
+		return
+	}
+
+	bdv := inputs[2].boundTo.(*dualValue)
+	return accumulateDeriv(bdv, func(d interface{}) error { return maskedAdd(d, dy, mask, false) })
+}
+
+func (op whereOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "whereOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var mask []bool
+	if mask, err = maskOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	if op.shape.IsScalar() {
+		if mask[0] {
+			return inputs[1], nil
+		}
+		return inputs[2], nil
+	}
+
+	var a, b interface{}
+	if a, err = valueData(inputs[1]); err != nil {
+		return
+	}
+	if b, err = valueData(inputs[2]); err != nil {
+		return
+	}
+
+	t := tensor.Zeroes(dtypeOfData(a), len(mask))
+	switch data := t.Data().(type) {
+	case []float64:
+		af, aok := a.([]float64)
+		bf, bok := b.([]float64)
+		if !aok || !bok {
+			err = NewError(RuntimeError, "%v expects both values to be of the same Dtype. Got %T and %T", op, a, b)
+			return
+		}
+		for i, m := range mask {
+			if m {
+				data[i] = af[i%len(af)]
+			} else {
+				data[i] = bf[i%len(bf)]
+			}
+		}
+	case []float32:
+		af, aok := a.([]float32)
+		bf, bok := b.([]float32)
+		if !aok || !bok {
+			err = NewError(RuntimeError, "%v expects both values to be of the same Dtype. Got %T and %T", op, a, b)
+			return
+		}
+		for i, m := range mask {
+			if m {
+				data[i] = af[i%len(af)]
+			} else {
+				data[i] = bf[i%len(bf)]
+			}
+		}
+	default:
+		err = nyi("whereOp.Do", data)
+		return
+	}
+
+	if err = t.Reshape(op.shape...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, op.shape, t.DataSize())
+		return
+	}
+	retVal = FromTensor(t)
+	return
+}
+
+func (op whereOp) returnsPtr() bool    { return false }
+func (op whereOp) callsExtern() bool   { return false }
+func (op whereOp) overwriteInput() int { return -1 }
+
+func (op whereOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("where"))
+	fmt.Fprintf(h, "%v%v", op.shape, op.scalars)
+}
+
+func (op whereOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op whereOp) String() string { return "Where" }
+
+// maskOf extracts the mask from a Bool value
+func maskOf(op Op, v Value) (retVal []bool, err error) {
+	switch vt := v.(type) {
+	case Scalar:
+		b, ok := vt.v.(bool)
+		if !ok {
+			err = NewError(RuntimeError, "%v expects a Bool mask. Got %v instead", op, vt.t)
+			return
+		}
+		retVal = []bool{b}
+	case Tensor:
+		t, ok := vt.Tensor.(*tb.Tensor)
+		if !ok {
+			err = NewError(RuntimeError, "%v expects a Bool mask. Got %v instead", op, vt.Dtype())
+			return
+		}
+		retVal = t.Materialize().Data().([]bool)
+	default:
+		err = nyi("maskOf", v)
+	}
+	return
+}
+
+// maskedAdd adds src[i] into dst[i] wherever mask[i] is want. If dst has only one element, everything is added into it.
+func maskedAdd(dst, src interface{}, mask []bool, want bool) error {
+	switch d := dst.(type) {
+	case []float64:
+		s, ok := src.([]float64)
+		if !ok {
+			return NewError(RuntimeError, "Expected src to be []float64. Got %T instead", src)
+		}
+		for i, m := range mask {
+			if m == want {
+				d[i%len(d)] += s[i]
+			}
+		}
+	case []float32:
+		s, ok := src.([]float32)
+		if !ok {
+			return NewError(RuntimeError, "Expected src to be []float32. Got %T instead", src)
+		}
+		for i, m := range mask {
+			if m == want {
+				d[i%len(d)] += s[i]
+			}
+		}
+	default:
+		return nyi("maskedAdd", dst)
+	}
+	return nil
+}
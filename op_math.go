@@ -19,11 +19,13 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"math"
 
 	"github.com/chewxy/gorgonia/tensor"
 	tf32 "github.com/chewxy/gorgonia/tensor/f32"
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
 	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/gonum/blas"
 	"github.com/pkg/errors"
 )
 
@@ -256,6 +258,114 @@ func (op elemBinOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes,
 	return
 }
 
+// FwdDiff uses the product, quotient and power rules. The comparison operators have no tangents.
+func (op elemBinOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "differentiating binary operations only takes 2 nodes. Got %d instead", len(inputs))
+		return
+	}
+
+	a, b := inputs[0], inputs[1]
+	ta, tb := tangents[0], tangents[1]
+
+	// da and db are the terms of the tangent contributed by the tangents of a and b
+	var da, db *Node
+	switch op.ʘBinaryOperator.binOpType() {
+	case addOpType:
+		da, db = ta, tb
+	case subOpType:
+		da = ta
+		if tb != nil {
+			db, err = Neg(tb)
+		}
+	case mulOpType:
+		if ta != nil {
+			if da, err = HadamardProd(ta, b); err != nil {
+				break
+			}
+		}
+		if tb != nil {
+			db, err = HadamardProd(a, tb)
+		}
+	case divOpType:
+		// d(a/b) = da/b - (a/b)·db/b
+		if ta != nil {
+			if da, err = HadamardDiv(ta, b); err != nil {
+				break
+			}
+		}
+		if tb != nil {
+			if db, err = HadamardProd(output, tb); err != nil {
+				break
+			}
+			if db, err = HadamardDiv(db, b); err != nil {
+				break
+			}
+			db, err = Neg(db)
+		}
+	case powOpType:
+		// d(aᵇ) = b·aᵇ⁻¹·da + aᵇ·ln(a)·db. The log is only taken when b moves, so that negative bases work with constant exponents.
+		if ta != nil {
+			var bm1 *Node
+			if bm1, err = HadamardDiv(output, a); err != nil {
+				break
+			}
+			if bm1, err = HadamardProd(bm1, b); err != nil {
+				break
+			}
+			if da, err = HadamardProd(bm1, ta); err != nil {
+				break
+			}
+		}
+		if tb != nil {
+			var ln *Node
+			if ln, err = Log(a); err != nil {
+				break
+			}
+			if ln, err = HadamardProd(output, ln); err != nil {
+				break
+			}
+			db, err = HadamardProd(ln, tb)
+		}
+	case atan2OpType:
+		// d(atan2(a, b)) = (b·da - a·db) / (a² + b²)
+		var a2, b2, r2 *Node
+		if a2, err = Square(a); err != nil {
+			break
+		}
+		if b2, err = Square(b); err != nil {
+			break
+		}
+		if r2, err = Add(a2, b2); err != nil {
+			break
+		}
+		if ta != nil {
+			if da, err = HadamardProd(b, ta); err != nil {
+				break
+			}
+			if da, err = HadamardDiv(da, r2); err != nil {
+				break
+			}
+		}
+		if tb != nil {
+			if db, err = HadamardProd(a, tb); err != nil {
+				break
+			}
+			if db, err = HadamardDiv(db, r2); err != nil {
+				break
+			}
+			db, err = Neg(db)
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return addTangents(da, db)
+}
+
 func (op elemBinOp) Do(values ...Value) (Value, error) {
 	return op.ʘBinaryOperator.Do(op.retSame, values...)
 }
@@ -322,7 +432,7 @@ func (op elemBinOp) WriteHash(h hash.Hash) {
 		panic(err)
 	}
 
-	fmt.Fprintf(h, "%v,%v", op.arg0, op.arg1)
+	fmt.Fprintf(h, "%v,%v,%t", op.arg0, op.arg1, op.retSame)
 }
 
 func (op elemBinOp) Hashcode() uint32 {
@@ -390,7 +500,8 @@ type elemUnaryOp struct {
 	ʘUnaryOperator
 
 	argTensor     bool
-	numericResult bool // indicate if boolean results should be converted to 1 and 0 in the respective Dtype
+	numericResult bool    // indicate if boolean results should be converted to 1 and 0 in the respective Dtype
+	alpha         float64 // parameter of the parameterized operators (leaky ReLU, ELU)
 }
 
 func newElemUnaryOp(op ʘUnaryOperatorType, a *Node) elemUnaryOp {
@@ -412,6 +523,7 @@ func newElemUnaryOp(op ʘUnaryOperatorType, a *Node) elemUnaryOp {
 	return elemUnaryOp{
 		ʘUnaryOperator: operator,
 		argTensor:      isTensor,
+		alpha:          op.defaultAlpha(),
 	}
 }
 
@@ -468,6 +580,20 @@ func (op elemUnaryOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Node
 	return
 }
 
+// FwdDiff reuses the expression of the gradient: the Jacobian of an elementwise op is diagonal, so it is the same as its transpose.
+func (op elemUnaryOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Differentiating unary operation expects only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	u := op.ʘUnaryOperator.unaryOpType()
+	if !ʘUnaryOpDifferentiable[u] {
+		return nil, nil
+	}
+	return ʘUnaryOpDiffExprs[u](inputs[0], output, tangents[0])
+}
+
 func (op elemUnaryOp) DoDiff(inputs Nodes, output *Node) (err error) {
 	if len(inputs) != 1 {
 		err = NewError(GraphError, "differentiating binary operations only takes 1 node. Got %d instead", len(inputs))
@@ -506,6 +632,12 @@ func (op elemUnaryOp) WriteHash(h hash.Hash) {
 	} else {
 		h.Write([]byte{0})
 	}
+
+	if op.unaryOpType().parameterized() {
+		if err := binary.Write(h, binary.LittleEndian, op.alpha); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func (op elemUnaryOp) Hashcode() uint32 {
@@ -514,6 +646,13 @@ func (op elemUnaryOp) Hashcode() uint32 {
 	return h.Sum32()
 }
 
+func (op elemUnaryOp) String() string {
+	if u := op.unaryOpType(); u.parameterized() {
+		return fmt.Sprintf("%v(%v)", u, op.alpha)
+	}
+	return op.ʘUnaryOperator.String()
+}
+
 // fulfils UnsafeDoer interface
 func (op elemUnaryOp) UnsafeDo(inputs ...Value) (Value, error) {
 	return op.do(inputs, types.UseUnsafe())
@@ -525,6 +664,30 @@ func (op elemUnaryOp) isUnary() bool { return true }
 
 // misc private methods
 
+// f64 returns the float64 kernel of the operator, with the alpha of parameterized operators bound
+func (op elemUnaryOp) f64() func(float64) float64 {
+	alpha := op.alpha
+	switch op.unaryOpType() {
+	case leakyReluOpType:
+		return func(x float64) float64 { return _leakyReluf64(x, alpha) }
+	case eluOpType:
+		return func(x float64) float64 { return _eluf64(x, alpha) }
+	}
+	return *(op.ʘUnaryOperator.(*sf64UnaryOperator))
+}
+
+// f32 returns the float32 kernel of the operator, with the alpha of parameterized operators bound
+func (op elemUnaryOp) f32() func(float32) float32 {
+	alpha := float32(op.alpha)
+	switch op.unaryOpType() {
+	case leakyReluOpType:
+		return func(x float32) float32 { return _leakyReluf32(x, alpha) }
+	case eluOpType:
+		return func(x float32) float32 { return _eluf32(x, alpha) }
+	}
+	return *(op.ʘUnaryOperator.(*sf32UnaryOperator))
+}
+
 func (op elemUnaryOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, err error) {
 	if len(inputs) != 1 {
 		err = NewError(GraphError, "Executing unary operation expects only one input. Got %d instead", len(inputs))
@@ -536,8 +699,11 @@ func (op elemUnaryOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, e
 	case Tensor:
 		switch vt := v.Tensor.(type) {
 		case *tf64.Tensor:
-			opFn := op.ʘUnaryOperator.(*sf64UnaryOperator)
-			fn := (func(float64) float64)(*opFn)
+			fn := op.f64()
+			if vt.IsView() {
+				// Apply does not walk views, so slices are copied out first
+				vt = vt.Materialize().(*tf64.Tensor)
+			}
 
 			// TODO: this is pretty shit.... the tf64 lib provides a whole bunch of these
 			var t types.Tensor
@@ -546,8 +712,11 @@ func (op elemUnaryOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, e
 			}
 			retVal = FromTensor(t)
 		case *tf32.Tensor:
-			opFn := op.ʘUnaryOperator.(*sf32UnaryOperator)
-			fn := (func(float32) float32)(*opFn)
+			fn := op.f32()
+			if vt.IsView() {
+				// Apply does not walk views, so slices are copied out first
+				vt = vt.Materialize().(*tf32.Tensor)
+			}
 
 			// TODO: this is pretty shit.... the tf64 lib provides a whole bunch of these
 			var t types.Tensor
@@ -562,12 +731,10 @@ func (op elemUnaryOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, e
 		switch v.t {
 		case Float32:
 			f := v.v.(float32)
-			opFn := op.ʘUnaryOperator.(*sf32UnaryOperator)
-			retVal = NewScalarValue((*opFn)(f))
+			retVal = NewScalarValue(op.f32()(f))
 		case Float64:
 			f := v.v.(float64)
-			opFn := op.ʘUnaryOperator.(*sf64UnaryOperator)
-			retVal = NewScalarValue((*opFn)(f))
+			retVal = NewScalarValue(op.f64()(f))
 		default:
 			err = nyi("elemUnaryOp.do", v.t)
 		}
@@ -629,6 +796,8 @@ func (op linAlgBinOp) inferShape(retType Type, inputs ...*Node) (retVal types.Sh
 	case outerProdOperator:
 		// outerprods only handles vec x vec for now
 		retVal = types.Shape{x.shape.TotalSize(), y.shape.TotalSize()}
+	case batchedMatMulOperator:
+		retVal, err = batchedMatMulShape(x.shape, y.shape, op.transA, op.transB)
 	}
 	return
 }
@@ -648,6 +817,29 @@ func (op linAlgBinOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Node
 	return
 }
 
+// FwdDiff uses the product rule: the operators are bilinear, so each tangent is multiplied with the other input.
+func (op linAlgBinOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "Differentiating binary linear algebra operators expects exactly two inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var da, db *Node
+	if tangents[0] != nil {
+		if da, err = binOpNode(op, tangents[0], inputs[1]); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	if tangents[1] != nil {
+		if db, err = binOpNode(op, inputs[0], tangents[1]); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	return addTangents(da, db)
+}
+
 func (op linAlgBinOp) DoDiff(inputs Nodes, output *Node) (err error) {
 	if len(inputs) != 2 {
 		err = NewError(GraphError, "Differentiating binary linear algebra operators expects exactly two inputs. Got %d instead", len(inputs))
@@ -691,7 +883,7 @@ func (op linAlgBinOp) String() string {
 	var buf bytes.Buffer
 
 	switch op.āBinaryOperator {
-	case matMulOperator, matVecMulOperator:
+	case matMulOperator, matVecMulOperator, batchedMatMulOperator:
 		buf.WriteString("A")
 	case vecDotOperator, outerProdOperator:
 		buf.WriteString("a")
@@ -702,7 +894,7 @@ func (op linAlgBinOp) String() string {
 	}
 
 	switch op.āBinaryOperator {
-	case matMulOperator:
+	case matMulOperator, batchedMatMulOperator:
 		fmt.Fprintf(&buf, " %v B", op.āBinaryOperator)
 	case matVecMulOperator, vecDotOperator, outerProdOperator:
 		fmt.Fprintf(&buf, " %v b", op.āBinaryOperator)
@@ -766,6 +958,11 @@ func (op linAlgBinOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, e
 
 	a, b := inputs[0].(Tensor), inputs[1].(Tensor)
 
+	// batched matmuls handle the transposes in the BLAS calls
+	if op.āBinaryOperator == batchedMatMulOperator {
+		return op.batchedMatMul(a.Tensor, b.Tensor, opts...)
+	}
+
 	if op.transA {
 		if err = a.Tensor.T(); err != nil {
 			err = errors.Wrap(err, tFail)
@@ -801,3 +998,460 @@ func (op linAlgBinOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, e
 	}
 	return
 }
+
+// batchedMatMul does one GEMM per matrix in the batch, using the BLAS that gorgonia is configured to use.
+func (op linAlgBinOp) batchedMatMul(a, b types.Tensor, opts ...types.FuncOpt) (retVal Value, err error) {
+	if a.IsView() {
+		a = a.Materialize()
+	}
+	if b.IsView() {
+		b = b.Materialize()
+	}
+
+	as, bs := a.Shape(), b.Shape()
+	var s types.Shape
+	if s, err = batchedMatMulShape(as, bs, op.transA, op.transB); err != nil {
+		return
+	}
+	batch, m, n := s[0], s[1], s[2]
+	k := as[2]
+	if op.transA {
+		k = as[1]
+	}
+
+	var reuse, incr types.Tensor
+	for _, opt := range opts {
+		flag, iface := opt()
+		switch flag {
+		case types.Reuse:
+			reuse = iface.(types.Tensor)
+		case types.Incr:
+			incr = iface.(types.Tensor)
+		}
+	}
+
+	// with an incr, the results are accumulated into it
+	var c types.Tensor
+	var beta float64
+	switch {
+	case incr != nil:
+		c = incr
+		beta = 1
+	case reuse != nil:
+		c = reuse
+	default:
+		c = tensor.Zeroes(a.Dtype(), s...)
+	}
+
+	if c.Size() != s.TotalSize() {
+		err = NewError(ShapeError, "Expected a tensor of shape %v to store the result. Got %v instead", s, c.Shape())
+		return
+	}
+	if err = c.Reshape(s...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, s, c.DataSize())
+		return
+	}
+
+	tA, tB := blas.NoTrans, blas.NoTrans
+	if op.transA {
+		tA = blas.Trans
+	}
+	if op.transB {
+		tB = blas.Trans
+	}
+
+	// leading dimensions are of the matrices as they are stored, before any transposition
+	lda, ldb := as[2], bs[2]
+	aSize, bSize, cSize := as[1]*as[2], bs[1]*bs[2], m*n
+
+	switch ad := a.Data().(type) {
+	case []float64:
+		bd, ok1 := b.Data().([]float64)
+		cd, ok2 := c.Data().([]float64)
+		if !ok1 || !ok2 {
+			err = NewError(TypeError, "Cannot do %v on %v and %v", op, a.Dtype(), b.Dtype())
+			return
+		}
+		for i := 0; i < batch; i++ {
+			whichblas.Dgemm(tA, tB, m, n, k, 1, ad[i*aSize:(i+1)*aSize], lda, bd[i*bSize:(i+1)*bSize], ldb, beta, cd[i*cSize:(i+1)*cSize], n)
+		}
+	case []float32:
+		bd, ok1 := b.Data().([]float32)
+		cd, ok2 := c.Data().([]float32)
+		if !ok1 || !ok2 {
+			err = NewError(TypeError, "Cannot do %v on %v and %v", op, a.Dtype(), b.Dtype())
+			return
+		}
+		for i := 0; i < batch; i++ {
+			whichblas.Sgemm(tA, tB, m, n, k, 1, ad[i*aSize:(i+1)*aSize], lda, bd[i*bSize:(i+1)*bSize], ldb, float32(beta), cd[i*cSize:(i+1)*cSize], n)
+		}
+	default:
+		err = nyi("batchedMatMul", a.Dtype())
+		return
+	}
+
+	retVal = FromTensor(c)
+	return
+}
+
+// batchedMatMulShape calculates the shape of multiplying (B, M, K) and (B, K, N) tensors, after the optional transposes of the
+// last two axes.
+func batchedMatMulShape(a, b types.Shape, transA, transB bool) (retVal types.Shape, err error) {
+	if len(a) != 3 || len(b) != 3 {
+		err = NewError(ShapeError, "Batched matrix multiplication expects 3D tensors. Got %v and %v instead", a, b)
+		return
+	}
+
+	m, k := a[1], a[2]
+	if transA {
+		m, k = k, m
+	}
+	k2, n := b[1], b[2]
+	if transB {
+		k2, n = n, k2
+	}
+
+	if a[0] != b[0] || k != k2 {
+		err = NewError(ShapeError, "Incompatible shapes for batched matrix multiplication: %v and %v (transA: %t, transB: %t)", a, b, transA, transB)
+		return
+	}
+	return types.Shape{a[0], m, n}, nil
+}
+
+/* CLAMP */
+
+// clampOp clamps its input to [min, max]. The bounds are either the constants held by the op,
+// or the second and third inputs (which are scalars)
+type clampOp struct {
+	min, max   float64
+	nodeBounds bool
+	d          int
+}
+
+// clampOp has these types:
+//		clamp :: (Floats a) ⇒ Tensor d a → Tensor d a
+//		clamp :: (Floats a) ⇒ Tensor d a → a → a → Tensor d a
+// where the second type is used when the bounds are nodes. If d is 0, Tensor d a is simply a.
+func (op clampOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	var t Type = a
+	if op.d > 0 {
+		t = newTensorType(op.d, a)
+	}
+
+	if op.nodeBounds {
+		return newFunctionType(t, a, a, t)
+	}
+	return newFunctionType(t, t)
+}
+
+func (op clampOp) inferShape(retType Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "clampOp expects %d inputs. Got %d instead", op.arity(), len(inputs))
+		return
+	}
+	return inputs[0].shape.Clone(), nil
+}
+
+func (op clampOp) DiffWRT(inputs int) []bool {
+	if op.nodeBounds {
+		return []bool{true, true, true}
+	}
+	return []bool{true}
+}
+
+// SymDiff passes the gradient through the elements that were not clamped. The elements that were clamped
+// get a zero gradient, and their gradients go to the bound that clamped them instead.
+func (op clampOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "clampOp expects %d inputs. Got %d instead", op.arity(), len(inputs))
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(gradNode.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, gradNode.t)
+		return
+	}
+
+	var zero *Node
+	if zero, err = floatConstant(dt, 0); err != nil {
+		return
+	}
+
+	x := inputs[0]
+	var inside *Node
+	if inside, err = Eq(x, output, false); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	WithGroupName(gradClust)(inside)
+
+	retVal = make(Nodes, len(inputs))
+	if retVal[0], err = Where(inside, gradNode, zero); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	if !op.nodeBounds {
+		return
+	}
+
+	var below, above *Node
+	if below, err = Lt(x, inputs[1], false); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if above, err = Gt(x, inputs[2], false); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	for i, clamped := range []*Node{below, above} {
+		WithGroupName(gradClust)(clamped)
+		if retVal[i+1], err = Where(clamped, gradNode, zero); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		if !retVal[i+1].IsScalar() {
+			WithGroupName(gradClust)(retVal[i+1])
+			if retVal[i+1], err = Sum(retVal[i+1]); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+	}
+	return
+}
+
+// FwdDiff takes the tangent of the input where it is inside the bounds, and the tangents of the bounds where it is clamped.
+func (op clampOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "clampOp expects %d inputs. Got %d instead", op.arity(), len(inputs))
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(output.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, output.t)
+		return
+	}
+
+	var zero *Node
+	if zero, err = floatConstant(dt, 0); err != nil {
+		return
+	}
+
+	x := inputs[0]
+	terms := make(Nodes, len(inputs))
+	if tangents[0] != nil {
+		var inside *Node
+		if inside, err = Eq(x, output, false); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		if terms[0], err = Where(inside, tangents[0], zero); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+
+	if op.nodeBounds {
+		clamped := []func(a, b *Node, retSame bool) (*Node, error){Lt, Gt}
+		for i, cmp := range clamped {
+			if tangents[i+1] == nil {
+				continue
+			}
+
+			var c *Node
+			if c, err = cmp(x, inputs[i+1], false); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+			if terms[i+1], err = Where(c, tangents[i+1], zero); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+	}
+	return addTangents(terms...)
+}
+
+func (op clampOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "clampOp expects %d inputs. Got %d instead", op.arity(), len(inputs))
+		return
+	}
+
+	var x, y, dy interface{}
+	if x, err = valueData(inputs[0].Value()); err != nil {
+		return
+	}
+	ydv := output.boundTo.(*dualValue)
+	if y, err = valueData(ydv.Value); err != nil {
+		return
+	}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	inside := func(x, y float64) bool { return x == y }
+	if err = accumulateDeriv(xdv, func(d interface{}) error { return addWhere(d, x, y, dy, inside) }); err != nil {
+		return
+	}
+
+	if !op.nodeBounds {
+		return
+	}
+
+	var min, max interface{}
+	if min, err = valueData(inputs[1].Value()); err != nil {
+		return
+	}
+	if max, err = valueData(inputs[2].Value()); err != nil {
+		return
+	}
+
+	below := func(x, min float64) bool { return x < min }
+	above := func(x, max float64) bool { return x > max }
+	if err = accumulateDeriv(inputs[1].boundTo.(*dualValue), func(d interface{}) error { return addWhere(d, x, min, dy, below) }); err != nil {
+		return
+	}
+	return accumulateDeriv(inputs[2].boundTo.(*dualValue), func(d interface{}) error { return addWhere(d, x, max, dy, above) })
+}
+
+func (op clampOp) Do(inputs ...Value) (Value, error) { return op.do(inputs) }
+
+// fulfils UnsafeDoer interface
+func (op clampOp) UnsafeDo(inputs ...Value) (Value, error) { return op.do(inputs, types.UseUnsafe()) }
+
+func (op clampOp) returnsPtr() bool  { return op.d > 0 }
+func (op clampOp) callsExtern() bool { return false }
+func (op clampOp) overwriteInput() int {
+	if op.d > 0 {
+		return 0
+	}
+	return -1
+}
+
+func (op clampOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("clamp"))
+	fmt.Fprintf(h, "%v%v%t%d", op.min, op.max, op.nodeBounds, op.d)
+}
+
+func (op clampOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op clampOp) String() string {
+	if op.nodeBounds {
+		return "Clamp"
+	}
+	return fmt.Sprintf("Clamp(%v, %v)", op.min, op.max)
+}
+
+func (op clampOp) arity() int {
+	if op.nodeBounds {
+		return 3
+	}
+	return 1
+}
+
+func (op clampOp) do(inputs []Value, opts ...types.FuncOpt) (retVal Value, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "clampOp expects %d inputs. Got %d instead", op.arity(), len(inputs))
+		return
+	}
+
+	min, max := op.min, op.max
+	if op.nodeBounds {
+		if min, err = scalarFloat(op, inputs[1]); err != nil {
+			return
+		}
+		if max, err = scalarFloat(op, inputs[2]); err != nil {
+			return
+		}
+	}
+
+	switch x := inputs[0].(type) {
+	case Scalar:
+		switch v := x.v.(type) {
+		case float64:
+			retVal = NewScalarValue(math.Max(min, math.Min(max, v)))
+		case float32:
+			retVal = NewScalarValue(float32(math.Max(min, math.Min(max, float64(v)))))
+		default:
+			err = nyi("clampOp.Do", x.t)
+		}
+	case Tensor:
+		var xt, t types.Tensor
+		if xt, err = tensorOf(op, x); err != nil {
+			return
+		}
+		switch xt := xt.(type) {
+		case *tf64.Tensor:
+			t, err = tf64.Clamp(xt, min, max, opts...)
+		case *tf32.Tensor:
+			t, err = tf32.Clamp(xt, float32(min), float32(max), opts...)
+		default:
+			err = nyi("clampOp.Do", xt)
+		}
+		if err == nil {
+			retVal = FromTensor(t)
+		}
+	default:
+		err = nyi("clampOp.Do", inputs[0])
+	}
+	return
+}
+
+// scalarFloat extracts a float64 from a Float32 or Float64 scalar value
+func scalarFloat(op Op, v Value) (retVal float64, err error) {
+	if s, ok := v.(Scalar); ok {
+		switch f := s.v.(type) {
+		case float64:
+			return f, nil
+		case float32:
+			return float64(f), nil
+		}
+	}
+	err = NewError(RuntimeError, "%v expects a float scalar. Got %v of %T instead", op, v, v)
+	return
+}
+
+// addWhere adds dy[i] into d wherever pick(x[i], y[i]) is true. If d has only one element, everything is added into it.
+// y may have only one element, in which case it is used for every x.
+func addWhere(d, x, y, dy interface{}, pick func(x, y float64) bool) error {
+	switch dt := d.(type) {
+	case []float64:
+		xs, xok := x.([]float64)
+		ys, yok := y.([]float64)
+		dys, dyok := dy.([]float64)
+		if !xok || !yok || !dyok {
+			return NewError(RuntimeError, "Expected []float64. Got %T, %T and %T instead", x, y, dy)
+		}
+		for i, v := range xs {
+			if pick(v, ys[i%len(ys)]) {
+				dt[i%len(dt)] += dys[i]
+			}
+		}
+	case []float32:
+		xs, xok := x.([]float32)
+		ys, yok := y.([]float32)
+		dys, dyok := dy.([]float32)
+		if !xok || !yok || !dyok {
+			return NewError(RuntimeError, "Expected []float32. Got %T, %T and %T instead", x, y, dy)
+		}
+		for i, v := range xs {
+			if pick(float64(v), float64(ys[i%len(ys)])) {
+				dt[i%len(dt)] += dys[i]
+			}
+		}
+	default:
+		return nyi("addWhere", d)
+	}
+	return nil
+}
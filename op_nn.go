@@ -1,15 +1,18 @@
 package gorgonia
 
 import (
+	"encoding/binary"
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"math"
 	"time"
 
 	tf32 "github.com/chewxy/gorgonia/tensor/f32"
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
 	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/leesper/go_rng"
+	"github.com/pkg/errors"
 )
 
 /*
@@ -133,3 +136,2222 @@ func (op randomOp) Hashcode() uint32 {
 func (op randomOp) String() string {
 	return fmt.Sprintf("%v(%v, %v) - %v", op.which, op.a, op.b, op.shape)
 }
+
+// window describes the sliding window used by convolutions and pooling.
+// All the images are expected to be in NCHW format.
+type window struct {
+	kh, kw           int // size of the kernel
+	padH, padW       int
+	strideH, strideW int
+	dilH, dilW       int
+}
+
+func (w window) outSize(h, wd int) (oh, ow int) {
+	oh = (h+2*w.padH-w.dilH*(w.kh-1)-1)/w.strideH + 1
+	ow = (wd+2*w.padW-w.dilW*(w.kw-1)-1)/w.strideW + 1
+	return
+}
+
+// outShape calculates the shape of sliding the window over an image of the given shape.
+// The channel dimension of the result is left as is.
+func (w window) outShape(s types.Shape) (retVal types.Shape, err error) {
+	if len(s) != 4 {
+		err = NewError(ShapeError, "Expected a 4D image in NCHW format. Got %v instead", s)
+		return
+	}
+
+	oh, ow := w.outSize(s[2], s[3])
+	if oh <= 0 || ow <= 0 {
+		err = NewError(ShapeError, "Window %v is too large for an image of %v", w, s)
+		return
+	}
+	return types.Shape{s[0], s[1], oh, ow}, nil
+}
+
+// walk calls fn for every element under the window as it slides over an image of size (n, c, h, wd).
+// b and ch are the batch and channel, o is the flat index of the window's position in the output image, and k is the flat index into the kernel.
+// in is the flat index into the image, or -1 if the element is padding.
+func (w window) walk(n, c, h, wd int, fn func(b, ch, o, k, in int)) {
+	oh, ow := w.outSize(h, wd)
+	for b := 0; b < n; b++ {
+		for ch := 0; ch < c; ch++ {
+			base := (b*c + ch) * h * wd
+			for oy := 0; oy < oh; oy++ {
+				for ox := 0; ox < ow; ox++ {
+					o := oy*ow + ox
+					for ki := 0; ki < w.kh; ki++ {
+						y := oy*w.strideH - w.padH + ki*w.dilH
+						for kj := 0; kj < w.kw; kj++ {
+							x := ox*w.strideW - w.padW + kj*w.dilW
+							in := -1
+							if y >= 0 && y < h && x >= 0 && x < wd {
+								in = base + y*wd + x
+							}
+							fn(b, ch, o, ki*w.kw+kj, in)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func (w window) WriteHash(h hash.Hash) {
+	for _, v := range []int{w.kh, w.kw, w.padH, w.padW, w.strideH, w.strideW, w.dilH, w.dilW} {
+		if err := binary.Write(h, binary.LittleEndian, int32(v)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (w window) String() string {
+	return fmt.Sprintf("{kernel: (%d, %d), pad: (%d, %d), stride: (%d, %d), dilation: (%d, %d)}", w.kh, w.kw, w.padH, w.padW, w.strideH, w.strideW, w.dilH, w.dilW)
+}
+
+// windowOpType is the type of all the ops that slide windows over images:
+//		op :: Tensor 4 a → Tensor 4 a
+func windowOpType() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	tt := newTensorType(4, a)
+	return newFunctionType(tt, tt)
+}
+
+// imageOf extracts a contiguous tensor from an input value.
+func imageOf(op Op, v Value) (t types.Tensor, err error) {
+	if t, err = tensorOf(op, v); err != nil {
+		return
+	}
+
+	if len(t.Shape()) != 4 {
+		err = NewError(ShapeError, "%v expects a 4D input. Got %v instead", op, t.Shape())
+	}
+	return
+}
+
+// addInto adds the gradient d into the derivative of the dual value of the node
+func addInto(n *Node, d Value) (err error) {
+	dv := n.boundTo.(*dualValue)
+	add := newEBOByType(addOpType, dv.d.Type(), d.Type())
+	if _, err = add.UnsafeDo(dv.d, d); err != nil {
+		err = errors.Wrapf(err, unsafeDoFail, add)
+	}
+	return
+}
+
+// im2colOp unrolls every window of an image (N, C, H, W) into a row, giving a (N, OH, OW, C*KH*KW) tensor.
+// Convolutions are then matrix multiplications of the result.
+type im2colOp struct {
+	window
+}
+
+func (op im2colOp) Type() Type { return windowOpType() }
+
+func (op im2colOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "im2colOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var s types.Shape
+	if s, err = op.outShape(inputs[0].shape); err != nil {
+		return
+	}
+	return types.Shape{s[0], s[2], s[3], s[1] * op.kh * op.kw}, nil
+}
+
+func (op im2colOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op im2colOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "im2colOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	s := inputs[0].shape
+	diff := col2imOp{op.window, s[2], s[3]}
+
+	var n *Node
+	if n, err = applyOp(diff, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal = Nodes{n}
+	return
+}
+
+// FwdDiff unrolls the tangent.
+func (op im2colOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op im2colOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "im2colOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	s := inputs[0].shape
+	diff := col2imOp{op.window, s[2], s[3]}
+	ydv := output.boundTo.(*dualValue)
+
+	var d Value
+	if d, err = diff.Do(ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+func (op im2colOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "im2colOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var im types.Tensor
+	if im, err = imageOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	s := im.Shape()
+	n, c, h, w := s[0], s[1], s[2], s[3]
+	oh, ow := op.outSize(h, w)
+	kk := op.kh * op.kw
+	rowSize := c * kk
+	col := func(b, ch, o, k int) int { return (b*oh*ow+o)*rowSize + ch*kk + k }
+
+	switch data := im.Data().(type) {
+	case []float64:
+		t := tf64.NewTensor(tf64.WithShape(n, oh, ow, rowSize))
+		cols := t.Data().([]float64)
+		op.walk(n, c, h, w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				cols[col(b, ch, o, k)] = data[in]
+			}
+		})
+		retVal = FromTensor(t)
+	case []float32:
+		t := tf32.NewTensor(tf32.WithShape(n, oh, ow, rowSize))
+		cols := t.Data().([]float32)
+		op.walk(n, c, h, w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				cols[col(b, ch, o, k)] = data[in]
+			}
+		})
+		retVal = FromTensor(t)
+	default:
+		err = nyi("im2colOp", im.Dtype())
+	}
+	return
+}
+
+func (op im2colOp) returnsPtr() bool    { return false }
+func (op im2colOp) callsExtern() bool   { return false }
+func (op im2colOp) overwriteInput() int { return -1 }
+
+func (op im2colOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("im2col"))
+	op.window.WriteHash(h)
+}
+
+func (op im2colOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op im2colOp) String() string { return fmt.Sprintf("im2col%v", op.window) }
+
+// col2imOp is the adjoint of im2colOp: it sums the unrolled windows back into an image of size h, w.
+type col2imOp struct {
+	window
+	h, w int
+}
+
+func (op col2imOp) Type() Type { return windowOpType() }
+
+func (op col2imOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "col2imOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	s := inputs[0].shape
+	if len(s) != 4 || s[3]%(op.kh*op.kw) != 0 {
+		err = NewError(ShapeError, "col2imOp cannot take an input of %v", s)
+		return
+	}
+	return types.Shape{s[0], s[3] / (op.kh * op.kw), op.h, op.w}, nil
+}
+
+func (op col2imOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op col2imOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "col2imOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var n *Node
+	if n, err = applyOp(im2colOp{op.window}, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal = Nodes{n}
+	return
+}
+
+// FwdDiff sums the unrolled tangent.
+func (op col2imOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op col2imOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "col2imOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	diff := im2colOp{op.window}
+	ydv := output.boundTo.(*dualValue)
+
+	var d Value
+	if d, err = diff.Do(ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+func (op col2imOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "col2imOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var cols types.Tensor
+	if cols, err = imageOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	s := cols.Shape()
+	kk := op.kh * op.kw
+	n, c := s[0], s[3]/kk
+	oh, ow := op.outSize(op.h, op.w)
+	if s[1] != oh || s[2] != ow {
+		err = NewError(ShapeError, "col2imOp expected an input of (%d, %d, %d, %d). Got %v instead", n, oh, ow, c*kk, s)
+		return
+	}
+	col := func(b, ch, o, k int) int { return (b*oh*ow+o)*s[3] + ch*kk + k }
+
+	switch data := cols.Data().(type) {
+	case []float64:
+		t := tf64.NewTensor(tf64.WithShape(n, c, op.h, op.w))
+		im := t.Data().([]float64)
+		op.walk(n, c, op.h, op.w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				im[in] += data[col(b, ch, o, k)]
+			}
+		})
+		retVal = FromTensor(t)
+	case []float32:
+		t := tf32.NewTensor(tf32.WithShape(n, c, op.h, op.w))
+		im := t.Data().([]float32)
+		op.walk(n, c, op.h, op.w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				im[in] += data[col(b, ch, o, k)]
+			}
+		})
+		retVal = FromTensor(t)
+	default:
+		err = nyi("col2imOp", cols.Dtype())
+	}
+	return
+}
+
+func (op col2imOp) returnsPtr() bool    { return false }
+func (op col2imOp) callsExtern() bool   { return false }
+func (op col2imOp) overwriteInput() int { return -1 }
+
+func (op col2imOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("col2im"))
+	op.window.WriteHash(h)
+	fmt.Fprintf(h, "%d,%d", op.h, op.w)
+}
+
+func (op col2imOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op col2imOp) String() string { return fmt.Sprintf("col2im%v", op.window) }
+
+// maxPoolOp takes the maximum of each window of an image. Padding is never selected.
+type maxPoolOp struct {
+	window
+}
+
+func (op maxPoolOp) Type() Type { return windowOpType() }
+
+func (op maxPoolOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "maxPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+	return op.outShape(inputs[0].shape)
+}
+
+func (op maxPoolOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op maxPoolOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "maxPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var n *Node
+	if n, err = applyOp(maxPoolDiffOp{op.window}, inputs[0], gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal = Nodes{n}
+	return
+}
+
+func (op maxPoolOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "maxPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	if retVal, err = applyOp(maxPoolFwdDiffOp{op.window}, inputs[0], tangents[0]); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+func (op maxPoolOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "maxPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	diff := maxPoolDiffOp{op.window}
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var d Value
+	if d, err = diff.Do(xdv.Value, ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+func (op maxPoolOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "maxPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var im types.Tensor
+	if im, err = imageOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var s types.Shape
+	if s, err = op.outShape(im.Shape()); err != nil {
+		return
+	}
+	n, c, h, w := im.Shape()[0], im.Shape()[1], im.Shape()[2], im.Shape()[3]
+	ohw := s[2] * s[3]
+
+	switch data := im.Data().(type) {
+	case []float64:
+		t := tf64.NewTensor(tf64.WithShape(s...))
+		out := t.Data().([]float64)
+		for i := range out {
+			out[i] = math.Inf(-1)
+		}
+		op.walk(n, c, h, w, func(b, ch, o, k, in int) {
+			idx := (b*c+ch)*ohw + o
+			if in >= 0 && data[in] > out[idx] {
+				out[idx] = data[in]
+			}
+		})
+		retVal = FromTensor(t)
+	case []float32:
+		t := tf32.NewTensor(tf32.WithShape(s...))
+		out := t.Data().([]float32)
+		for i := range out {
+			out[i] = float32(math.Inf(-1))
+		}
+		op.walk(n, c, h, w, func(b, ch, o, k, in int) {
+			idx := (b*c+ch)*ohw + o
+			if in >= 0 && data[in] > out[idx] {
+				out[idx] = data[in]
+			}
+		})
+		retVal = FromTensor(t)
+	default:
+		err = nyi("maxPoolOp", im.Dtype())
+	}
+	return
+}
+
+func (op maxPoolOp) returnsPtr() bool    { return false }
+func (op maxPoolOp) callsExtern() bool   { return false }
+func (op maxPoolOp) overwriteInput() int { return -1 }
+
+func (op maxPoolOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("maxpool"))
+	op.window.WriteHash(h)
+}
+
+func (op maxPoolOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op maxPoolOp) String() string { return fmt.Sprintf("MaxPool%v", op.window) }
+
+// maxPoolDiffOp routes the gradient of a max pool to the first maximum of each window.
+// It takes the input of the max pool and the gradient of its output.
+type maxPoolDiffOp struct {
+	window
+}
+
+// maxPoolDiffOp has this type:
+//		op :: Tensor 4 a → Tensor 4 a → Tensor 4 a
+func (op maxPoolDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	tt := newTensorType(4, a)
+	return newFunctionType(tt, tt, tt)
+}
+
+func (op maxPoolDiffOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "maxPoolDiffOp takes two inputs. Got %d instead", len(inputs))
+		return
+	}
+	return inputs[0].shape.Clone(), nil
+}
+
+func (op maxPoolDiffOp) DiffWRT(i int) []bool { return []bool{true, true} }
+
+// SymDiff differentiates the routing of the gradient. It is piecewise constant in the image, and linear in the gradient:
+// its transpose picks the element of the first maximum of each window, which is what maxPoolFwdDiffOp does.
+func (op maxPoolDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "maxPoolDiffOp takes two inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	retVal = make(Nodes, 2)
+	if retVal[0], err = zeroGrad(inputs[0]); err != nil {
+		return
+	}
+	if retVal[1], err = applyOp(maxPoolFwdDiffOp{op.window}, inputs[0], gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[1].setGroup(gradClust)
+	return
+}
+
+func (op maxPoolDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "maxPoolDiffOp takes two inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var im, grad types.Tensor
+	if im, err = imageOf(op, inputs[0]); err != nil {
+		return
+	}
+	if grad, err = imageOf(op, inputs[1]); err != nil {
+		return
+	}
+
+	var s types.Shape
+	if s, err = op.outShape(im.Shape()); err != nil {
+		return
+	}
+	if !s.Eq(grad.Shape()) {
+		err = NewError(ShapeError, "maxPoolDiffOp expected a gradient of %v. Got %v instead", s, grad.Shape())
+		return
+	}
+
+	var argmax []int
+	if argmax, err = op.argmax(im, s); err != nil {
+		return
+	}
+
+	switch dy := grad.Data().(type) {
+	case []float64:
+		t := tf64.NewTensor(tf64.WithShape(im.Shape()...))
+		dx := t.Data().([]float64)
+		for i, in := range argmax {
+			if in >= 0 {
+				dx[in] += dy[i]
+			}
+		}
+		retVal = FromTensor(t)
+	case []float32:
+		t := tf32.NewTensor(tf32.WithShape(im.Shape()...))
+		dx := t.Data().([]float32)
+		for i, in := range argmax {
+			if in >= 0 {
+				dx[in] += dy[i]
+			}
+		}
+		retVal = FromTensor(t)
+	default:
+		err = nyi("maxPoolDiffOp", grad.Dtype())
+	}
+	return
+}
+
+func (op maxPoolDiffOp) returnsPtr() bool    { return false }
+func (op maxPoolDiffOp) callsExtern() bool   { return false }
+func (op maxPoolDiffOp) overwriteInput() int { return -1 }
+
+func (op maxPoolDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("maxpoolDiff"))
+	op.window.WriteHash(h)
+}
+
+func (op maxPoolDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op maxPoolDiffOp) String() string { return fmt.Sprintf("MaxPoolDiff%v", op.window) }
+
+// argmax finds the flat index into the image of the first maximum of each window of a max pool with output shape s.
+// The index is -1 if the window only covers padding.
+func (w window) argmax(im types.Tensor, s types.Shape) (retVal []int, err error) {
+	n, c, h, wd := im.Shape()[0], im.Shape()[1], im.Shape()[2], im.Shape()[3]
+	ohw := s[2] * s[3]
+
+	retVal = make([]int, s.TotalSize())
+	for i := range retVal {
+		retVal[i] = -1
+	}
+
+	switch data := im.Data().(type) {
+	case []float64:
+		w.walk(n, c, h, wd, func(b, ch, o, k, in int) {
+			idx := (b*c+ch)*ohw + o
+			if in >= 0 && (retVal[idx] < 0 || data[in] > data[retVal[idx]]) {
+				retVal[idx] = in
+			}
+		})
+	case []float32:
+		w.walk(n, c, h, wd, func(b, ch, o, k, in int) {
+			idx := (b*c+ch)*ohw + o
+			if in >= 0 && (retVal[idx] < 0 || data[in] > data[retVal[idx]]) {
+				retVal[idx] = in
+			}
+		})
+	default:
+		err = nyi("argmax", im.Dtype())
+	}
+	return
+}
+
+// maxPoolFwdDiffOp picks the tangent of the first maximum of each window, which is the forward derivative of a max pool.
+// It takes the input of the max pool and its tangent.
+type maxPoolFwdDiffOp struct {
+	window
+}
+
+// maxPoolFwdDiffOp has this type:
+//		op :: Tensor 4 a → Tensor 4 a → Tensor 4 a
+func (op maxPoolFwdDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	tt := newTensorType(4, a)
+	return newFunctionType(tt, tt, tt)
+}
+
+func (op maxPoolFwdDiffOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "maxPoolFwdDiffOp takes two inputs. Got %d instead", len(inputs))
+		return
+	}
+	return op.outShape(inputs[0].shape)
+}
+
+func (op maxPoolFwdDiffOp) DiffWRT(i int) []bool { return []bool{true, true} }
+
+// SymDiff differentiates the picking of the tangents. It is piecewise constant in the image, and linear in the tangent:
+// its transpose is maxPoolDiffOp.
+func (op maxPoolFwdDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "maxPoolFwdDiffOp takes two inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	retVal = make(Nodes, 2)
+	if retVal[0], err = zeroGrad(inputs[0]); err != nil {
+		return
+	}
+	if retVal[1], err = applyOp(maxPoolDiffOp{op.window}, inputs[0], gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[1].setGroup(gradClust)
+	return
+}
+
+func (op maxPoolFwdDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "maxPoolFwdDiffOp takes two inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var im, tangent types.Tensor
+	if im, err = imageOf(op, inputs[0]); err != nil {
+		return
+	}
+	if tangent, err = imageOf(op, inputs[1]); err != nil {
+		return
+	}
+	if !im.Shape().Eq(tangent.Shape()) {
+		err = NewError(ShapeError, "maxPoolFwdDiffOp expected a tangent of %v. Got %v instead", im.Shape(), tangent.Shape())
+		return
+	}
+
+	var s types.Shape
+	if s, err = op.outShape(im.Shape()); err != nil {
+		return
+	}
+
+	var argmax []int
+	if argmax, err = op.argmax(im, s); err != nil {
+		return
+	}
+
+	switch dx := tangent.Data().(type) {
+	case []float64:
+		t := tf64.NewTensor(tf64.WithShape(s...))
+		dy := t.Data().([]float64)
+		for i, in := range argmax {
+			if in >= 0 {
+				dy[i] = dx[in]
+			}
+		}
+		retVal = FromTensor(t)
+	case []float32:
+		t := tf32.NewTensor(tf32.WithShape(s...))
+		dy := t.Data().([]float32)
+		for i, in := range argmax {
+			if in >= 0 {
+				dy[i] = dx[in]
+			}
+		}
+		retVal = FromTensor(t)
+	default:
+		err = nyi("maxPoolFwdDiffOp", tangent.Dtype())
+	}
+	return
+}
+
+func (op maxPoolFwdDiffOp) returnsPtr() bool    { return false }
+func (op maxPoolFwdDiffOp) callsExtern() bool   { return false }
+func (op maxPoolFwdDiffOp) overwriteInput() int { return -1 }
+
+func (op maxPoolFwdDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("maxpoolFwdDiff"))
+	op.window.WriteHash(h)
+}
+
+func (op maxPoolFwdDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op maxPoolFwdDiffOp) String() string { return fmt.Sprintf("MaxPoolFwdDiff%v", op.window) }
+
+// avgPoolOp averages each window of an image. Padding counts as zeroes.
+type avgPoolOp struct {
+	window
+}
+
+func (op avgPoolOp) Type() Type { return windowOpType() }
+
+func (op avgPoolOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+	return op.outShape(inputs[0].shape)
+}
+
+func (op avgPoolOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op avgPoolOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	s := inputs[0].shape
+	var n *Node
+	if n, err = applyOp(avgPoolDiffOp{op.window, s[2], s[3]}, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal = Nodes{n}
+	return
+}
+
+// FwdDiff averages the tangent.
+func (op avgPoolOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op avgPoolOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	s := inputs[0].shape
+	diff := avgPoolDiffOp{op.window, s[2], s[3]}
+	ydv := output.boundTo.(*dualValue)
+
+	var d Value
+	if d, err = diff.Do(ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+func (op avgPoolOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var im types.Tensor
+	if im, err = imageOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var s types.Shape
+	if s, err = op.outShape(im.Shape()); err != nil {
+		return
+	}
+	n, c, h, w := im.Shape()[0], im.Shape()[1], im.Shape()[2], im.Shape()[3]
+	ohw := s[2] * s[3]
+	kk := float64(op.kh * op.kw)
+
+	switch data := im.Data().(type) {
+	case []float64:
+		t := tf64.NewTensor(tf64.WithShape(s...))
+		out := t.Data().([]float64)
+		op.walk(n, c, h, w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				out[(b*c+ch)*ohw+o] += data[in] / kk
+			}
+		})
+		retVal = FromTensor(t)
+	case []float32:
+		t := tf32.NewTensor(tf32.WithShape(s...))
+		out := t.Data().([]float32)
+		op.walk(n, c, h, w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				out[(b*c+ch)*ohw+o] += data[in] / float32(kk)
+			}
+		})
+		retVal = FromTensor(t)
+	default:
+		err = nyi("avgPoolOp", im.Dtype())
+	}
+	return
+}
+
+func (op avgPoolOp) returnsPtr() bool    { return false }
+func (op avgPoolOp) callsExtern() bool   { return false }
+func (op avgPoolOp) overwriteInput() int { return -1 }
+
+func (op avgPoolOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("avgpool"))
+	op.window.WriteHash(h)
+}
+
+func (op avgPoolOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op avgPoolOp) String() string { return fmt.Sprintf("AvgPool%v", op.window) }
+
+// avgPoolDiffOp spreads the gradient of an average pool evenly over each window.
+// It is the adjoint of avgPoolOp, so its own gradient is an avgPoolOp.
+type avgPoolDiffOp struct {
+	window
+	h, w int
+}
+
+func (op avgPoolDiffOp) Type() Type { return windowOpType() }
+
+func (op avgPoolDiffOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolDiffOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	s := inputs[0].shape
+	if len(s) != 4 {
+		err = NewError(ShapeError, "avgPoolDiffOp expects a 4D input. Got %v instead", s)
+		return
+	}
+	return types.Shape{s[0], s[1], op.h, op.w}, nil
+}
+
+func (op avgPoolDiffOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op avgPoolDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolDiffOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var n *Node
+	if n, err = applyOp(avgPoolOp{op.window}, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal = Nodes{n}
+	return
+}
+
+func (op avgPoolDiffOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolDiffOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	diff := avgPoolOp{op.window}
+	ydv := output.boundTo.(*dualValue)
+
+	var d Value
+	if d, err = diff.Do(ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+func (op avgPoolDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "avgPoolDiffOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var grad types.Tensor
+	if grad, err = imageOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	s := grad.Shape()
+	n, c := s[0], s[1]
+	oh, ow := op.outSize(op.h, op.w)
+	if s[2] != oh || s[3] != ow {
+		err = NewError(ShapeError, "avgPoolDiffOp expected an input of (%d, %d, %d, %d). Got %v instead", n, c, oh, ow, s)
+		return
+	}
+	ohw := oh * ow
+	kk := float64(op.kh * op.kw)
+
+	switch data := grad.Data().(type) {
+	case []float64:
+		t := tf64.NewTensor(tf64.WithShape(n, c, op.h, op.w))
+		dx := t.Data().([]float64)
+		op.walk(n, c, op.h, op.w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				dx[in] += data[(b*c+ch)*ohw+o] / kk
+			}
+		})
+		retVal = FromTensor(t)
+	case []float32:
+		t := tf32.NewTensor(tf32.WithShape(n, c, op.h, op.w))
+		dx := t.Data().([]float32)
+		op.walk(n, c, op.h, op.w, func(b, ch, o, k, in int) {
+			if in >= 0 {
+				dx[in] += data[(b*c+ch)*ohw+o] / float32(kk)
+			}
+		})
+		retVal = FromTensor(t)
+	default:
+		err = nyi("avgPoolDiffOp", grad.Dtype())
+	}
+	return
+}
+
+func (op avgPoolDiffOp) returnsPtr() bool    { return false }
+func (op avgPoolDiffOp) callsExtern() bool   { return false }
+func (op avgPoolDiffOp) overwriteInput() int { return -1 }
+
+func (op avgPoolDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("avgpoolDiff"))
+	op.window.WriteHash(h)
+	fmt.Fprintf(h, "%d,%d", op.h, op.w)
+}
+
+func (op avgPoolDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op avgPoolDiffOp) String() string { return fmt.Sprintf("AvgPoolDiff%v", op.window) }
+
+/* SOFTMAX CROSS ENTROPY */
+
+// softmaxXentOp computes the cross entropy between softmax(logits) and the targets for each example (row) of the logits.
+// The targets are either Int class labels (sparse), or a Float tensor of the same shape as the logits (e.g. one-hot vectors).
+// The classes are along the last axis of the logits.
+type softmaxXentOp struct {
+	shape  types.Shape // shape of the logits
+	sparse bool
+}
+
+func newSoftmaxXentOp(logits types.Shape, sparse bool) softmaxXentOp {
+	return softmaxXentOp{
+		shape:  logits.Clone(),
+		sparse: sparse,
+	}
+}
+
+func (op softmaxXentOp) classAxis() axes { return axes{len(op.shape) - 1} }
+
+func (op softmaxXentOp) targetType(a Type) Type {
+	if op.sparse {
+		return indicesType(reduceShape(op.shape, op.classAxis()))
+	}
+	return newTensorType(op.shape.Dims(), a)
+}
+
+// softmaxXentOp has these types:
+//		softmaxXent :: (Floats a) ⇒ Tensor d a → Tensor d-1 Int → Tensor d-1 a
+//		softmaxXent :: (Floats a) ⇒ Tensor d a → Tensor d a → Tensor d-1 a
+// with a scalar in place of Tensor 0
+func (op softmaxXentOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	d := op.shape.Dims()
+	return newFunctionType(newTensorType(d, a), op.targetType(a), reductionRetType(d, op.classAxis(), op.shape, a))
+}
+
+func (op softmaxXentOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "softmaxXentOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+	return reduceShape(op.shape, op.classAxis()), nil
+}
+
+func (op softmaxXentOp) DiffWRT(inputs int) []bool { return []bool{true, !op.sparse} }
+
+func (op softmaxXentOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "softmaxXentOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	retVal = make(Nodes, 2)
+	diff := softmaxXentDiffOp{op}
+	if retVal[0], err = applyOp(diff, inputs[0], inputs[1], gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+
+	if op.sparse {
+		return
+	}
+
+	// the gradient wrt the targets is -logsoftmax(logits)
+	var lsm, g *Node
+	if lsm, err = LogSoftMax(inputs[0], op.classAxis()[0]); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if lsm, err = Neg(lsm); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if g, err = keepDims(gradNode, op.shape, op.classAxis()); err != nil {
+		return
+	}
+	if retVal[1], err = HadamardProd(lsm, g); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[1].setGroup(gradClust)
+	return
+}
+
+// FwdDiff sums the tangents over the classes. The derivatives of each loss wrt its logits are what the gradient of the logits is
+// when the gradient of the losses is one, and those wrt its targets are -logsoftmax(logits).
+func (op softmaxXentOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "softmaxXentOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var dx, dt *Node
+	if tangents[0] != nil {
+		if dx, err = gradFwdDiff(softmaxXentDiffOp{op}, output, tangents[0], op.classAxis(), inputs[0], inputs[1]); err != nil {
+			return
+		}
+	}
+	if !op.sparse && tangents[1] != nil {
+		var lsm *Node
+		if lsm, err = LogSoftMax(inputs[0], op.classAxis()[0]); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		if lsm, err = Neg(lsm); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		if dt, err = reductionFwdDiff(lsm, tangents[1], op.classAxis()); err != nil {
+			return
+		}
+	}
+	return addTangents(dx, dt)
+}
+
+func (op softmaxXentOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "softmaxXentOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	diff := softmaxXentDiffOp{op}
+	var d Value
+	if d, err = diff.Do(xdv.Value, inputs[1].Value(), ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	if err = addInto(inputs[0], d); err != nil {
+		return
+	}
+
+	if op.sparse {
+		return
+	}
+
+	var x types.Tensor
+	if x, err = tensorOf(op, xdv.Value); err != nil {
+		return
+	}
+
+	var lse, dy interface{}
+	var idx []int
+	if lse, idx, err = logSumExp(op, x, op.classAxis()[0]); err != nil {
+		return
+	}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	tdv := inputs[1].boundTo.(*dualValue)
+	return accumulateDeriv(tdv, func(d interface{}) error {
+		switch dt := d.(type) {
+		case []float64:
+			xs, lses, dys := x.Data().([]float64), lse.([]float64), dy.([]float64)
+			for i, o := range idx {
+				dt[i] += (lses[o] - xs[i]) * dys[o]
+			}
+		case []float32:
+			xs, lses, dys := x.Data().([]float32), lse.([]float32), dy.([]float32)
+			for i, o := range idx {
+				dt[i] += (lses[o] - xs[i]) * dys[o]
+			}
+		default:
+			return nyi("softmaxXentOp.DoDiff", d)
+		}
+		return nil
+	})
+}
+
+func (op softmaxXentOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "softmaxXentOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var loss interface{}
+	if loss, _, err = op.do(inputs[0], inputs[1], nil); err != nil {
+		return
+	}
+	return reducedValue(loss, reduceShape(op.shape, op.classAxis()))
+}
+
+// do computes the loss of each example. If dy is not nil, the gradient wrt the logits is computed as well:
+//		dlogits = dy * (Σtargets * softmax(logits) - targets)
+// which, for one-hot targets or labels, is dy * (softmax(logits) - onehot(labels))
+func (op softmaxXentOp) do(logits, targets Value, dy interface{}) (loss, dx interface{}, err error) {
+	var x types.Tensor
+	if x, err = tensorOf(op, logits); err != nil {
+		return
+	}
+
+	var lse interface{}
+	if lse, _, err = logSumExp(op, x, op.classAxis()[0]); err != nil {
+		return
+	}
+
+	classes := op.shape[len(op.shape)-1]
+	rows := x.DataSize() / classes
+
+	var labels []int
+	var dense interface{}
+	if op.sparse {
+		if labels, err = indicesOf(op, targets, classes); err != nil {
+			return
+		}
+		if len(labels) != rows {
+			err = NewError(ShapeError, "%v expects %d labels. Got %d instead", op, rows, len(labels))
+			return
+		}
+	} else {
+		if dense, err = valueData(targets); err != nil {
+			return
+		}
+	}
+
+	switch xs := x.Data().(type) {
+	case []float64:
+		lses := lse.([]float64)
+		var ts, dys, dxs []float64
+		if !op.sparse {
+			var ok bool
+			if ts, ok = dense.([]float64); !ok || len(ts) != len(xs) {
+				err = NewError(TypeError, "%v expects the targets to be %d float64s", op, len(xs))
+				return
+			}
+		}
+		if dy != nil {
+			dys = dy.([]float64)
+			dxs = make([]float64, len(xs))
+		}
+
+		losses := make([]float64, rows)
+		for r := 0; r < rows; r++ {
+			row := xs[r*classes : (r+1)*classes]
+			if op.sparse {
+				losses[r] = lses[r] - row[labels[r]]
+				if dxs != nil {
+					for c, v := range row {
+						dxs[r*classes+c] = math.Exp(v-lses[r]) * dys[r]
+					}
+					dxs[r*classes+labels[r]] -= dys[r]
+				}
+				continue
+			}
+
+			t := ts[r*classes : (r+1)*classes]
+			var sum float64
+			for c, v := range row {
+				losses[r] += t[c] * (lses[r] - v)
+				sum += t[c]
+			}
+			if dxs != nil {
+				for c, v := range row {
+					dxs[r*classes+c] = (sum*math.Exp(v-lses[r]) - t[c]) * dys[r]
+				}
+			}
+		}
+		loss = losses
+		if dxs != nil {
+			dx = dxs
+		}
+	case []float32:
+		lses := lse.([]float32)
+		var ts, dys, dxs []float32
+		if !op.sparse {
+			var ok bool
+			if ts, ok = dense.([]float32); !ok || len(ts) != len(xs) {
+				err = NewError(TypeError, "%v expects the targets to be %d float32s", op, len(xs))
+				return
+			}
+		}
+		if dy != nil {
+			dys = dy.([]float32)
+			dxs = make([]float32, len(xs))
+		}
+
+		losses := make([]float32, rows)
+		for r := 0; r < rows; r++ {
+			row := xs[r*classes : (r+1)*classes]
+			if op.sparse {
+				losses[r] = lses[r] - row[labels[r]]
+				if dxs != nil {
+					for c, v := range row {
+						dxs[r*classes+c] = float32(math.Exp(float64(v-lses[r]))) * dys[r]
+					}
+					dxs[r*classes+labels[r]] -= dys[r]
+				}
+				continue
+			}
+
+			t := ts[r*classes : (r+1)*classes]
+			var sum float32
+			for c, v := range row {
+				losses[r] += t[c] * (lses[r] - v)
+				sum += t[c]
+			}
+			if dxs != nil {
+				for c, v := range row {
+					dxs[r*classes+c] = (sum*float32(math.Exp(float64(v-lses[r]))) - t[c]) * dys[r]
+				}
+			}
+		}
+		loss = losses
+		if dxs != nil {
+			dx = dxs
+		}
+	default:
+		err = nyi("softmaxXentOp", x.Dtype())
+	}
+	return
+}
+
+func (op softmaxXentOp) returnsPtr() bool    { return false }
+func (op softmaxXentOp) callsExtern() bool   { return false }
+func (op softmaxXentOp) overwriteInput() int { return -1 }
+
+func (op softmaxXentOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("softmaxXent"))
+	fmt.Fprintf(h, "%v,%t", op.shape, op.sparse)
+}
+
+func (op softmaxXentOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op softmaxXentOp) String() string { return "SoftmaxXent" }
+
+// softmaxXentDiffOp is the gradient of softmaxXentOp wrt the logits. It takes the logits, the targets and the gradient of the loss.
+type softmaxXentDiffOp struct {
+	softmaxXentOp
+}
+
+// softmaxXentDiffOp is a function with this type:
+//		softmaxXentDiff :: (Floats a) ⇒ Tensor d a → t → Tensor d-1 a → Tensor d a
+// where t is the type of the targets
+func (op softmaxXentDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	d := op.shape.Dims()
+	t := newTensorType(d, a)
+	return newFunctionType(t, op.targetType(a), reductionRetType(d, op.classAxis(), op.shape, a), t)
+}
+
+func (op softmaxXentDiffOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "softmaxXentDiffOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+	return op.shape.Clone(), nil
+}
+
+func (op softmaxXentDiffOp) DiffWRT(inputs int) []bool { return []bool{true, !op.sparse, true} }
+
+// SymDiff differentiates the gradient of the loss, g (T softmax(x) - t), where T is the sum of the targets (1 if they are sparse).
+// The Jacobian of the softmax p is diag(p) - p pᵀ.
+func (op softmaxXentDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "softmaxXentDiffOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	x, t, g := inputs[0], inputs[1], inputs[2]
+	along := op.classAxis()
+	retVal = make(Nodes, 3)
+	if retVal[2], err = gradFwdDiff(op, g, gradNode, along, x, t); err != nil {
+		return
+	}
+	retVal[2].setGroup(gradClust)
+
+	var p, ph, sum, gk *Node
+	if p, err = LogSoftMax(x, along[0]); err == nil {
+		if p, err = Exp(p); err == nil {
+			if ph, err = HadamardProd(p, gradNode); err == nil {
+				sum, err = Sum(ph, along...)
+			}
+		}
+	}
+	if err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if sum, err = keepDims(sum, op.shape, along); err != nil {
+		return
+	}
+	if gk, err = keepDims(g, op.shape, along); err != nil {
+		return
+	}
+
+	var jh *Node
+	if jh, err = HadamardProd(p, sum); err == nil {
+		if jh, err = Sub(ph, jh); err == nil {
+			retVal[0], err = HadamardProd(gk, jh)
+		}
+	}
+	if err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	if op.sparse {
+		retVal[0].setGroup(gradClust)
+		return
+	}
+
+	var total *Node
+	if total, err = Sum(t, along...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if total, err = keepDims(total, op.shape, along); err != nil {
+		return
+	}
+	if retVal[0], err = HadamardProd(total, retVal[0]); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+
+	if retVal[1], err = Sub(sum, gradNode); err == nil {
+		retVal[1], err = HadamardProd(gk, retVal[1])
+	}
+	if err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[1].setGroup(gradClust)
+	return
+}
+
+func (op softmaxXentDiffOp) DoDiff(inputs Nodes, output *Node) error { return nondiffErr(op) }
+
+func (op softmaxXentDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "softmaxXentDiffOp expects 3 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var dy, dx interface{}
+	if dy, err = valueData(inputs[2]); err != nil {
+		return
+	}
+	if _, dx, err = op.do(inputs[0], inputs[1], dy); err != nil {
+		return
+	}
+
+	switch d := dx.(type) {
+	case []float64:
+		retVal = FromTensor(tf64.NewTensor(tf64.WithShape(op.shape...), tf64.WithBacking(d)))
+	case []float32:
+		retVal = FromTensor(tf32.NewTensor(tf32.WithShape(op.shape...), tf32.WithBacking(d)))
+	}
+	return
+}
+
+func (op softmaxXentDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("softmaxXentDiff"))
+	fmt.Fprintf(h, "%v,%t", op.shape, op.sparse)
+}
+
+func (op softmaxXentDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op softmaxXentDiffOp) String() string { return "SoftmaxXentDiff" }
+
+/* NORMALIZATION */
+
+// BatchNormStats holds the running means and variances of the features normalized by a BatchNorm.
+// In training mode they are updated by every forward pass. In inference mode they are used in place of the statistics of the batch.
+type BatchNormStats struct {
+	mean, variance []float64
+	momentum       float64
+	dt             Dtype
+
+	training bool
+}
+
+func newBatchNormStats(dt Dtype, features int, momentum float64) *BatchNormStats {
+	s := &BatchNormStats{
+		mean:     make([]float64, features),
+		variance: make([]float64, features),
+		momentum: momentum,
+		dt:       dt,
+		training: true,
+	}
+	s.Reset()
+	return s
+}
+
+// Mean returns a copy of the running means
+func (s *BatchNormStats) Mean() Value { return s.value(s.mean) }
+
+// Var returns a copy of the running variances
+func (s *BatchNormStats) Var() Value { return s.value(s.variance) }
+
+// Reset sets the running means to 0 and the running variances to 1
+func (s *BatchNormStats) Reset() {
+	for i := range s.mean {
+		s.mean[i] = 0
+		s.variance[i] = 1
+	}
+}
+
+func (s *BatchNormStats) value(data []float64) Value {
+	if s.dt == Float32 {
+		return FromTensor(tf32.NewTensor(tf32.WithShape(len(data)), tf32.WithBacking(f64sTof32s(data))))
+	}
+	return FromTensor(tf64.NewTensor(tf64.WithShape(len(data)), tf64.WithBacking(append([]float64{}, data...))))
+}
+
+// update folds the statistics of a batch into the running statistics. The variances of the batch are those of a population,
+// so they are corrected with n/(n-1), where n is the number of elements that each feature was computed over.
+func (s *BatchNormStats) update(means, variances []float64, n int) {
+	correction := 1.0
+	if n > 1 {
+		correction = float64(n) / float64(n-1)
+	}
+
+	m := s.momentum
+	for i := range s.mean {
+		s.mean[i] = m*s.mean[i] + (1-m)*means[i]
+		s.variance[i] = m*s.variance[i] + (1-m)*variances[i]*correction
+	}
+}
+
+// normalizeOp normalizes its input to a mean of 0 and a variance of 1 over the axes in along, then scales and shifts it:
+//		y = scale * (x - mean) / √(var + eps) + bias
+// The scale and bias span the axes in params.
+//
+// Batch normalization computes the statistics over every axis but the features (axis 1), and spans the features.
+// It keeps running statistics, which are used instead of those of the batch in inference mode.
+// Layer normalization computes the statistics over the same axes that the scale and bias span.
+type normalizeOp struct {
+	along  axes
+	params axes
+	eps    float64
+
+	inputShape types.Shape
+
+	stats *BatchNormStats // only batch normalization has running statistics
+}
+
+// normalizeOp is a function with this type:
+//		normalizeOp :: (Floats a) ⇒ Tensor d a → Tensor p a → Tensor p a → Tensor d a
+// where p is the number of axes the scale and bias span
+func (op normalizeOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(len(op.inputShape), a)
+	p := newTensorType(len(op.params), a)
+	return newFunctionType(t, p, p, t)
+}
+
+func (op normalizeOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+	return op.inputShape.Clone(), nil
+}
+
+func (op normalizeOp) DiffWRT(inputs int) []bool { return []bool{true, true, true} }
+
+func (op normalizeOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+
+	retVal = make(Nodes, 3)
+	for i := range retVal {
+		diff := normalizeDiffOp{normalizeOp: op, wrt: i}
+		if retVal[i], err = applyOp(diff, inputs[0], inputs[1], gradNode); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		retVal[i].setGroup(gradClust)
+	}
+	return
+}
+
+func (op normalizeOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+
+	children := Nodes{inputs[0], inputs[1], nil, nil, nil}
+	for i, t := range tangents {
+		if children[i+2] = t; t == nil {
+			if children[i+2], err = zeroesLike(inputs[i]); err != nil {
+				return
+			}
+		}
+	}
+	if retVal, err = applyOp(normalizeFwdDiffOp{op}, children...); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+func (op normalizeOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	sdv := inputs[1].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var grads []Value
+	if grads, err = op.grads(xdv.Value, sdv.Value, ydv.d); err != nil {
+		return
+	}
+	for i, d := range grads {
+		if err = addInto(inputs[i], d); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (op normalizeOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+
+	var x, scale, bias []float64
+	if x, err = op.floatsOf(inputs[0], op.inputShape.TotalSize()); err != nil {
+		return
+	}
+	if scale, err = op.floatsOf(inputs[1], op.paramShape().TotalSize()); err != nil {
+		return
+	}
+	if bias, err = op.floatsOf(inputs[2], op.paramShape().TotalSize()); err != nil {
+		return
+	}
+
+	idx, groups := reductionIndices(op.inputShape, op.along)
+	pidx := op.paramIndices()
+	means, invstds := op.moments(x, idx, groups, true)
+
+	y := make([]float64, len(x))
+	for i, v := range x {
+		g, p := idx[i], pidx[i]
+		y[i] = scale[p]*(v-means[g])*invstds[g] + bias[p]
+	}
+	return op.valueLike(inputs[0], y, op.inputShape)
+}
+
+func (op normalizeOp) returnsPtr() bool    { return false }
+func (op normalizeOp) callsExtern() bool   { return false }
+func (op normalizeOp) overwriteInput() int { return -1 }
+
+func (op normalizeOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("normalize"))
+	fmt.Fprintf(h, "%v,%v,%v,%v,%p", op.along, op.params, op.eps, op.inputShape, op.stats)
+}
+
+func (op normalizeOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op normalizeOp) String() string {
+	if op.stats != nil {
+		return "BatchNorm"
+	}
+	return fmt.Sprintf("LayerNorm%v", op.along)
+}
+
+func (op normalizeOp) setTraining(training bool) {
+	if op.stats != nil {
+		op.stats.training = training
+	}
+}
+
+// batchStats reports whether the statistics are those of the input, rather than the running statistics
+func (op normalizeOp) batchStats() bool { return op.stats == nil || op.stats.training }
+
+func (op normalizeOp) paramShape() types.Shape {
+	retVal := make(types.Shape, len(op.params))
+	for i, a := range op.params {
+		retVal[i] = op.inputShape[a]
+	}
+	return retVal
+}
+
+// paramIndices maps each element of the input to the element of the scale and bias that it is multiplied and shifted by
+func (op normalizeOp) paramIndices() []int {
+	var others axes
+	for i := range op.inputShape {
+		var spanned bool
+		for _, a := range op.params {
+			if a == i {
+				spanned = true
+				break
+			}
+		}
+		if !spanned {
+			others = append(others, i)
+		}
+	}
+	idx, _ := reductionIndices(op.inputShape, others)
+	return idx
+}
+
+// moments returns the mean and the reciprocal of the standard deviation of each group. If the running statistics are not used,
+// they are computed from x, and the running statistics (if any) are updated when update is set.
+func (op normalizeOp) moments(x []float64, idx []int, groups int, update bool) (means, invstds []float64) {
+	means = make([]float64, groups)
+	invstds = make([]float64, groups)
+	if !op.batchStats() {
+		for g := range means {
+			means[g] = op.stats.mean[g]
+			invstds[g] = 1 / math.Sqrt(op.stats.variance[g]+op.eps)
+		}
+		return
+	}
+
+	n := len(x) / groups
+	for i, v := range x {
+		means[idx[i]] += v
+	}
+	for g := range means {
+		means[g] /= float64(n)
+	}
+
+	variances := make([]float64, groups)
+	for i, v := range x {
+		d := v - means[idx[i]]
+		variances[idx[i]] += d * d
+	}
+	for g := range variances {
+		variances[g] /= float64(n)
+		invstds[g] = 1 / math.Sqrt(variances[g]+op.eps)
+	}
+
+	if update && op.stats != nil {
+		op.stats.update(means, variances, n)
+	}
+	return
+}
+
+// grads computes the gradients wrt the input, the scale and the bias in one pass, given the gradient of the output.
+// With x̂ = (x - mean)/σ and dx̂ = scale * dy, the gradients are
+//		dscale = Σdy x̂ and dbias = Σdy, summed over the elements that share a scale and bias
+//		dx = (N dx̂ - Σdx̂ - x̂ Σdx̂ x̂) / Nσ, summed over the N elements of each group
+// When the running statistics are used, the statistics don't depend on x, and dx is just dx̂/σ.
+func (op normalizeOp) grads(xv, scalev, dyv Value) (retVal []Value, err error) {
+	var x, scale, dy []float64
+	if x, err = op.floatsOf(xv, op.inputShape.TotalSize()); err != nil {
+		return
+	}
+	ps := op.paramShape()
+	if scale, err = op.floatsOf(scalev, ps.TotalSize()); err != nil {
+		return
+	}
+	if dy, err = op.floatsOf(dyv, op.inputShape.TotalSize()); err != nil {
+		return
+	}
+
+	idx, groups := reductionIndices(op.inputShape, op.along)
+	pidx := op.paramIndices()
+	means, invstds := op.moments(x, idx, groups, false)
+	n := float64(len(x) / groups)
+
+	xhat := make([]float64, len(x))
+	sums := make([]float64, groups)
+	dots := make([]float64, groups)
+	dscale := make([]float64, len(scale))
+	dbias := make([]float64, len(scale))
+	for i, v := range x {
+		g, p := idx[i], pidx[i]
+		xhat[i] = (v - means[g]) * invstds[g]
+		dxhat := dy[i] * scale[p]
+		sums[g] += dxhat
+		dots[g] += dxhat * xhat[i]
+		dscale[p] += dy[i] * xhat[i]
+		dbias[p] += dy[i]
+	}
+
+	batch := op.batchStats()
+	dx := make([]float64, len(x))
+	for i := range x {
+		g := idx[i]
+		dxhat := dy[i] * scale[pidx[i]]
+		if batch {
+			dx[i] = invstds[g] / n * (n*dxhat - sums[g] - xhat[i]*dots[g])
+		} else {
+			dx[i] = dxhat * invstds[g]
+		}
+	}
+
+	retVal = make([]Value, 3)
+	if retVal[0], err = op.valueLike(xv, dx, op.inputShape); err != nil {
+		return
+	}
+	if retVal[1], err = op.valueLike(xv, dscale, ps); err != nil {
+		return
+	}
+	retVal[2], err = op.valueLike(xv, dbias, ps)
+	return
+}
+
+// tangent computes the tangent of the output in one pass, given the tangents of the input, the scale and the bias.
+// With x̂ = (x - mean)/σ, the tangent is
+//		dy = scale * dx̂ + dscale * x̂ + dbias
+//		dx̂ = (N dx - Σdx - x̂ Σdx x̂) / Nσ, summed over the N elements of each group
+// When the running statistics are used, dx̂ is just dx/σ.
+func (op normalizeOp) tangent(xv, scalev, dxv, dscalev, dbiasv Value) (retVal Value, err error) {
+	ps := op.paramShape()
+	var x, scale, dx, dscale, dbias []float64
+	if x, err = op.floatsOf(xv, op.inputShape.TotalSize()); err != nil {
+		return
+	}
+	if scale, err = op.floatsOf(scalev, ps.TotalSize()); err != nil {
+		return
+	}
+	if dx, err = op.floatsOf(dxv, op.inputShape.TotalSize()); err != nil {
+		return
+	}
+	if dscale, err = op.floatsOf(dscalev, ps.TotalSize()); err != nil {
+		return
+	}
+	if dbias, err = op.floatsOf(dbiasv, ps.TotalSize()); err != nil {
+		return
+	}
+
+	idx, groups := reductionIndices(op.inputShape, op.along)
+	pidx := op.paramIndices()
+	means, invstds := op.moments(x, idx, groups, false)
+	n := float64(len(x) / groups)
+
+	xhat := make([]float64, len(x))
+	sums := make([]float64, groups)
+	dots := make([]float64, groups)
+	for i, v := range x {
+		g := idx[i]
+		xhat[i] = (v - means[g]) * invstds[g]
+		sums[g] += dx[i]
+		dots[g] += dx[i] * xhat[i]
+	}
+
+	batch := op.batchStats()
+	dy := make([]float64, len(x))
+	for i := range x {
+		g, p := idx[i], pidx[i]
+		dxhat := dx[i] * invstds[g]
+		if batch {
+			dxhat = invstds[g] / n * (n*dx[i] - sums[g] - xhat[i]*dots[g])
+		}
+		dy[i] = scale[p]*dxhat + dscale[p]*xhat[i] + dbias[p]
+	}
+	return op.valueLike(xv, dy, op.inputShape)
+}
+
+// secondGrads computes the gradients of Σ g·grad wrt the input and the scale, where grad is the gradient wrt the input (wrt is 0)
+// or the scale (1) given the gradient of the output dy. grad is linear in dy, so with the Jacobian J of x̂ = (x - mean)/σ:
+//		wrt the input: Σ g·dx = uᵀJv, with u = g and v = scale * dy
+//		wrt the scale: Σ g·dscale = uᵀx̂, with u = g * dy
+// J = (I - 11ᵀ/N - x̂x̂ᵀ/N)/σ for each group of N elements is symmetric, and
+//		∂(uᵀJv)/∂x = -(x̂ uᵀJv + (vᵀx̂) Ju + (uᵀx̂) Jv) / Nσ and ∂(uᵀx̂)/∂x = Ju
+// When the running statistics are used, J is just I/σ, which doesn't depend on x.
+func (op normalizeOp) secondGrads(wrt int, xv, scalev, dyv, gv Value) (retVal []Value, err error) {
+	ps := op.paramShape()
+	gs := ps
+	if wrt == 0 {
+		gs = op.inputShape
+	}
+
+	var x, scale, dy, g []float64
+	if x, err = op.floatsOf(xv, op.inputShape.TotalSize()); err != nil {
+		return
+	}
+	if scale, err = op.floatsOf(scalev, ps.TotalSize()); err != nil {
+		return
+	}
+	if dy, err = op.floatsOf(dyv, op.inputShape.TotalSize()); err != nil {
+		return
+	}
+	if g, err = op.floatsOf(gv, gs.TotalSize()); err != nil {
+		return
+	}
+
+	idx, groups := reductionIndices(op.inputShape, op.along)
+	pidx := op.paramIndices()
+	means, invstds := op.moments(x, idx, groups, false)
+	n := float64(len(x) / groups)
+
+	xhat := make([]float64, len(x))
+	u := make([]float64, len(x))
+	v := make([]float64, len(x))
+	for i, xi := range x {
+		grp, p := idx[i], pidx[i]
+		xhat[i] = (xi - means[grp]) * invstds[grp]
+		switch wrt {
+		case 0:
+			u[i], v[i] = g[i], scale[p]*dy[i]
+		case 1:
+			u[i] = g[p] * dy[i]
+		}
+	}
+
+	dx := make([]float64, len(x))
+	dscale := make([]float64, len(scale))
+	ju := op.jacobianProd(xhat, u, idx, invstds, groups)
+	switch wrt {
+	case 0:
+		for i := range x {
+			dscale[pidx[i]] += ju[i] * dy[i]
+		}
+		if !op.batchStats() {
+			break
+		}
+
+		jv := op.jacobianProd(xhat, v, idx, invstds, groups)
+		ujvs := make([]float64, groups)
+		uxs := make([]float64, groups)
+		vxs := make([]float64, groups)
+		for i := range x {
+			grp := idx[i]
+			ujvs[grp] += u[i] * jv[i]
+			uxs[grp] += u[i] * xhat[i]
+			vxs[grp] += v[i] * xhat[i]
+		}
+		for i := range x {
+			grp := idx[i]
+			dx[i] = -invstds[grp] / n * (xhat[i]*ujvs[grp] + vxs[grp]*ju[i] + uxs[grp]*jv[i])
+		}
+	case 1:
+		copy(dx, ju)
+	}
+
+	retVal = make([]Value, 2)
+	if retVal[0], err = op.valueLike(xv, dx, op.inputShape); err != nil {
+		return
+	}
+	retVal[1], err = op.valueLike(xv, dscale, ps)
+	return
+}
+
+// jacobianProd returns Jv, where J is the Jacobian of x̂ = (x - mean)/σ, and idx maps each element to its group
+func (op normalizeOp) jacobianProd(xhat, v []float64, idx []int, invstds []float64, groups int) []float64 {
+	retVal := make([]float64, len(v))
+	if !op.batchStats() {
+		for i := range v {
+			retVal[i] = v[i] * invstds[idx[i]]
+		}
+		return retVal
+	}
+
+	n := float64(len(v) / groups)
+	sums := make([]float64, groups)
+	dots := make([]float64, groups)
+	for i := range v {
+		sums[idx[i]] += v[i]
+		dots[idx[i]] += v[i] * xhat[i]
+	}
+	for i := range v {
+		g := idx[i]
+		retVal[i] = invstds[g] / n * (n*v[i] - sums[g] - xhat[i]*dots[g])
+	}
+	return retVal
+}
+
+// floatsOf returns the data of v as float64s, checking that there are size of them
+func (op normalizeOp) floatsOf(v Value, size int) (retVal []float64, err error) {
+	var data interface{}
+	if data, err = valueData(v); err != nil {
+		return
+	}
+
+	switch d := data.(type) {
+	case []float64:
+		retVal = d
+	case []float32:
+		retVal = f32sTof64s(d)
+	default:
+		err = nyi(op.String(), v.Dtype())
+		return
+	}
+
+	if len(retVal) != size {
+		err = NewError(ShapeError, "%v expected %d elements. Got %d instead", op, size, len(retVal))
+	}
+	return
+}
+
+// valueLike wraps data into a tensor of shape s, with the Dtype of like
+func (op normalizeOp) valueLike(like Value, data []float64, s types.Shape) (Value, error) {
+	if like.Dtype() == Float32 {
+		return reducedValue(f64sTof32s(data), s)
+	}
+	return reducedValue(data, s)
+}
+
+// normalizeDiffOp is the gradient of normalizeOp wrt one of its inputs: the input (wrt is 0), the scale (1) or the bias (2).
+// It takes the input, the scale and the gradient of the output.
+type normalizeDiffOp struct {
+	normalizeOp
+	wrt int
+}
+
+// normalizeDiffOp is a function with one of these types:
+//		normalizeDiff :: (Floats a) ⇒ Tensor d a → Tensor p a → Tensor d a → Tensor d a
+//		normalizeDiff :: (Floats a) ⇒ Tensor d a → Tensor p a → Tensor d a → Tensor p a
+// depending on whether the gradient is wrt the input, or the scale or bias
+func (op normalizeDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(len(op.inputShape), a)
+	p := newTensorType(len(op.params), a)
+	if op.wrt == 0 {
+		return newFunctionType(t, p, t, t)
+	}
+	return newFunctionType(t, p, t, p)
+}
+
+func (op normalizeDiffOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+	if op.wrt == 0 {
+		return op.inputShape.Clone(), nil
+	}
+	return op.paramShape(), nil
+}
+
+// DiffWRT states which inputs the gradient depends on: the gradient wrt the scale doesn't depend on the scale,
+// and the gradient wrt the bias only depends on the gradient of the output.
+func (op normalizeDiffOp) DiffWRT(inputs int) []bool { return []bool{op.wrt != 2, op.wrt == 0, true} }
+
+// SymDiff differentiates the gradient. It is linear in the gradient of the output, and its transpose is the tangent that
+// normalizeFwdDiffOp computes when the input the gradient is taken wrt is moved by gradNode. The derivatives wrt the input
+// and the scale are computed by normalizeDiffDiffOp.
+func (op normalizeDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+
+	x, scale, dy := inputs[0], inputs[1], inputs[2]
+	children := Nodes{x, scale, nil, nil, nil}
+	for i := range children[2:] {
+		switch {
+		case i == op.wrt:
+			children[i+2] = gradNode
+		case i == 0:
+			children[i+2], err = zeroesLike(x)
+		default:
+			children[i+2], err = zeroesLike(scale)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	retVal = make(Nodes, 3)
+	if retVal[2], err = applyOp(normalizeFwdDiffOp{op.normalizeOp}, children...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[2].setGroup(gradClust)
+
+	for i, diffable := range op.DiffWRT(3)[:2] {
+		if !diffable {
+			continue
+		}
+		if retVal[i], err = applyOp(normalizeDiffDiffOp{op, i}, x, scale, dy, gradNode); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		retVal[i].setGroup(gradClust)
+	}
+	return
+}
+
+func (op normalizeDiffOp) DoDiff(inputs Nodes, output *Node) error { return nondiffErr(op) }
+
+func (op normalizeDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "%v expects 3 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+
+	var grads []Value
+	if grads, err = op.grads(inputs[0], inputs[1], inputs[2]); err != nil {
+		return
+	}
+	return grads[op.wrt], nil
+}
+
+func (op normalizeDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("normalizeDiff"))
+	op.normalizeOp.WriteHash(h)
+	fmt.Fprintf(h, "wrt%d", op.wrt)
+}
+
+func (op normalizeDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op normalizeDiffOp) String() string { return fmt.Sprintf("%vDiff%d", op.normalizeOp, op.wrt) }
+
+// normalizeDiffDiffOp is the gradient of normalizeDiffOp wrt the input (of is 0) or the scale (1).
+// It takes the input, the scale, the gradient of the output and the gradient of the result of the normalizeDiffOp.
+type normalizeDiffDiffOp struct {
+	normalizeDiffOp
+	of int
+}
+
+// normalizeDiffDiffOp is a function with this type:
+//		normalizeDiffDiff :: (Floats a) ⇒ Tensor d a → Tensor p a → Tensor d a → b → c
+// where b is the type of the result of the normalizeDiffOp, and c is the type of the input or the scale
+func (op normalizeDiffDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(len(op.inputShape), a)
+	p := newTensorType(len(op.params), a)
+	g, r := p, p
+	if op.wrt == 0 {
+		g = t
+	}
+	if op.of == 0 {
+		r = t
+	}
+	return newFunctionType(t, p, t, g, r)
+}
+
+func (op normalizeDiffDiffOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 4 {
+		err = NewError(GraphError, "%v expects 4 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+	if op.of == 0 {
+		return op.inputShape.Clone(), nil
+	}
+	return op.paramShape(), nil
+}
+
+func (op normalizeDiffDiffOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op normalizeDiffDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	err = nondiffErr(op)
+	return
+}
+
+func (op normalizeDiffDiffOp) DoDiff(inputs Nodes, output *Node) error { return nondiffErr(op) }
+
+func (op normalizeDiffDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 4 {
+		err = NewError(GraphError, "%v expects 4 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+
+	var grads []Value
+	if grads, err = op.secondGrads(op.wrt, inputs[0], inputs[1], inputs[2], inputs[3]); err != nil {
+		return
+	}
+	return grads[op.of], nil
+}
+
+func (op normalizeDiffDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("normalizeDiffDiff"))
+	op.normalizeDiffOp.WriteHash(h)
+	fmt.Fprintf(h, "of%d", op.of)
+}
+
+func (op normalizeDiffDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op normalizeDiffDiffOp) String() string {
+	return fmt.Sprintf("%vDiff%d", op.normalizeDiffOp, op.of)
+}
+
+// normalizeFwdDiffOp is the forward derivative of normalizeOp. It takes the input, the scale, and the tangents of the input, the scale and the bias.
+type normalizeFwdDiffOp struct {
+	normalizeOp
+}
+
+// normalizeFwdDiffOp is a function with this type:
+//		normalizeFwdDiff :: (Floats a) ⇒ Tensor d a → Tensor p a → Tensor d a → Tensor p a → Tensor p a → Tensor d a
+func (op normalizeFwdDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(len(op.inputShape), a)
+	p := newTensorType(len(op.params), a)
+	return newFunctionType(t, p, t, p, p, t)
+}
+
+func (op normalizeFwdDiffOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 5 {
+		err = NewError(GraphError, "%v expects 5 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+	return op.inputShape.Clone(), nil
+}
+
+func (op normalizeFwdDiffOp) DiffWRT(inputs int) []bool { return make([]bool, inputs) }
+
+func (op normalizeFwdDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	err = nondiffErr(op)
+	return
+}
+
+func (op normalizeFwdDiffOp) DoDiff(inputs Nodes, output *Node) error { return nondiffErr(op) }
+
+func (op normalizeFwdDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 5 {
+		err = NewError(GraphError, "%v expects 5 inputs. Got %d instead", op, len(inputs))
+		return
+	}
+	return op.tangent(inputs[0], inputs[1], inputs[2], inputs[3], inputs[4])
+}
+
+func (op normalizeFwdDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("normalizeFwdDiff"))
+	op.normalizeOp.WriteHash(h)
+}
+
+func (op normalizeFwdDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op normalizeFwdDiffOp) String() string { return fmt.Sprintf("%vFwdDiff", op.normalizeOp) }
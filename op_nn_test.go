@@ -0,0 +1,555 @@
+package gorgonia
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	ti "github.com/chewxy/gorgonia/tensor/i"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveConv2d is a straightforward convolution used to check Conv2d
+func naiveConv2d(im []float64, is types.Shape, filter []float64, fs types.Shape, stride, pad int) (retVal []float64, s types.Shape) {
+	n, c, h, w := is[0], is[1], is[2], is[3]
+	f, kh, kw := fs[0], fs[2], fs[3]
+	oh := (h+2*pad-kh)/stride + 1
+	ow := (w+2*pad-kw)/stride + 1
+	s = types.Shape{n, f, oh, ow}
+	retVal = make([]float64, s.TotalSize())
+	for b := 0; b < n; b++ {
+		for k := 0; k < f; k++ {
+			for oy := 0; oy < oh; oy++ {
+				for ox := 0; ox < ow; ox++ {
+					var sum float64
+					for ch := 0; ch < c; ch++ {
+						for i := 0; i < kh; i++ {
+							for j := 0; j < kw; j++ {
+								y, x := oy*stride-pad+i, ox*stride-pad+j
+								if y < 0 || y >= h || x < 0 || x >= w {
+									continue
+								}
+								sum += im[((b*c+ch)*h+y)*w+x] * filter[((k*c+ch)*kh+i)*kw+j]
+							}
+						}
+					}
+					retVal[((b*f+k)*oh+oy)*ow+ox] = sum
+				}
+			}
+		}
+	}
+	return
+}
+
+func TestConv2d(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithName("x"), WithShape(1, 1, 3, 3), WithInit(RangedFrom(0)))
+	f := NewTensor(g, Float64, 4, WithName("f"), WithShape(1, 1, 2, 2), WithValue(tf64.Ones(1, 1, 2, 2)))
+	conv := Must(Conv2d(x, f, nil, nil, nil))
+	assert.Equal(types.Shape{1, 1, 2, 2}, conv.Shape())
+	cost := Must(Sum(Must(Reshape(conv, 4))))
+
+	if _, err := Grad(cost, x, f); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]float64{8, 12, 20, 24}, conv.Value().(Tensor).Tensor.Data())
+	xG, _ := x.Grad()
+	fG, _ := f.Grad()
+	assert.Equal([]float64{1, 2, 1, 2, 4, 2, 1, 2, 1}, xG.(Tensor).Tensor.Data())
+	assert.Equal([]float64{8, 12, 20, 24}, fG.(Tensor).Tensor.Data())
+
+	/* Lisp machine version */
+	g2 := NewGraph()
+	x = NewTensor(g2, Float64, 4, WithName("x"), WithShape(1, 1, 3, 3), WithInit(RangedFrom(0)))
+	f = NewTensor(g2, Float64, 4, WithName("f"), WithShape(1, 1, 2, 2), WithValue(tf64.Ones(1, 1, 2, 2)))
+	conv = Must(Conv2d(x, f, nil, nil, nil))
+	cost = Must(Sum(Must(Reshape(conv, 4))))
+
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	xG, _ = x.Grad()
+	fG, _ = f.Grad()
+	assert.Equal([]float64{1, 2, 1, 2, 4, 2, 1, 2, 1}, xG.(Tensor).Tensor.Data())
+	assert.Equal([]float64{8, 12, 20, 24}, fG.(Tensor).Tensor.Data())
+
+	// multiple channels and filters, with strides and padding
+	is, fs := types.Shape{2, 2, 5, 5}, types.Shape{3, 2, 3, 3}
+	imData := tf64.RangeFloat64(0, is.TotalSize())
+	filterData := tf64.RangeFloat64(0, fs.TotalSize())
+	correct, cs := naiveConv2d(imData, is, filterData, fs, 2, 1)
+
+	g3 := NewGraph()
+	x = NewTensor(g3, Float64, 4, WithName("x"), WithShape(is...), WithValue(tf64.NewTensor(tf64.WithShape(is...), tf64.WithBacking(imData))))
+	f = NewTensor(g3, Float64, 4, WithName("f"), WithShape(fs...), WithValue(tf64.NewTensor(tf64.WithShape(fs...), tf64.WithBacking(filterData))))
+	conv = Must(Conv2d(x, f, []int{2, 2}, []int{1, 1}, nil))
+	assert.Equal(cs, conv.Shape())
+
+	prog, locMap, err = Compile(g3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(correct, conv.Value().(Tensor).Tensor.Data())
+
+	// bad filters
+	_, err = Conv2d(x, NewTensor(g3, Float64, 4, WithName("bad"), WithShape(3, 1, 3, 3)), nil, nil, nil)
+	assert.NotNil(err)
+}
+
+func TestPool2D(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewTensor(g, Float64, 4, WithName("x"), WithShape(1, 1, 4, 4), WithInit(RangedFrom(0)))
+	w := NewVector(g, Float64, WithName("w"), WithShape(4), WithInit(RangedFrom(0)))
+	max := Must(MaxPool2D(x, []int{2, 2}, nil, nil))
+	avg := Must(AvgPool2D(x, []int{2, 2}, nil, nil))
+	assert.Equal(types.Shape{1, 1, 2, 2}, max.Shape())
+	assert.Equal(types.Shape{1, 1, 2, 2}, avg.Shape())
+
+	maxCost := Must(Sum(Must(HadamardProd(Must(Reshape(max, 4)), w))))
+	avgCost := Must(Sum(Must(Reshape(avg, 4))))
+	cost := Must(Add(maxCost, avgCost))
+
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	correctGrad := []float64{
+		0.25, 0.25, 0.25, 0.25,
+		0.25, 0.25, 0.25, 1.25,
+		0.25, 0.25, 0.25, 0.25,
+		0.25, 2.25, 0.25, 3.25,
+	}
+	assert.Equal([]float64{5, 7, 13, 15}, max.Value().(Tensor).Tensor.Data())
+	assert.Equal([]float64{2.5, 4.5, 10.5, 12.5}, avg.Value().(Tensor).Tensor.Data())
+	xG, _ := x.Grad()
+	assert.Equal(correctGrad, xG.(Tensor).Tensor.Data())
+
+	/* Lisp machine version */
+	g2 := NewGraph()
+	x = NewTensor(g2, Float64, 4, WithName("x"), WithShape(1, 1, 4, 4), WithInit(RangedFrom(0)))
+	w = NewVector(g2, Float64, WithName("w"), WithShape(4), WithInit(RangedFrom(0)))
+	max = Must(MaxPool2D(x, []int{2, 2}, nil, nil))
+	avg = Must(AvgPool2D(x, []int{2, 2}, nil, nil))
+	maxCost = Must(Sum(Must(HadamardProd(Must(Reshape(max, 4)), w))))
+	avgCost = Must(Sum(Must(Reshape(avg, 4))))
+	cost = Must(Add(maxCost, avgCost))
+
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	xG, _ = x.Grad()
+	assert.Equal(correctGrad, xG.(Tensor).Tensor.Data())
+
+	// padded and strided
+	g3 := NewGraph()
+	x = NewTensor(g3, Float64, 4, WithName("x"), WithShape(1, 1, 3, 3), WithInit(RangedFrom(0)))
+	max = Must(MaxPool2D(x, []int{2, 2}, []int{2, 2}, []int{1, 1}))
+	assert.Equal(types.Shape{1, 1, 2, 2}, max.Shape())
+	prog, locMap, err = Compile(g3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{0, 2, 6, 8}, max.Value().(Tensor).Tensor.Data())
+}
+
+func TestSoftmaxCrossEntropy(t *testing.T) {
+	assert := assert.New(t)
+	logits := []float64{1, 2, 3, 1000, 0, -1000}
+	labels := []int{2, 0}
+	onehot := []float64{0, 0, 1, 1, 0, 0}
+
+	// softmax(logits) - onehot(labels), and -log(softmax(logits)[label])
+	correctGrad := make([]float64, len(logits))
+	correctTargetGrad := make([]float64, len(logits)) // -logsoftmax(logits)
+	correctLoss := make([]float64, 2)
+	for r := 0; r < 2; r++ {
+		row := logits[r*3 : r*3+3]
+		max := math.Max(row[0], math.Max(row[1], row[2]))
+		var sum float64
+		for _, v := range row {
+			sum += math.Exp(v - max)
+		}
+		lse := max + math.Log(sum)
+		correctLoss[r] = lse - row[labels[r]]
+		for c, v := range row {
+			correctGrad[r*3+c] = math.Exp(v-lse) - onehot[r*3+c]
+			correctTargetGrad[r*3+c] = lse - v
+		}
+	}
+
+	for _, sparse := range []bool{true, false} {
+		name := fmt.Sprintf("sparse %t", sparse)
+		build := func() (g *ExprGraph, x, y, loss, cost *Node) {
+			g = NewGraph()
+			x = NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(logits))))
+			if sparse {
+				y = NewVector(g, Int, WithName("y"), WithShape(2), WithValue(ti.NewTensor(ti.WithShape(2), ti.WithBacking(labels))))
+			} else {
+				y = NewMatrix(g, Float64, WithName("y"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(onehot))))
+			}
+			loss = Must(SoftmaxCrossEntropy(x, y))
+			cost = Must(Sum(loss))
+			return
+		}
+
+		g, x, y, loss, cost := build()
+		assert.Equal(types.Shape{2}, loss.Shape(), name)
+		wrt := Nodes{x}
+		if !sparse {
+			wrt = append(wrt, y)
+		}
+		if _, err := Grad(cost, wrt...); err != nil {
+			t.Fatal(err)
+		}
+
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := NewTapeMachine(prog, locMap)
+		if err = m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.InDelta(correctLoss[0]+correctLoss[1], cost.Value().(Scalar).v, 1e-10, name)
+		xG, _ := x.Grad()
+		xGData := extractF64s(xG)
+		for i, v := range correctGrad {
+			assert.InDelta(v, xGData[i], 1e-10, name)
+		}
+
+		if !sparse {
+			yG, _ := y.Grad()
+			yGData := extractF64s(yG)
+			for i, v := range correctTargetGrad {
+				assert.InDelta(v, yGData[i], 1e-10, name)
+			}
+		}
+
+		// lisp machine
+		_, x2, _, loss2, _ := build()
+		m2 := NewLispMachine(x2.g)
+		if err = m2.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		lossData := extractF64s(loss2.Value())
+		for i, v := range correctLoss {
+			assert.InDelta(v, lossData[i], 1e-10, name)
+		}
+		x2G, _ := x2.Grad()
+		x2GData := extractF64s(x2G)
+		for i, v := range correctGrad {
+			assert.InDelta(v, x2GData[i], 1e-10, name)
+		}
+	}
+
+	// a single example with a scalar label
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking(logits[:3]))))
+	y := NewScalar(g, Int, WithName("y"), WithValue(2))
+	loss := Must(SoftmaxCrossEntropy(x, y))
+	assert.True(loss.IsScalar())
+
+	m := NewLispMachine(g)
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.InDelta(correctLoss[0], loss.Value().(Scalar).v, 1e-10)
+	xG, _ := x.Grad()
+	xGData := extractF64s(xG)
+	for i, v := range correctGrad[:3] {
+		assert.InDelta(v, xGData[i], 1e-10)
+	}
+
+	// mismatched targets
+	z := NewVector(g, Int, WithName("z"), WithShape(3))
+	_, err := SoftmaxCrossEntropy(x, z)
+	assert.NotNil(err)
+}
+
+// naiveNormalize normalizes x over the axes in along, then scales and shifts it by the elements of the scale and bias that span params
+func naiveNormalize(x []float64, s types.Shape, along, params axes, scale, bias []float64, eps float64) []float64 {
+	groups, _ := reductionIndices(s, along)
+	var others axes
+	for i := range s {
+		var spanned bool
+		for _, a := range params {
+			spanned = spanned || a == i
+		}
+		if !spanned {
+			others = append(others, i)
+		}
+	}
+	ps, _ := reductionIndices(s, others)
+
+	sums := make(map[int]float64)
+	sqs := make(map[int]float64)
+	counts := make(map[int]float64)
+	for i, v := range x {
+		sums[groups[i]] += v
+		counts[groups[i]]++
+	}
+	for i, v := range x {
+		d := v - sums[groups[i]]/counts[groups[i]]
+		sqs[groups[i]] += d * d
+	}
+
+	retVal := make([]float64, len(x))
+	for i, v := range x {
+		g := groups[i]
+		mean, variance := sums[g]/counts[g], sqs[g]/counts[g]
+		retVal[i] = scale[ps[i]]*(v-mean)/math.Sqrt(variance+eps) + bias[ps[i]]
+	}
+	return retVal
+}
+
+func TestNormalization(t *testing.T) {
+	assert := assert.New(t)
+	const eps = 1e-5
+	normTests := []struct {
+		name   string
+		shape  types.Shape
+		along  axes // the axes the statistics are computed over
+		params axes
+		fn     func(x, scale, bias *Node) (*Node, error)
+	}{
+		{"BatchNorm (N, C)", types.Shape{4, 3}, axes{0}, axes{1}, func(x, scale, bias *Node) (*Node, error) {
+			y, err := BatchNorm(x, scale, bias, 0.9, eps)
+			return y, err
+		}},
+		{"BatchNorm (N, C, H, W)", types.Shape{2, 3, 2, 2}, axes{0, 2, 3}, axes{1}, func(x, scale, bias *Node) (*Node, error) {
+			y, err := BatchNorm(x, scale, bias, 0.9, eps)
+			return y, err
+		}},
+		{"LayerNorm (last axis)", types.Shape{2, 3, 4}, axes{2}, axes{2}, func(x, scale, bias *Node) (*Node, error) {
+			return LayerNorm(x, scale, bias, []int{2}, eps)
+		}},
+		{"LayerNorm (unsorted axes)", types.Shape{2, 3, 4}, axes{1, 2}, axes{1, 2}, func(x, scale, bias *Node) (*Node, error) {
+			return LayerNorm(x, scale, bias, []int{2, 1}, eps)
+		}},
+	}
+
+	for _, nt := range normTests {
+		ps := make(types.Shape, len(nt.params))
+		for i, a := range nt.params {
+			ps[i] = nt.shape[a]
+		}
+		data := [][]float64{make([]float64, nt.shape.TotalSize()), make([]float64, ps.TotalSize()), make([]float64, ps.TotalSize())}
+		for i := range data[0] {
+			data[0][i] = math.Sin(float64(i)) * 3
+		}
+		for i := range data[1] {
+			data[1][i] = 1 + 0.5*float64(i)
+			data[2][i] = 0.25 * float64(i)
+		}
+
+		// the cost is Σwᵢyᵢ, with weights that differ for each element
+		cost := func() (retVal float64) {
+			for i, v := range naiveNormalize(data[0], nt.shape, nt.along, nt.params, data[1], data[2], eps) {
+				retVal += float64(i%7+1) * v
+			}
+			return
+		}
+		correct := naiveNormalize(data[0], nt.shape, nt.along, nt.params, data[1], data[2], eps)
+		correctGrads := make([][]float64, len(data))
+		for i := range data {
+			correctGrads[i] = make([]float64, len(data[i]))
+			for j, orig := range data[i] {
+				data[i][j] = orig + 1e-6
+				plus := cost()
+				data[i][j] = orig - 1e-6
+				minus := cost()
+				data[i][j] = orig
+				correctGrads[i][j] = (plus - minus) / 2e-6
+			}
+		}
+
+		build := func() (g *ExprGraph, y *Node, inputs Nodes) {
+			g = NewGraph()
+			shapes := []types.Shape{nt.shape, ps, ps}
+			for i, name := range []string{"x", "scale", "bias"} {
+				T := tf64.NewTensor(tf64.WithShape(shapes[i]...), tf64.WithBacking(append([]float64{}, data[i]...)))
+				inputs = append(inputs, NewTensor(g, Float64, len(shapes[i]), WithName(name), WithShape(shapes[i]...), WithValue(T)))
+			}
+			y = Must(nt.fn(inputs[0], inputs[1], inputs[2]))
+			w := make([]float64, nt.shape.TotalSize())
+			for i := range w {
+				w[i] = float64(i%7 + 1)
+			}
+			W := NewConstant(tf64.NewTensor(tf64.WithShape(nt.shape...), tf64.WithBacking(w)))
+			Must(Sum(Must(HadamardProd(y, W))))
+			return
+		}
+
+		checkGrads := func(machine string, inputs Nodes) {
+			for i, n := range inputs {
+				grad, err := n.Grad()
+				if err != nil {
+					t.Errorf("%s: %s: %v", nt.name, machine, err)
+					continue
+				}
+				for j, v := range extractF64s(grad) {
+					assert.InDelta(correctGrads[i][j], v, 1e-5, nt.name+": "+machine+": "+n.Name())
+				}
+			}
+		}
+
+		// symbolic differentiation
+		g, _, inputs := build()
+		if _, err := Grad(g.Roots()[0], inputs...); err != nil {
+			t.Errorf("%s: %v", nt.name, err)
+			continue
+		}
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Errorf("%s: %v", nt.name, err)
+			continue
+		}
+		if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+			t.Errorf("%s: %v", nt.name, err)
+			continue
+		}
+		checkGrads("TapeMachine", inputs)
+
+		// automatic differentiation
+		g, y, inputs := build()
+		if err = NewLispMachine(g).RunAll(); err != nil {
+			t.Errorf("%s: %v", nt.name, err)
+			continue
+		}
+		for i, v := range extractF64s(y.Value()) {
+			assert.InDelta(correct[i], v, 1e-10, nt.name)
+		}
+		checkGrads("LispMachine", inputs)
+	}
+}
+
+func TestBatchNormModes(t *testing.T) {
+	assert := assert.New(t)
+	const eps = 1e-5
+	xs := []float64{1, 10, 2, 20, 3, 30, 6, 60} // (4, 2): the features have means 3 and 30, and unbiased variances 14/3 and 1400/3
+	dys := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(4, 2), WithValue(tf64.NewTensor(tf64.WithShape(4, 2), tf64.WithBacking(xs))))
+	scale := NewVector(g, Float64, WithName("scale"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking([]float64{2, 3}))))
+	bias := NewVector(g, Float64, WithName("bias"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking([]float64{1, -1}))))
+	y, err := BatchNorm(x, scale, bias, 0.9, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := BatchNormStatsOf(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	W := NewConstant(tf64.NewTensor(tf64.WithShape(4, 2), tf64.WithBacking(dys)))
+	cost := Must(Sum(Must(HadamardProd(y, W))))
+	if _, err = Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]float64{0, 0}, extractF64s(stats.Mean()))
+	assert.Equal([]float64{1, 1}, extractF64s(stats.Var()))
+
+	// training: the running statistics move towards those of the batch
+	if err = NewTapeMachine(prog, locMap, TrainingMode()).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	runningMean := []float64{0.1 * 3, 0.1 * 30}
+	runningVar := []float64{0.9 + 0.1*14.0/3, 0.9 + 0.1*1400.0/3}
+	for i, v := range extractF64s(stats.Mean()) {
+		assert.InDelta(runningMean[i], v, 1e-10)
+	}
+	for i, v := range extractF64s(stats.Var()) {
+		assert.InDelta(runningVar[i], v, 1e-10)
+	}
+
+	// inference: the same program uses the running statistics, and leaves them be
+	m := NewTapeMachine(prog, locMap, TraceExec(), InferenceMode())
+	if err = m.Run(prog.instructions); err != nil {
+		t.Fatal(err)
+	}
+	xG, _ := x.Grad()
+	for i, v := range extractF64s(y.Value()) {
+		f := i % 2
+		invstd := 1 / math.Sqrt(runningVar[f]+eps)
+		assert.InDelta([]float64{2, 3}[f]*(xs[i]-runningMean[f])*invstd+[]float64{1, -1}[f], v, 1e-10)
+		assert.InDelta(dys[i]*[]float64{2, 3}[f]*invstd, extractF64s(xG)[i], 1e-10)
+	}
+	for i, v := range extractF64s(stats.Var()) {
+		assert.InDelta(runningVar[i], v, 1e-10)
+	}
+
+	// the mode can be switched on the same machine
+	TrainingMode()(m)
+	if err = m.Run(prog.instructions); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range extractF64s(stats.Mean()) {
+		assert.InDelta(0.9*runningMean[i]+0.1*[]float64{3, 30}[i], v, 1e-10)
+	}
+	stats.Reset()
+	assert.Equal([]float64{0, 0}, extractF64s(stats.Mean()))
+
+	/* Idiots */
+
+	if _, err = BatchNorm(x, scale, bias, 1.5, eps); err == nil {
+		t.Error("Expected an error with a momentum greater than 1")
+	}
+	if _, err = BatchNorm(scale, scale, bias, 0.9, eps); err == nil {
+		t.Error("Expected an error normalizing a vector")
+	}
+	if _, err = BatchNorm(x, x, bias, 0.9, eps); err == nil {
+		t.Error("Expected an error with a scale of the wrong shape")
+	}
+	if _, err = BatchNormStatsOf(cost); err == nil {
+		t.Error("Expected an error getting the statistics of a node that is not the result of a BatchNorm")
+	}
+	if _, err = LayerNorm(x, scale, bias, []int{2}, eps); err == nil {
+		t.Error("Expected an error normalizing along an axis that doesn't exist")
+	}
+	if _, err = LayerNorm(x, scale, bias, nil, eps); err == nil {
+		t.Error("Expected an error with a scale that doesn't span every axis")
+	}
+}
@@ -12,71 +12,78 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"math"
 
 	"github.com/chewxy/gorgonia/tensor"
 	tf32 "github.com/chewxy/gorgonia/tensor/f32"
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	ti "github.com/chewxy/gorgonia/tensor/i"
 	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/pkg/errors"
 )
 
+/* MAX OP */
+
 type maxOp struct {
 	along axes
 	d     int
+
+	inputShape types.Shape
 }
 
-func newMaxOp(along axes, dim int) *maxOp {
+func newMaxOp(along axes, s types.Shape, dim int) *maxOp {
 	return &maxOp{
-		along: along,
-		d:     dim,
+		along:      along,
+		d:          dim,
+		inputShape: s,
 	}
 }
 
+// maxOp is a function with this type:
+//		maxOp :: (Summable a) ⇒ Tensor d a → Tensor d-len(along) a
 func (op maxOp) Type() Type {
 	a := newTypeVariable("a", withTVConstraints(summable))
 	t := newTensorType(op.d, a)
+	return newFunctionType(t, reductionRetType(op.d, op.along, op.inputShape, a))
+}
 
-	var retType Type
-	if op.d == 1 || len(op.along) == 0 || len(op.along) == op.d {
-		// then it redueces down
-		retType = a
-		return newFunctionType(t, a)
-	} else {
-		retType = newTensorType(op.d-1, a)
+func (op maxOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
+	if len(inputs) != 1 {
+		return nil, NewError(GraphError, "maxOp requires only one input")
 	}
-	return newFunctionType(t, retType)
+	return reduceShape(inputs[0].shape, op.along), nil
 }
 
-func (op maxOp) inferShape(Type, ...*Node) (types.Shape, error) { return scalarShape, nil } // TODO, THIS IS INCORRECT
-func (op maxOp) DiffWRT(i int) []bool                           { return []bool{true} }
+func (op maxOp) DiffWRT(i int) []bool { return []bool{true} }
 
 func (op maxOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
 	if len(inputs) != 1 {
-		err = NewError(GraphError, "Expect at least 1 input. Got %d instead", len(inputs))
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
 		return
 	}
-	t := inputs[0]
-	opDim := len(t.Shape())
 
-	var leftAxes []byte
-	for i := 0; i < opDim; i++ {
-		for _, ax := range op.along {
-			if i == ax {
-				leftAxes = append(leftAxes, byte(i))
-				break
-			}
-		}
+	diff := extremumDiffOp{along: op.along, inputShape: inputs[0].shape.Clone()}
+	retVal = make(Nodes, 1)
+	if retVal[0], err = applyOp(diff, inputs[0], output, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
 	}
+	retVal[0].setGroup(gradClust)
+	return
+}
 
-	var eq *Node
-	bcpat := NewBroadcastPattern(leftAxes, nil)
-	if eq, err = Broadcast(eqOpType, output, t, bcpat); err != nil {
-		err = errors.Wrap(err, operationError)
+func (op maxOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
 		return
 	}
 
-	retVal[0], err = Broadcast(mulOpType, gradNode, eq, bcpat)
-	return
+	diff := extremumDiffOp{along: op.along, inputShape: inputs[0].shape.Clone()}
+	return gradFwdDiff(diff, output, tangents[0], op.along, inputs[0], output)
+}
+
+func (op maxOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	return extremumDoDiff(op.along, inputs, output)
 }
 
 func (op maxOp) Do(inputs ...Value) (retVal Value, err error) {
@@ -84,11 +91,11 @@ func (op maxOp) Do(inputs ...Value) (retVal Value, err error) {
 		err = NewError(GraphError, "Expected only one input for maxop. Got %d instead", len(inputs))
 		return
 	}
-	return nil, NewError(NotYetImplemented, "maxOp")
+	return extremum(op, inputs[0], op.along, false)
 }
 
-func (op maxOp) returnsPtr() bool    { return true }
-func (op maxOp) overwriteInput() int { return 0 }
+func (op maxOp) returnsPtr() bool    { return false }
+func (op maxOp) overwriteInput() int { return -1 }
 func (op maxOp) callsExtern() bool   { return false }
 
 func (op maxOp) WriteHash(h hash.Hash) {
@@ -96,7 +103,7 @@ func (op maxOp) WriteHash(h hash.Hash) {
 	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
 		panic(err)
 	}
-	fmt.Fprintf(h, "%v", op.d, op.along)
+	fmt.Fprintf(h, "%v->%v", op.along, op.inputShape)
 }
 
 func (op maxOp) Hashcode() uint32 {
@@ -108,234 +115,2242 @@ func (op maxOp) Hashcode() uint32 {
 func (op maxOp) String() string { return fmt.Sprintf("MaxAlong%v", op.along) }
 func (op maxOp) isUnary() bool  { return true }
 
-/* ARGMAX OP */
-// type argmaxOp struct {
-// 	along int // axis
-// }
-
-// func (op argmaxOp) Type() Type {
-// 	a := newTypeVariable("a")
-
-// }
+/* MIN OP */
 
-/* SUM OP */
+type minOp struct {
+	along axes
+	d     int
 
-type sumOp struct {
-	along      axes
-	d          int
 	inputShape types.Shape
 }
 
-func newSumOp(along axes, s types.Shape, d int) sumOp {
-	return sumOp{
+func newMinOp(along axes, s types.Shape, dim int) *minOp {
+	return &minOp{
 		along:      along,
-		d:          d,
+		d:          dim,
 		inputShape: s,
 	}
 }
 
-// sumOp is a function with this type:
-//		sumOp :: (Summable a) ⇒ Tensor d a → Tensor d-1 a
-func (op sumOp) Type() Type {
+// minOp is a function with this type:
+//		minOp :: (Summable a) ⇒ Tensor d a → Tensor d-len(along) a
+func (op minOp) Type() Type {
 	a := newTypeVariable("a", withTVConstraints(summable))
 	t := newTensorType(op.d, a)
-	var retType Type
-	if op.d == 1 || len(op.along) == 0 || len(op.along) == op.d {
-		// then it redueces down
-		retType = a
-		return newFunctionType(t, a)
-	} else {
-		retType = newTensorType(op.d-1, a)
-	}
-	return newFunctionType(t, retType)
+	return newFunctionType(t, reductionRetType(op.d, op.along, op.inputShape, a))
 }
 
-func (op sumOp) inferShape(t Type, inputs ...*Node) (shape types.Shape, err error) {
+func (op minOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
 	if len(inputs) != 1 {
-		err = NewError(GraphError, "sumOp requires only one input")
-		return
+		return nil, NewError(GraphError, "minOp requires only one input")
 	}
+	return reduceShape(inputs[0].shape, op.along), nil
+}
 
-	in := inputs[0]
-	shapeLogf("Infering... Type: %v", t)
-	shapeLogf("input shape: %v", in.shape)
-	switch {
-	case in.IsScalar():
-		shape = scalarShape
-	case in.IsVector() && !in.IsRowVec() && !in.IsColVec():
-		if len(op.along) > 1 || (len(op.along) == 1 && op.along[0] != 0) {
-			err = NewError(ShapeError, "Shape mismatch: along is %v. Shape is %v", op.along, in.shape)
-			return
-		}
-		shape = scalarShape
-	default:
-		shape = in.Shape().Clone()
-		if len(op.along) > len(shape) {
-			err = NewError(ShapeError, "Shape mismatch: %v and %v", shape, op.along)
-			return
-		}
-
-		if monotonic, incr1 := types.IsMonotonicInts(op.along); monotonic && incr1 && len(op.along) == len(shape) {
-			shape = scalarShape
-			return
-		}
-
-		for _, a := range op.along {
-			if a >= len(shape) {
-				err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", a, shape)
-				return
-			}
-			shape[a] = 1
-		}
+func (op minOp) DiffWRT(i int) []bool { return []bool{true} }
 
-		if oneone.Eq(shape) {
-			shape = scalarShape
-		}
+func (op minOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
 
+	diff := extremumDiffOp{along: op.along, inputShape: inputs[0].shape.Clone()}
+	retVal = make(Nodes, 1)
+	if retVal[0], err = applyOp(diff, inputs[0], output, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
 	}
+	retVal[0].setGroup(gradClust)
 	return
 }
 
-func (op sumOp) DiffWRT(i int) []bool { return []bool{true} }
-
-func (op sumOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+func (op minOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
 	if len(inputs) != 1 {
-		err = NewError(GraphError, "Requires only one input to differentiate sumop")
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
 		return
 	}
-	children := make(Nodes, len(op.along)+1)
-	children[0] = gradNode
-	for i, a := range op.along {
-		var n *Node
-		if n, err = SizeOf(a, inputs[0]); err != nil {
-			err = errors.Wrap(err, operationError)
-			return
-		}
-		WithGroupName(gradClust)(n)
-		children[i+1] = n
-	}
 
-	retVal = make(Nodes, 1)
-	repeat := newRepeatOp(op.along, children)
+	diff := extremumDiffOp{along: op.along, inputShape: inputs[0].shape.Clone()}
+	return gradFwdDiff(diff, output, tangents[0], op.along, inputs[0], output)
+}
 
-	symdiffLogf("repeat: %v", repeat.Type())
-	symdiffLogf("children %#Y", children)
-	symdiffLogf("children: %v", children)
-	retVal[0], err = applyOp(repeat, children...)
-	retVal[0].setGroup(gradClust)
-	return
+func (op minOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	return extremumDoDiff(op.along, inputs, output)
 }
 
-func (op sumOp) DoDiff(inputs Nodes, output *Node) (err error) {
+func (op minOp) Do(inputs ...Value) (retVal Value, err error) {
 	if len(inputs) != 1 {
-		err = NewError(GraphError, "Requires only one input to differentiate sumop")
+		err = NewError(GraphError, "Expected only one input for minop. Got %d instead", len(inputs))
 		return
 	}
+	return extremum(op, inputs[0], op.along, true)
+}
 
-	xdv := inputs[0].boundTo.(*dualValue)
-	ydv := output.boundTo.(*dualValue)
-	xShape := xdv.Value.Shape()
+func (op minOp) returnsPtr() bool    { return false }
+func (op minOp) overwriteInput() int { return -1 }
+func (op minOp) callsExtern() bool   { return false }
 
-	var T types.Tensor
-	switch ydvd := ydv.d.(type) {
-	case Scalar:
-		switch ydvd.t {
-		case Float64:
-			f := ydvd.v.(float64)
-			T = tf64.NewTensor(tf64.AsScalar(f))
-		case Float32:
-			f := ydvd.v.(float32)
-			T = tf32.NewTensor(tf32.AsScalar(f))
-		default:
-			err = nyi("sumOp.DoDiff", ydvd.t)
-			return
-		}
-	case Tensor:
-		T = ydvd.Tensor
+func (op minOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("min"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
 	}
+	fmt.Fprintf(h, "%v->%v", op.along, op.inputShape)
+}
 
-	var val Value
-	if !T.Shape().Eq(xdv.d.Shape()) {
-		// TO DO: Optimize: figure out a way to bunch it all up so you can repeat in one call
-		for _, a := range op.along {
-			if xShape[a] == 1 {
-				continue // don't need to repeat
-			}
-			if T, err = tensor.Repeat(T, a, xShape[a]); err != nil {
-				err = errors.Wrapf(err, repFail, a, xShape[a])
-				return
-			}
-		}
+func (op minOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
 
-		val = FromTensor(T)
-	} else {
-		val = ydv.d
+func (op minOp) String() string { return fmt.Sprintf("MinAlong%v", op.along) }
+func (op minOp) isUnary() bool  { return true }
+
+/* EXTREMUM DIFF OP */
+
+// extremumDiffOp is the gradient of maxOp and minOp. It takes the input of the reduction, the result of the reduction
+// and the gradient of the result, and routes the gradient to the first element that is equal to the extremum of its group.
+// Like Argmax, ties are broken in favour of the first element, so the gradient is not multiplied by the number of ties.
+type extremumDiffOp struct {
+	along      axes
+	inputShape types.Shape
+}
+
+// extremumDiffOp is a function with this type:
+//		extremumDiffOp :: (Floats a) ⇒ Tensor d a → b → b → Tensor d a
+// where b is the type of the result of the reduction
+func (op extremumDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	d := op.inputShape.Dims()
+	t := newTensorType(d, a)
+	r := reductionRetType(d, op.along, op.inputShape, a)
+	return newFunctionType(t, r, r, t)
+}
+
+func (op extremumDiffOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
+	if len(inputs) != 3 {
+		return nil, NewError(GraphError, "extremumDiffOp requires three inputs. Got %d instead", len(inputs))
 	}
+	return op.inputShape.Clone(), nil
+}
 
-	// then just add the two
-	add := newEBOByType(addOpType, xdv.d.Type(), val.Type())
+// DiffWRT states that extremumDiffOp is differentiable wrt the input and the gradient. The extremum is only used to find
+// the elements the gradient is routed to.
+func (op extremumDiffOp) DiffWRT(i int) []bool { return []bool{true, false, true} }
 
-	var d Value
-	if d, err = add.UnsafeDo(xdv.d, val); err != nil {
-		err = errors.Wrapf(err, unsafeDoFail, add)
+// SymDiff differentiates the routing of the gradient. It is piecewise constant in the input, so the gradient wrt the input is 0.
+func (op extremumDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "extremumDiffOp requires three inputs. Got %d instead", len(inputs))
 		return
 	}
 
-	// check if xdv.d is scalar
-	if xdv.d.Type().isScalar() {
-		return xdv.SetDeriv(d)
+	retVal = make(Nodes, 3)
+	if retVal[0], err = zeroGrad(inputs[0]); err != nil {
+		return
+	}
+	if retVal[2], err = gradFwdDiff(op, inputs[1], gradNode, op.along, inputs[0], inputs[1]); err != nil {
+		return
 	}
+	retVal[2].setGroup(gradClust)
 	return
-
 }
 
-func (op sumOp) Do(inputs ...Value) (retVal Value, err error) {
-	if len(inputs) != 1 {
-		err = NewError(GraphError, "Expect only one input for sumOp. GOt %v instead", len(inputs))
+func (op extremumDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "extremumDiffOp requires three inputs. Got %d instead", len(inputs))
 		return
 	}
 
-	a := inputs[0]
-	at := a.(Tensor)
-	switch t := at.Tensor.(type) {
-	case *tf64.Tensor:
-		var ret *tf64.Tensor
-		if ret, err = t.Sum(op.along...); err == nil {
-			if ret.IsScalar() {
-				retVal = NewScalarValue(ret.ScalarValue())
-			} else {
-				retVal = FromTensor(ret)
+	var x types.Tensor
+	if x, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var y, dy interface{}
+	if y, err = valueData(inputs[1]); err != nil {
+		return
+	}
+	if dy, err = valueData(inputs[2]); err != nil {
+		return
+	}
+
+	idx, size := reductionIndices(x.Shape(), op.along)
+	switch data := x.Data().(type) {
+	case []float64:
+		ys, ok1 := y.([]float64)
+		dys, ok2 := dy.([]float64)
+		if !ok1 || !ok2 || len(ys) != size || len(dys) != size {
+			err = NewError(RuntimeError, "%v expected the reduced values and their gradients to be %d float64s", op, size)
+			return
+		}
+
+		t := tf64.NewTensor(tf64.WithShape(x.Shape()...))
+		dx := t.Data().([]float64)
+		taken := make([]bool, size)
+		for i, o := range idx {
+			if data[i] == ys[o] && !taken[o] {
+				dx[i] = dys[o]
+				taken[o] = true
 			}
 		}
-	case *tf32.Tensor:
-		var ret *tf32.Tensor
-		if ret, err = t.Sum(op.along...); err == nil {
-			if ret.IsScalar() {
-				retVal = NewScalarValue(ret.ScalarValue())
-			} else {
-				retVal = FromTensor(ret)
+		retVal = FromTensor(t)
+	case []float32:
+		ys, ok1 := y.([]float32)
+		dys, ok2 := dy.([]float32)
+		if !ok1 || !ok2 || len(ys) != size || len(dys) != size {
+			err = NewError(RuntimeError, "%v expected the reduced values and their gradients to be %d float32s", op, size)
+			return
+		}
+
+		t := tf32.NewTensor(tf32.WithShape(x.Shape()...))
+		dx := t.Data().([]float32)
+		taken := make([]bool, size)
+		for i, o := range idx {
+			if data[i] == ys[o] && !taken[o] {
+				dx[i] = dys[o]
+				taken[o] = true
 			}
 		}
+		retVal = FromTensor(t)
 	default:
-		err = nyi("sumOp.Do", at.Tensor)
+		err = nyi("extremumDiffOp", x.Dtype())
 	}
 	return
 }
 
-func (op sumOp) returnsPtr() bool    { return true }
-func (op sumOp) overwriteInput() int { return 0 }
-func (op sumOp) callsExtern() bool   { return false }
+func (op extremumDiffOp) returnsPtr() bool    { return false }
+func (op extremumDiffOp) overwriteInput() int { return -1 }
+func (op extremumDiffOp) callsExtern() bool   { return false }
 
-func (op sumOp) WriteHash(h hash.Hash) {
-	h.Write([]byte("sum"))
+func (op extremumDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("extremumDiff"))
 	fmt.Fprintf(h, "%v->%v", op.along, op.inputShape)
 }
 
-func (op sumOp) Hashcode() uint32 {
+func (op extremumDiffOp) Hashcode() uint32 {
 	h := fnv.New32a()
 	op.WriteHash(h)
 	return h.Sum32()
 }
 
-func (op sumOp) String() string { return fmt.Sprintf("Σ%v", op.along) }
-func (op sumOp) isUnary() bool  { return true }
+func (op extremumDiffOp) String() string { return fmt.Sprintf("ExtremumDiff%v", op.along) }
+
+/* ARGMAX/ARGMIN OP */
+
+// argOp finds the index of the largest (or smallest, if min is set) value along an axis.
+// Ties are resolved to the first index. The results are Ints, so argOp is not differentiable.
+type argOp struct {
+	along int
+	d     int
+	min   bool
+
+	inputShape types.Shape
+}
+
+func newArgOp(along int, s types.Shape, dim int, min bool) argOp {
+	return argOp{
+		along:      along,
+		d:          dim,
+		min:        min,
+		inputShape: s,
+	}
+}
+
+// argOp is a function with this type:
+//		argOp :: (Floats a) ⇒ Tensor d a → Tensor d-1 Int
+func (op argOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.d, a)
+	return newFunctionType(t, reductionRetType(op.d, axes{op.along}, op.inputShape, Int))
+}
+
+func (op argOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
+	if len(inputs) != 1 {
+		return nil, NewError(GraphError, "%v requires only one input", op)
+	}
+	return reduceShape(inputs[0].shape, axes{op.along}), nil
+}
+
+func (op argOp) DiffWRT(i int) []bool { return make([]bool, i) }
+
+func (op argOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	err = nondiffErr(op)
+	return
+}
+
+func (op argOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "%v requires only one input. Got %d instead", op, len(inputs))
+		return
+	}
+
+	var t types.Tensor
+	if t, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	s := t.Shape()
+	if op.along >= len(s) {
+		err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", op.along, s)
+		return
+	}
+
+	// stride of the axis
+	inner := 1
+	for _, size := range s[op.along+1:] {
+		inner *= size
+	}
+
+	idx, size := reductionIndices(s, axes{op.along})
+	best := make([]int, size)
+	for i := range best {
+		best[i] = -1
+	}
+
+	switch data := t.Data().(type) {
+	case []float64:
+		for i, o := range idx {
+			if best[o] < 0 || (op.min && data[i] < data[best[o]]) || (!op.min && data[i] > data[best[o]]) {
+				best[o] = i
+			}
+		}
+	case []float32:
+		for i, o := range idx {
+			if best[o] < 0 || (op.min && data[i] < data[best[o]]) || (!op.min && data[i] > data[best[o]]) {
+				best[o] = i
+			}
+		}
+	default:
+		err = nyi(op.String(), t.Dtype())
+		return
+	}
+
+	for o, i := range best {
+		best[o] = (i / inner) % s[op.along]
+	}
+
+	rs := reduceShape(s, axes{op.along})
+	if rs.IsScalar() {
+		retVal = NewScalarValue(best[0])
+		return
+	}
+	retVal = FromTensor(ti.NewTensor(ti.WithShape(rs...), ti.WithBacking(best)))
+	return
+}
+
+func (op argOp) returnsPtr() bool    { return false }
+func (op argOp) overwriteInput() int { return -1 }
+func (op argOp) callsExtern() bool   { return false }
+
+func (op argOp) WriteHash(h hash.Hash) {
+	if op.min {
+		h.Write([]byte("argmin"))
+	} else {
+		h.Write([]byte("argmax"))
+	}
+	fmt.Fprintf(h, "%v->%v", op.along, op.inputShape)
+}
+
+func (op argOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op argOp) String() string {
+	if op.min {
+		return fmt.Sprintf("Argmin(%d)", op.along)
+	}
+	return fmt.Sprintf("Argmax(%d)", op.along)
+}
+
+func (op argOp) isUnary() bool { return true }
+
+/* UTILITY FUNCTIONS FOR REDUCTIONS */
+
+// reduceShape returns the shape of a tensor of shape s after it has been reduced along the given axes.
+// Unlike sumOp, the reduced axes are removed rather than kept as 1.
+func reduceShape(s types.Shape, along axes) types.Shape {
+	if s.IsScalar() {
+		return scalarShape
+	}
+
+	retVal := make(types.Shape, 0, len(s))
+	size := 1
+	for i, d := range s {
+		var reduced bool
+		for _, a := range along {
+			if a == i {
+				reduced = true
+				break
+			}
+		}
+		if !reduced {
+			retVal = append(retVal, d)
+			size *= d
+		}
+	}
+
+	if len(retVal) == 0 || size == 1 {
+		return scalarShape
+	}
+	return retVal
+}
+
+// reductionRetType is the type of the result of reducing a Tensor d a along the given axes.
+// If the input shape is not known, it is assumed that each axis reduces one dimension.
+func reductionRetType(d int, along axes, s types.Shape, a Type) Type {
+	rd := d - len(along)
+	if s != nil {
+		rd = reduceShape(s, along).Dims()
+	}
+
+	if d <= 1 || rd <= 0 {
+		return a
+	}
+	return newTensorType(rd, a)
+}
+
+// reductionIndices maps each element of a tensor of shape s to the element of the reduced tensor it reduces into.
+// It also returns the number of elements in the reduced tensor.
+func reductionIndices(s types.Shape, along axes) (retVal []int, size int) {
+	strides := make([]int, len(s)) // reduced axes have a stride of 0
+	size = 1
+	for i := len(s) - 1; i >= 0; i-- {
+		var reduced bool
+		for _, a := range along {
+			if a == i {
+				reduced = true
+				break
+			}
+		}
+		if !reduced {
+			strides[i] = size
+			size *= s[i]
+		}
+	}
+
+	total := 1
+	for _, d := range s {
+		total *= d
+	}
+
+	retVal = make([]int, total)
+	for i := range retVal {
+		rem := i
+		for j := len(s) - 1; j >= 0; j-- {
+			retVal[i] += (rem % s[j]) * strides[j]
+			rem /= s[j]
+		}
+	}
+	return
+}
+
+// extremum reduces v along the given axes, keeping the largest (or smallest, if min is set) value of each group.
+func extremum(op Op, v Value, along axes, min bool) (retVal Value, err error) {
+	var t types.Tensor
+	if t, err = tensorOf(op, v); err != nil {
+		return
+	}
+
+	s := t.Shape()
+	for _, a := range along {
+		if a >= len(s) {
+			err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", a, s)
+			return
+		}
+	}
+
+	rs := reduceShape(s, along)
+	idx, size := reductionIndices(s, along)
+	seen := make([]bool, size)
+	switch data := t.Data().(type) {
+	case []float64:
+		reduced := make([]float64, size)
+		for i, o := range idx {
+			if !seen[o] || (min && data[i] < reduced[o]) || (!min && data[i] > reduced[o]) {
+				reduced[o] = data[i]
+				seen[o] = true
+			}
+		}
+
+		if rs.IsScalar() {
+			retVal = NewScalarValue(reduced[0])
+		} else {
+			retVal = FromTensor(tf64.NewTensor(tf64.WithShape(rs...), tf64.WithBacking(reduced)))
+		}
+	case []float32:
+		reduced := make([]float32, size)
+		for i, o := range idx {
+			if !seen[o] || (min && data[i] < reduced[o]) || (!min && data[i] > reduced[o]) {
+				reduced[o] = data[i]
+				seen[o] = true
+			}
+		}
+
+		if rs.IsScalar() {
+			retVal = NewScalarValue(reduced[0])
+		} else {
+			retVal = FromTensor(tf32.NewTensor(tf32.WithShape(rs...), tf32.WithBacking(reduced)))
+		}
+	default:
+		err = nyi(op.String(), t.Dtype())
+	}
+	return
+}
+
+// extremumDoDiff is the DoDiff shared by maxOp and minOp
+func extremumDoDiff(along axes, inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	diff := extremumDiffOp{along: along, inputShape: xdv.Value.Shape()}
+	var d Value
+	if d, err = diff.Do(xdv.Value, ydv.Value, ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+// tensorOf returns the (contiguous) tensor held by v
+func tensorOf(op Op, v Value) (t types.Tensor, err error) {
+	T, ok := v.(Tensor)
+	if !ok {
+		err = NewError(RuntimeError, "%v expects a Tensor input. Got %v of %T instead", op, v, v)
+		return
+	}
+	return T.Materialize(), nil
+}
+
+// valueData returns the flat data held by v. Scalars are returned as a slice of length 1.
+func valueData(v Value) (retVal interface{}, err error) {
+	switch vt := v.(type) {
+	case Tensor:
+		retVal = vt.Materialize().Data()
+	case Scalar:
+		switch f := vt.v.(type) {
+		case float64:
+			retVal = []float64{f}
+		case float32:
+			retVal = []float32{f}
+		default:
+			err = nyi("valueData", vt.t)
+		}
+	default:
+		err = nyi("valueData", v)
+	}
+	return
+}
+
+// accumulateDeriv calls fn with the data of the derivative of dv, so that fn can accumulate into it.
+// Scalar derivatives are immutable, so they are replaced with the accumulated value.
+func accumulateDeriv(dv *dualValue, fn func(d interface{}) error) (err error) {
+	switch d := dv.d.(type) {
+	case Tensor:
+		return fn(d.Data())
+	case Scalar:
+		var data interface{}
+		if data, err = valueData(d); err != nil {
+			return
+		}
+		if err = fn(data); err != nil {
+			return
+		}
+
+		var v Value
+		switch dt := data.(type) {
+		case []float64:
+			v, err = anyToValue(dt[0])
+		case []float32:
+			v, err = anyToValue(dt[0])
+		}
+		if err != nil {
+			return
+		}
+		return dv.SetDeriv(v)
+	default:
+		return nyi("accumulateDeriv", dv.d)
+	}
+}
+
+/* SUM OP */
+
+type sumOp struct {
+	along      axes
+	d          int
+	inputShape types.Shape
+}
+
+func newSumOp(along axes, s types.Shape, d int) sumOp {
+	return sumOp{
+		along:      along,
+		d:          d,
+		inputShape: s,
+	}
+}
+
+// sumOp is a function with this type:
+//		sumOp :: (Summable a) ⇒ Tensor d a → Tensor d-1 a
+func (op sumOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(summable))
+	t := newTensorType(op.d, a)
+	if op.d > 2 {
+		return newFunctionType(t, reductionRetType(op.d, op.along, op.inputShape, a))
+	}
+
+	var retType Type
+	if op.d == 1 || len(op.along) == 0 || len(op.along) == op.d {
+		// then it redueces down
+		retType = a
+		return newFunctionType(t, a)
+	} else {
+		retType = newTensorType(op.d-1, a)
+	}
+	return newFunctionType(t, retType)
+}
+
+func (op sumOp) inferShape(t Type, inputs ...*Node) (shape types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "sumOp requires only one input")
+		return
+	}
+
+	in := inputs[0]
+	shapeLogf("Infering... Type: %v", t)
+	shapeLogf("input shape: %v", in.shape)
+	switch {
+	case in.IsScalar():
+		shape = scalarShape
+	case in.IsVector() && !in.IsRowVec() && !in.IsColVec():
+		if len(op.along) > 1 || (len(op.along) == 1 && op.along[0] != 0) {
+			err = NewError(ShapeError, "Shape mismatch: along is %v. Shape is %v", op.along, in.shape)
+			return
+		}
+		shape = scalarShape
+	case len(in.shape) > 2:
+		for _, a := range op.along {
+			if a >= len(in.shape) {
+				err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", a, in.shape)
+				return
+			}
+		}
+		shape = reduceShape(in.shape, op.along)
+	default:
+		shape = in.Shape().Clone()
+		if len(op.along) > len(shape) {
+			err = NewError(ShapeError, "Shape mismatch: %v and %v", shape, op.along)
+			return
+		}
+
+		if monotonic, incr1 := types.IsMonotonicInts(op.along); monotonic && incr1 && len(op.along) == len(shape) {
+			shape = scalarShape
+			return
+		}
+
+		for _, a := range op.along {
+			if a >= len(shape) {
+				err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", a, shape)
+				return
+			}
+			shape[a] = 1
+		}
+
+		if oneone.Eq(shape) {
+			shape = scalarShape
+		}
+
+	}
+	return
+}
+
+func (op sumOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op sumOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Requires only one input to differentiate sumop")
+		return
+	}
+
+	// higher dimension tensors have their reduced axes dropped, so the gradient is reshaped and broadcast back
+	if op.d > 2 {
+		g := gradNode
+		from := scalarShape
+		if !g.IsScalar() {
+			from = op.inputShape.Clone()
+			for _, a := range op.along {
+				from[a] = 1
+			}
+			if g, err = Reshape(g, from...); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+			g.setGroup(gradClust)
+		}
+
+		retVal = make(Nodes, 1)
+		if retVal[0], err = applyOp(newBroadcastOp(from, op.inputShape), g); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		retVal[0].setGroup(gradClust)
+		return
+	}
+
+	children := make(Nodes, len(op.along)+1)
+	children[0] = gradNode
+	for i, a := range op.along {
+		var n *Node
+		if n, err = SizeOf(a, inputs[0]); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		WithGroupName(gradClust)(n)
+		children[i+1] = n
+	}
+
+	retVal = make(Nodes, 1)
+	repeat := newRepeatOp(op.along, children)
+
+	symdiffLogf("repeat: %v", repeat.Type())
+	symdiffLogf("children %#Y", children)
+	symdiffLogf("children: %v", children)
+	retVal[0], err = applyOp(repeat, children...)
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+// FwdDiff sums the tangent.
+func (op sumOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op sumOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Requires only one input to differentiate sumop")
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+	xShape := xdv.Value.Shape()
+
+	if op.d > 2 {
+		var dy interface{}
+		if dy, err = valueData(ydv.d); err != nil {
+			return
+		}
+
+		dx, ok := xdv.d.(Tensor)
+		if !ok {
+			err = NewError(RuntimeError, "%v expects the derivative of its input to be a Tensor. Got %T instead", op, xdv.d)
+			return
+		}
+		idx, _ := reductionIndices(xShape, op.along)
+		return broadcastData(dx.Data(), dy, idx, true)
+	}
+
+	var T types.Tensor
+	switch ydvd := ydv.d.(type) {
+	case Scalar:
+		switch ydvd.t {
+		case Float64:
+			f := ydvd.v.(float64)
+			T = tf64.NewTensor(tf64.AsScalar(f))
+		case Float32:
+			f := ydvd.v.(float32)
+			T = tf32.NewTensor(tf32.AsScalar(f))
+		default:
+			err = nyi("sumOp.DoDiff", ydvd.t)
+			return
+		}
+	case Tensor:
+		T = ydvd.Tensor
+
+		// a reduced row vector has the shape (1, n) but its gradient may have the shape (n), which would be repeated as a column
+		if s := T.Shape(); len(s) != len(output.shape) && s.TotalSize() == output.shape.TotalSize() {
+			T = tensor.Clone(T)
+			if err = T.Reshape(output.shape...); err != nil {
+				err = errors.Wrapf(err, reshapeFail, output.shape, T.DataSize())
+				return
+			}
+		}
+	}
+
+	var val Value
+	if !T.Shape().Eq(xdv.d.Shape()) {
+		// TO DO: Optimize: figure out a way to bunch it all up so you can repeat in one call
+		for _, a := range op.along {
+			if xShape[a] == 1 {
+				continue // don't need to repeat
+			}
+			if T, err = tensor.Repeat(T, a, xShape[a]); err != nil {
+				err = errors.Wrapf(err, repFail, a, xShape[a])
+				return
+			}
+		}
+
+		val = FromTensor(T)
+	} else {
+		val = ydv.d
+	}
+
+	// then just add the two
+	add := newEBOByType(addOpType, xdv.d.Type(), val.Type())
+
+	var d Value
+	if d, err = add.UnsafeDo(xdv.d, val); err != nil {
+		err = errors.Wrapf(err, unsafeDoFail, add)
+		return
+	}
+
+	// check if xdv.d is scalar
+	if xdv.d.Type().isScalar() {
+		return xdv.SetDeriv(d)
+	}
+	return
+
+}
+
+func (op sumOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only one input for sumOp. GOt %v instead", len(inputs))
+		return
+	}
+
+	a := inputs[0]
+	at := a.(Tensor)
+	switch t := at.Tensor.(type) {
+	case *tf64.Tensor:
+		var ret *tf64.Tensor
+		if ret, err = t.Sum(op.along...); err == nil {
+			if ret.IsScalar() {
+				retVal = NewScalarValue(ret.ScalarValue())
+			} else {
+				retVal = FromTensor(ret)
+			}
+		}
+	case *tf32.Tensor:
+		var ret *tf32.Tensor
+		if ret, err = t.Sum(op.along...); err == nil {
+			if ret.IsScalar() {
+				retVal = NewScalarValue(ret.ScalarValue())
+			} else {
+				retVal = FromTensor(ret)
+			}
+		}
+	default:
+		err = nyi("sumOp.Do", at.Tensor)
+	}
+	return
+}
+
+func (op sumOp) returnsPtr() bool    { return true }
+func (op sumOp) overwriteInput() int { return 0 }
+func (op sumOp) callsExtern() bool   { return false }
+
+func (op sumOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("sum"))
+	fmt.Fprintf(h, "%v->%v", op.along, op.inputShape)
+}
+
+func (op sumOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op sumOp) String() string { return fmt.Sprintf("Σ%v", op.along) }
+func (op sumOp) isUnary() bool  { return true }
+
+/* LOGSUMEXP OP */
+
+// logSumExpOp computes log(Σ exp(x)) along an axis. The maximum of each group is subtracted before
+// the values are exponentiated, so large values do not overflow.
+type logSumExpOp struct {
+	along      int
+	d          int
+	inputShape types.Shape
+}
+
+func newLogSumExpOp(along int, s types.Shape, d int) logSumExpOp {
+	return logSumExpOp{
+		along:      along,
+		d:          d,
+		inputShape: s.Clone(),
+	}
+}
+
+// logSumExpOp is a function with this type:
+//		logSumExpOp :: (Floats a) ⇒ Tensor d a → Tensor d-1 a
+func (op logSumExpOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.d, a)
+	return newFunctionType(t, reductionRetType(op.d, axes{op.along}, op.inputShape, a))
+}
+
+func (op logSumExpOp) inferShape(t Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSumExpOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+	return reduceShape(op.inputShape, axes{op.along}), nil
+}
+
+func (op logSumExpOp) DiffWRT(i int) []bool { return []bool{true} }
+
+// SymDiff uses the fact that the derivative of logsumexp(x) is exp(x - logsumexp(x)), which is the softmax of x.
+func (op logSumExpOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSumExpOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var y, g, sm *Node
+	if y, err = keepDims(output, op.inputShape, axes{op.along}); err != nil {
+		return
+	}
+	if g, err = keepDims(gradNode, op.inputShape, axes{op.along}); err != nil {
+		return
+	}
+
+	if sm, err = Sub(inputs[0], y); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if sm, err = Exp(sm); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	retVal = make(Nodes, 1)
+	if retVal[0], err = HadamardProd(sm, g); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+// FwdDiff weighs the tangent with the softmax of the input: dy = Σ softmax(x) ⊙ dx
+func (op logSumExpOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSumExpOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var y, sm *Node
+	if y, err = keepDims(output, op.inputShape, axes{op.along}); err != nil {
+		return
+	}
+	if sm, err = Sub(inputs[0], y); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if sm, err = Exp(sm); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reductionFwdDiff(sm, tangents[0], axes{op.along})
+}
+
+func (op logSumExpOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSumExpOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var x types.Tensor
+	if x, err = tensorOf(op, xdv.Value); err != nil {
+		return
+	}
+
+	var y, dy interface{}
+	if y, err = valueData(ydv.Value); err != nil {
+		return
+	}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	idx, _ := reductionIndices(x.Shape(), axes{op.along})
+	return accumulateDeriv(xdv, func(d interface{}) error {
+		switch dx := d.(type) {
+		case []float64:
+			xs, ys, dys := x.Data().([]float64), y.([]float64), dy.([]float64)
+			for i, o := range idx {
+				dx[i] += math.Exp(xs[i]-ys[o]) * dys[o]
+			}
+		case []float32:
+			xs, ys, dys := x.Data().([]float32), y.([]float32), dy.([]float32)
+			for i, o := range idx {
+				dx[i] += float32(math.Exp(float64(xs[i]-ys[o]))) * dys[o]
+			}
+		default:
+			return nyi("logSumExpOp.DoDiff", d)
+		}
+		return nil
+	})
+}
+
+func (op logSumExpOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSumExpOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var t types.Tensor
+	if t, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var reduced interface{}
+	if reduced, _, err = logSumExp(op, t, op.along); err != nil {
+		return
+	}
+	return reducedValue(reduced, reduceShape(t.Shape(), axes{op.along}))
+}
+
+func (op logSumExpOp) returnsPtr() bool    { return false }
+func (op logSumExpOp) overwriteInput() int { return -1 }
+func (op logSumExpOp) callsExtern() bool   { return false }
+
+func (op logSumExpOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("logsumexp"))
+	fmt.Fprintf(h, "%v->%v", op.along, op.inputShape)
+}
+
+func (op logSumExpOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op logSumExpOp) String() string { return fmt.Sprintf("LogSumExp(%d)", op.along) }
+func (op logSumExpOp) isUnary() bool  { return true }
+
+/* LOGSOFTMAX OP */
+
+// logSoftMaxOp computes log(softmax(x)) along an axis, as x - logsumexp(x).
+type logSoftMaxOp struct {
+	along      int
+	d          int
+	inputShape types.Shape
+}
+
+func newLogSoftMaxOp(along int, s types.Shape, d int) logSoftMaxOp {
+	return logSoftMaxOp{
+		along:      along,
+		d:          d,
+		inputShape: s.Clone(),
+	}
+}
+
+// logSoftMaxOp is a function with this type:
+//		logSoftMaxOp :: (Floats a) ⇒ Tensor d a → Tensor d a
+func (op logSoftMaxOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.d, a)
+	return newFunctionType(t, t)
+}
+
+func (op logSoftMaxOp) inferShape(t Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSoftMaxOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+	return op.inputShape.Clone(), nil
+}
+
+func (op logSoftMaxOp) DiffWRT(i int) []bool { return []bool{true} }
+
+// SymDiff: if y = logsoftmax(x), then dx = dy - exp(y) * Σdy, with the sum taken along the axis.
+func (op logSoftMaxOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSoftMaxOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var sm, sum *Node
+	if sm, err = Exp(output); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if sum, err = Sum(gradNode, op.along); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if sum, err = keepDims(sum, op.inputShape, axes{op.along}); err != nil {
+		return
+	}
+	if sm, err = HadamardProd(sm, sum); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	retVal = make(Nodes, 1)
+	if retVal[0], err = Sub(gradNode, sm); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+// FwdDiff: dy = dx - Σ softmax(x) ⊙ dx
+func (op logSoftMaxOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSoftMaxOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var sm, dot *Node
+	if sm, err = Exp(output); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if dot, err = reductionFwdDiff(sm, tangents[0], axes{op.along}); err != nil {
+		return
+	}
+	if dot, err = keepDims(dot, op.inputShape, axes{op.along}); err != nil {
+		return
+	}
+	if retVal, err = Sub(tangents[0], dot); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+func (op logSoftMaxOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSoftMaxOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var y types.Tensor
+	if y, err = tensorOf(op, ydv.Value); err != nil {
+		return
+	}
+
+	var dy interface{}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	idx, size := reductionIndices(y.Shape(), axes{op.along})
+	return accumulateDeriv(xdv, func(d interface{}) error {
+		switch dx := d.(type) {
+		case []float64:
+			ys, dys := y.Data().([]float64), dy.([]float64)
+			sums := make([]float64, size)
+			for i, o := range idx {
+				sums[o] += dys[i]
+			}
+			for i, o := range idx {
+				dx[i] += dys[i] - math.Exp(ys[i])*sums[o]
+			}
+		case []float32:
+			ys, dys := y.Data().([]float32), dy.([]float32)
+			sums := make([]float32, size)
+			for i, o := range idx {
+				sums[o] += dys[i]
+			}
+			for i, o := range idx {
+				dx[i] += dys[i] - float32(math.Exp(float64(ys[i])))*sums[o]
+			}
+		default:
+			return nyi("logSoftMaxOp.DoDiff", d)
+		}
+		return nil
+	})
+}
+
+func (op logSoftMaxOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "logSoftMaxOp requires only one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var t types.Tensor
+	if t, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var reduced interface{}
+	var idx []int
+	if reduced, idx, err = logSumExp(op, t, op.along); err != nil {
+		return
+	}
+
+	switch data := t.Data().(type) {
+	case []float64:
+		lse := reduced.([]float64)
+		ret := make([]float64, len(data))
+		for i, o := range idx {
+			ret[i] = data[i] - lse[o]
+		}
+		retVal = FromTensor(tf64.NewTensor(tf64.WithShape(t.Shape()...), tf64.WithBacking(ret)))
+	case []float32:
+		lse := reduced.([]float32)
+		ret := make([]float32, len(data))
+		for i, o := range idx {
+			ret[i] = data[i] - lse[o]
+		}
+		retVal = FromTensor(tf32.NewTensor(tf32.WithShape(t.Shape()...), tf32.WithBacking(ret)))
+	}
+	return
+}
+
+func (op logSoftMaxOp) returnsPtr() bool    { return false }
+func (op logSoftMaxOp) overwriteInput() int { return -1 }
+func (op logSoftMaxOp) callsExtern() bool   { return false }
+
+func (op logSoftMaxOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("logsoftmax"))
+	fmt.Fprintf(h, "%v->%v", op.along, op.inputShape)
+}
+
+func (op logSoftMaxOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op logSoftMaxOp) String() string { return fmt.Sprintf("LogSoftMax(%d)", op.along) }
+func (op logSoftMaxOp) isUnary() bool  { return true }
+
+/* LOG OF SOFTMAX OP */
+
+// logOfSoftMaxOp is what Log(SoftMax(x)) is stabilized into. It takes both x and softmax(x), and is computed and
+// differentiated exactly like logSoftMaxOp, from x alone.
+//
+// softmax(x) is kept as an input so that the caller may still differentiate with regards to it. When they do, viaSoftMax
+// is set (see unstabilize), and the gradient flows through softmax(x) as the gradient of log(softmax(x)) would. That
+// gradient isn't stable: it is dy/softmax(x), which is infinite where softmax(x) underflows.
+type logOfSoftMaxOp struct {
+	lsm        logSoftMaxOp
+	viaSoftMax bool
+}
+
+func newLogOfSoftMaxOp(along int, s types.Shape, d int) logOfSoftMaxOp {
+	return logOfSoftMaxOp{lsm: newLogSoftMaxOp(along, s, d)}
+}
+
+// logOfSoftMaxOp is a function with this type:
+//		logOfSoftMaxOp :: (Floats a) ⇒ Tensor d a → Tensor d a → Tensor d a
+func (op logOfSoftMaxOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.lsm.d, a)
+	return newFunctionType(t, t, t)
+}
+
+func (op logOfSoftMaxOp) inferShape(t Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "logOfSoftMaxOp requires 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+	return op.lsm.inputShape.Clone(), nil
+}
+
+func (op logOfSoftMaxOp) DiffWRT(i int) []bool {
+	if op.viaSoftMax {
+		return []bool{false, true}
+	}
+	return []bool{true, false}
+}
+
+// SymDiff is logSoftMaxOp's. Via the softmax s, if y = log(s), then ds = dy / s
+func (op logOfSoftMaxOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "logOfSoftMaxOp requires 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	retVal = make(Nodes, 2)
+	if !op.viaSoftMax {
+		var dx Nodes
+		if dx, err = op.lsm.SymDiff(inputs[:1], output, gradNode); err != nil {
+			return
+		}
+		retVal[0] = dx[0]
+		return
+	}
+
+	if retVal[1], err = HadamardDiv(gradNode, inputs[1]); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[1].setGroup(gradClust)
+	return
+}
+
+// FwdDiff is logSoftMaxOp's. Via the softmax s, dy = ds / s
+func (op logOfSoftMaxOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "logOfSoftMaxOp requires 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	if !op.viaSoftMax {
+		return op.lsm.FwdDiff(inputs[:1], output, tangents[:1])
+	}
+
+	if retVal, err = HadamardDiv(tangents[1], inputs[1]); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+func (op logOfSoftMaxOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "logOfSoftMaxOp requires 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	if !op.viaSoftMax {
+		return op.lsm.DoDiff(inputs[:1], output)
+	}
+
+	sdv := inputs[1].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var s, dy interface{}
+	if s, err = valueData(sdv.Value); err != nil {
+		return
+	}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	return accumulateDeriv(sdv, func(d interface{}) error {
+		switch ds := d.(type) {
+		case []float64:
+			ss, dys := s.([]float64), dy.([]float64)
+			for i := range ds {
+				ds[i] += dys[i] / ss[i]
+			}
+		case []float32:
+			ss, dys := s.([]float32), dy.([]float32)
+			for i := range ds {
+				ds[i] += dys[i] / ss[i]
+			}
+		default:
+			return nyi("logOfSoftMaxOp.DoDiff", d)
+		}
+		return nil
+	})
+}
+
+func (op logOfSoftMaxOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "logOfSoftMaxOp requires 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+	return op.lsm.Do(inputs[0])
+}
+
+func (op logOfSoftMaxOp) returnsPtr() bool    { return false }
+func (op logOfSoftMaxOp) overwriteInput() int { return -1 }
+func (op logOfSoftMaxOp) callsExtern() bool   { return false }
+
+// viaSoftMax doesn't change the value, so it isn't hashed
+func (op logOfSoftMaxOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("logofsoftmax"))
+	fmt.Fprintf(h, "%v->%v", op.lsm.along, op.lsm.inputShape)
+}
+
+func (op logOfSoftMaxOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op logOfSoftMaxOp) String() string { return fmt.Sprintf("Log(SoftMax(%d))", op.lsm.along) }
+func (op logOfSoftMaxOp) isBinary() bool { return true }
+
+/* NORM OP */
+
+// normOp computes the p-norm along the given axes. p may be +Inf, for the maximum norm.
+type normOp struct {
+	p     float64
+	along axes
+	d     int
+
+	inputShape types.Shape
+}
+
+func newNormOp(p float64, along axes, s types.Shape, d int) normOp {
+	return normOp{
+		p:          p,
+		along:      along,
+		d:          d,
+		inputShape: s,
+	}
+}
+
+// normOp is a function with this type:
+//		normOp :: (Floats a) ⇒ Tensor d a → Tensor d-len(along) a
+func (op normOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.d, a)
+	return newFunctionType(t, reductionRetType(op.d, op.along, op.inputShape, a))
+}
+
+func (op normOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
+	if len(inputs) != 1 {
+		return nil, NewError(GraphError, "normOp requires only one input")
+	}
+	return reduceShape(inputs[0].shape, op.along), nil
+}
+
+func (op normOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op normOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	diff := normDiffOp{normOp: op}
+	retVal = make(Nodes, 1)
+	if retVal[0], err = applyOp(diff, inputs[0], output, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+func (op normOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
+	return gradFwdDiff(normDiffOp{normOp: op}, output, tangents[0], op.along, inputs[0], output)
+}
+
+func (op normOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	diff := normDiffOp{normOp: op}
+	var d Value
+	if d, err = diff.Do(xdv.Value, ydv.Value, ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+func (op normOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expected only one input for normOp. Got %d instead", len(inputs))
+		return
+	}
+
+	var t types.Tensor
+	if t, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	s := t.Shape()
+	for _, a := range op.along {
+		if a >= len(s) {
+			err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", a, s)
+			return
+		}
+	}
+
+	idx, size := reductionIndices(s, op.along)
+	switch data := t.Data().(type) {
+	case []float64:
+		return reducedValue(norms(op.p, data, idx, size), reduceShape(s, op.along))
+	case []float32:
+		return reducedValue(f64sTof32s(norms(op.p, f32sTof64s(data), idx, size)), reduceShape(s, op.along))
+	}
+	err = nyi(op.String(), t.Dtype())
+	return
+}
+
+func (op normOp) returnsPtr() bool    { return false }
+func (op normOp) overwriteInput() int { return -1 }
+func (op normOp) callsExtern() bool   { return false }
+
+func (op normOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("norm"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%v:%v->%v", op.p, op.along, op.inputShape)
+}
+
+func (op normOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op normOp) String() string { return fmt.Sprintf("Norm(%v)Along%v", op.p, op.along) }
+func (op normOp) isUnary() bool  { return true }
+
+// normDiffOp is the gradient of normOp. It takes the input of the norm, the norm and the gradient of the norm.
+// Where the norm is 0, the gradient is 0 (which is a valid subgradient), so there are no NaNs.
+type normDiffOp struct {
+	normOp
+}
+
+// normDiffOp is a function with this type:
+//		normDiffOp :: (Floats a) ⇒ Tensor d a → b → b → Tensor d a
+// where b is the type of the norm
+func (op normDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.d, a)
+	r := reductionRetType(op.d, op.along, op.inputShape, a)
+	return newFunctionType(t, r, r, t)
+}
+
+func (op normDiffOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
+	if len(inputs) != 3 {
+		return nil, NewError(GraphError, "normDiffOp requires three inputs. Got %d instead", len(inputs))
+	}
+	return inputs[0].shape.Clone(), nil
+}
+
+// DiffWRT states that normDiffOp is differentiable wrt the input and the gradient. The norm is a function of the input,
+// so its contribution is folded into the gradient wrt the input.
+func (op normDiffOp) DiffWRT(i int) []bool { return []bool{true, false, true} }
+
+// SymDiff differentiates the gradient of the norm. With u = ∂y/∂x, the Hessian of the norm of a group is
+//		(p-1)/y ((|x|/y)^(p-2) I - u uᵀ)
+// which is 0 for the 1-norm and the ∞-norm.
+func (op normDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "normDiffOp requires three inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	x, y, g := inputs[0], inputs[1], inputs[2]
+	retVal = make(Nodes, 3)
+	if retVal[2], err = gradFwdDiff(op, y, gradNode, op.along, x, y); err != nil {
+		return
+	}
+	retVal[2].setGroup(gradClust)
+
+	if op.p == 1 || math.IsInf(op.p, 1) {
+		retVal[0], err = zeroGrad(x)
+		return
+	}
+
+	var ones, u, uh, yk, gk, h *Node
+	if ones, err = onesLike(g); err != nil {
+		return
+	}
+	if u, err = applyOp(op, x, y, ones); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if uh, err = reductionFwdDiff(u, gradNode, op.along); err != nil {
+		return
+	}
+	if uh, err = keepDims(uh, x.shape, op.along); err != nil {
+		return
+	}
+	if yk, err = keepDims(y, x.shape, op.along); err != nil {
+		return
+	}
+	if gk, err = keepDims(g, x.shape, op.along); err != nil {
+		return
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, x.t)
+		return
+	}
+
+	h = gradNode
+	if op.p != 2 {
+		var abs, ratio, exp *Node
+		if exp, err = floatConstant(dt, op.p-2); err != nil {
+			return
+		}
+		if abs, err = Abs(x); err == nil {
+			if ratio, err = HadamardDiv(abs, yk); err == nil {
+				if ratio, err = binOpNode(newElemBinOp(powOpType, ratio, exp), ratio, exp); err == nil {
+					h, err = HadamardProd(ratio, h)
+				}
+			}
+		}
+		if err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+
+	var scale, coef *Node
+	if uh, err = HadamardProd(uh, u); err == nil {
+		if h, err = Sub(h, uh); err == nil {
+			if coef, err = HadamardDiv(gk, yk); err == nil {
+				retVal[0], err = HadamardProd(coef, h)
+			}
+		}
+	}
+	if err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	if op.p != 2 {
+		if scale, err = floatConstant(dt, op.p-1); err != nil {
+			return
+		}
+		if retVal[0], err = Mul(scale, retVal[0]); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+func (op normDiffOp) DoDiff(inputs Nodes, output *Node) (err error) { return nondiffErr(op) }
+
+func (op normDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "normDiffOp requires three inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var x types.Tensor
+	if x, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var y, dy interface{}
+	if y, err = valueData(inputs[1]); err != nil {
+		return
+	}
+	if dy, err = valueData(inputs[2]); err != nil {
+		return
+	}
+
+	idx, size := reductionIndices(x.Shape(), op.along)
+	switch data := x.Data().(type) {
+	case []float64:
+		ys, ok1 := y.([]float64)
+		dys, ok2 := dy.([]float64)
+		if !ok1 || !ok2 || len(ys) != size || len(dys) != size {
+			err = NewError(RuntimeError, "%v expected the norms and their gradients to be %d float64s", op, size)
+			return
+		}
+		dx := normDiff(op.p, data, ys, dys, idx)
+		retVal = FromTensor(tf64.NewTensor(tf64.WithShape(x.Shape()...), tf64.WithBacking(dx)))
+	case []float32:
+		ys, ok1 := y.([]float32)
+		dys, ok2 := dy.([]float32)
+		if !ok1 || !ok2 || len(ys) != size || len(dys) != size {
+			err = NewError(RuntimeError, "%v expected the norms and their gradients to be %d float32s", op, size)
+			return
+		}
+		dx := normDiff(op.p, f32sTof64s(data), f32sTof64s(ys), f32sTof64s(dys), idx)
+		retVal = FromTensor(tf32.NewTensor(tf32.WithShape(x.Shape()...), tf32.WithBacking(f64sTof32s(dx))))
+	default:
+		err = nyi("normDiffOp", x.Dtype())
+	}
+	return
+}
+
+func (op normDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("normDiff"))
+	op.normOp.WriteHash(h)
+}
+
+func (op normDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op normDiffOp) String() string { return fmt.Sprintf("NormDiff(%v)Along%v", op.p, op.along) }
+
+// norms computes the p-norm of each group of data. idx maps each element to its group.
+func norms(p float64, data []float64, idx []int, size int) []float64 {
+	retVal := make([]float64, size)
+	for i, o := range idx {
+		a := math.Abs(data[i])
+		switch {
+		case p == 1:
+			retVal[o] += a
+		case p == 2:
+			retVal[o] += a * a
+		case math.IsInf(p, 1):
+			retVal[o] = math.Max(retVal[o], a)
+		default:
+			retVal[o] += math.Pow(a, p)
+		}
+	}
+
+	switch {
+	case p == 2:
+		for o, v := range retVal {
+			retVal[o] = math.Sqrt(v)
+		}
+	case p != 1 && !math.IsInf(p, 1):
+		for o, v := range retVal {
+			retVal[o] = math.Pow(v, 1/p)
+		}
+	}
+	return retVal
+}
+
+// normDiff computes the (sub)gradient of the p-norms y of the groups of data, given their gradients dy:
+//		p = 1: sign(x)
+//		p = 2: x/y
+//		p = ∞: sign(x) for the first element where |x| = y, like Argmax
+//		otherwise: sign(x) (|x|/y)^(p-1)
+// The gradient of a group with a norm of 0 is 0.
+func normDiff(p float64, data, y, dy []float64, idx []int) []float64 {
+	retVal := make([]float64, len(data))
+	taken := make([]bool, len(y))
+	for i, o := range idx {
+		x := data[i]
+		if y[o] == 0 || x == 0 {
+			continue
+		}
+
+		var d float64
+		switch {
+		case p == 1:
+			d = 1
+		case p == 2:
+			d = math.Abs(x) / y[o]
+		case math.IsInf(p, 1):
+			if math.Abs(x) != y[o] || taken[o] {
+				continue
+			}
+			d = 1
+			taken[o] = true
+		default:
+			d = math.Pow(math.Abs(x)/y[o], p-1)
+		}
+		retVal[i] = math.Copysign(d, x) * dy[o]
+	}
+	return retVal
+}
+
+/* VARIANCE OP */
+
+// varianceOp computes the variance (or the standard deviation, if std is set) along the given axes.
+// The sum of the squared deviations is divided by N - ddof, where N is the number of elements in each group.
+type varianceOp struct {
+	ddof  int
+	std   bool
+	along axes
+	d     int
+
+	inputShape types.Shape
+}
+
+func newVarianceOp(ddof int, std bool, along axes, s types.Shape, d int) varianceOp {
+	return varianceOp{
+		ddof:       ddof,
+		std:        std,
+		along:      along,
+		d:          d,
+		inputShape: s,
+	}
+}
+
+// varianceOp is a function with this type:
+//		varianceOp :: (Floats a) ⇒ Tensor d a → Tensor d-len(along) a
+func (op varianceOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.d, a)
+	return newFunctionType(t, reductionRetType(op.d, op.along, op.inputShape, a))
+}
+
+func (op varianceOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
+	if len(inputs) != 1 {
+		return nil, NewError(GraphError, "varianceOp requires only one input")
+	}
+	return reduceShape(inputs[0].shape, op.along), nil
+}
+
+func (op varianceOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op varianceOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	diff := varianceDiffOp{varianceOp: op}
+	retVal = make(Nodes, 1)
+	if retVal[0], err = applyOp(diff, inputs[0], output, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+func (op varianceOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (retVal *Node, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
+	return gradFwdDiff(varianceDiffOp{varianceOp: op}, output, tangents[0], op.along, inputs[0], output)
+}
+
+func (op varianceOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expect only 1 input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	diff := varianceDiffOp{varianceOp: op}
+	var d Value
+	if d, err = diff.Do(xdv.Value, ydv.Value, ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, diff)
+		return
+	}
+	return addInto(inputs[0], d)
+}
+
+func (op varianceOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "Expected only one input for varianceOp. Got %d instead", len(inputs))
+		return
+	}
+
+	var t types.Tensor
+	if t, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	s := t.Shape()
+	for _, a := range op.along {
+		if a >= len(s) {
+			err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", a, s)
+			return
+		}
+	}
+
+	idx, size := reductionIndices(s, op.along)
+	switch data := t.Data().(type) {
+	case []float64:
+		return reducedValue(variances(data, idx, size, op.ddof, op.std), reduceShape(s, op.along))
+	case []float32:
+		return reducedValue(f64sTof32s(variances(f32sTof64s(data), idx, size, op.ddof, op.std)), reduceShape(s, op.along))
+	}
+	err = nyi(op.String(), t.Dtype())
+	return
+}
+
+func (op varianceOp) returnsPtr() bool    { return false }
+func (op varianceOp) overwriteInput() int { return -1 }
+func (op varianceOp) callsExtern() bool   { return false }
+
+func (op varianceOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("variance"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%d%t:%v->%v", op.ddof, op.std, op.along, op.inputShape)
+}
+
+func (op varianceOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op varianceOp) String() string {
+	if op.std {
+		return fmt.Sprintf("Std(%d)Along%v", op.ddof, op.along)
+	}
+	return fmt.Sprintf("Var(%d)Along%v", op.ddof, op.along)
+}
+
+func (op varianceOp) isUnary() bool { return true }
+
+// varianceDiffOp is the gradient of varianceOp. It takes the input, the variance (or standard deviation) and its gradient.
+// The means are recomputed from the input. Where the standard deviation is 0, its gradient is 0.
+type varianceDiffOp struct {
+	varianceOp
+}
+
+// varianceDiffOp is a function with this type:
+//		varianceDiffOp :: (Floats a) ⇒ Tensor d a → b → b → Tensor d a
+// where b is the type of the variance
+func (op varianceDiffOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.d, a)
+	r := reductionRetType(op.d, op.along, op.inputShape, a)
+	return newFunctionType(t, r, r, t)
+}
+
+func (op varianceDiffOp) inferShape(t Type, inputs ...*Node) (types.Shape, error) {
+	if len(inputs) != 3 {
+		return nil, NewError(GraphError, "varianceDiffOp requires three inputs. Got %d instead", len(inputs))
+	}
+	return inputs[0].shape.Clone(), nil
+}
+
+// DiffWRT states that varianceDiffOp is differentiable wrt the input and the gradient. The variance is a function of the
+// input, so its contribution is folded into the gradient wrt the input.
+func (op varianceDiffOp) DiffWRT(i int) []bool { return []bool{true, false, true} }
+
+// SymDiff differentiates the gradient of the variance. With C = I - 11ᵀ/N, the Hessian of the variance of a group is
+//		2C/(N - ddof)
+// and with u = ∂s/∂x, the Hessian of the standard deviation s is
+//		C/((N - ddof) s) - u uᵀ/s
+func (op varianceDiffOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "varianceDiffOp requires three inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	x, y, g := inputs[0], inputs[1], inputs[2]
+	retVal = make(Nodes, 3)
+	if retVal[2], err = gradFwdDiff(op, y, gradNode, op.along, x, y); err != nil {
+		return
+	}
+	retVal[2].setGroup(gradClust)
+
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, x.t)
+		return
+	}
+
+	n := 1
+	for _, a := range op.along {
+		n *= x.shape[a]
+	}
+
+	// the tangent, centered within each group
+	var invN, mean, centered *Node
+	if invN, err = floatConstant(dt, 1/float64(n)); err != nil {
+		return
+	}
+	if mean, err = Sum(gradNode, op.along...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if mean, err = keepDims(mean, x.shape, op.along); err != nil {
+		return
+	}
+	if mean, err = Mul(invN, mean); err == nil {
+		centered, err = Sub(gradNode, mean)
+	}
+	if err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+
+	var scale, gk *Node
+	if gk, err = keepDims(g, x.shape, op.along); err != nil {
+		return
+	}
+
+	if !op.std {
+		if scale, err = floatConstant(dt, 2/float64(n-op.ddof)); err != nil {
+			return
+		}
+		if retVal[0], err = Mul(scale, centered); err == nil {
+			retVal[0], err = HadamardProd(gk, retVal[0])
+		}
+		if err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+		retVal[0].setGroup(gradClust)
+		return
+	}
+
+	var ones, u, uh, sk *Node
+	if ones, err = onesLike(g); err != nil {
+		return
+	}
+	if u, err = applyOp(op, x, y, ones); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	if uh, err = reductionFwdDiff(u, gradNode, op.along); err != nil {
+		return
+	}
+	if uh, err = keepDims(uh, x.shape, op.along); err != nil {
+		return
+	}
+	if sk, err = keepDims(y, x.shape, op.along); err != nil {
+		return
+	}
+	if scale, err = floatConstant(dt, 1/float64(n-op.ddof)); err != nil {
+		return
+	}
+
+	var coef *Node
+	if centered, err = Mul(scale, centered); err == nil {
+		if uh, err = HadamardProd(uh, u); err == nil {
+			if centered, err = Sub(centered, uh); err == nil {
+				if coef, err = HadamardDiv(gk, sk); err == nil {
+					retVal[0], err = HadamardProd(coef, centered)
+				}
+			}
+		}
+	}
+	if err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+func (op varianceDiffOp) DoDiff(inputs Nodes, output *Node) (err error) { return nondiffErr(op) }
+
+func (op varianceDiffOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 3 {
+		err = NewError(GraphError, "varianceDiffOp requires three inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var x types.Tensor
+	if x, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var y, dy interface{}
+	if y, err = valueData(inputs[1]); err != nil {
+		return
+	}
+	if dy, err = valueData(inputs[2]); err != nil {
+		return
+	}
+
+	idx, size := reductionIndices(x.Shape(), op.along)
+	switch data := x.Data().(type) {
+	case []float64:
+		ys, ok1 := y.([]float64)
+		dys, ok2 := dy.([]float64)
+		if !ok1 || !ok2 || len(ys) != size || len(dys) != size {
+			err = NewError(RuntimeError, "%v expected the variances and their gradients to be %d float64s", op, size)
+			return
+		}
+		dx := varianceDiff(data, ys, dys, idx, op.ddof, op.std)
+		retVal = FromTensor(tf64.NewTensor(tf64.WithShape(x.Shape()...), tf64.WithBacking(dx)))
+	case []float32:
+		ys, ok1 := y.([]float32)
+		dys, ok2 := dy.([]float32)
+		if !ok1 || !ok2 || len(ys) != size || len(dys) != size {
+			err = NewError(RuntimeError, "%v expected the variances and their gradients to be %d float32s", op, size)
+			return
+		}
+		dx := varianceDiff(f32sTof64s(data), f32sTof64s(ys), f32sTof64s(dys), idx, op.ddof, op.std)
+		retVal = FromTensor(tf32.NewTensor(tf32.WithShape(x.Shape()...), tf32.WithBacking(f64sTof32s(dx))))
+	default:
+		err = nyi("varianceDiffOp", x.Dtype())
+	}
+	return
+}
+
+func (op varianceDiffOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("varianceDiff"))
+	op.varianceOp.WriteHash(h)
+}
+
+func (op varianceDiffOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op varianceDiffOp) String() string { return fmt.Sprintf("%vDiff", op.varianceOp) }
+
+// variances computes the variance (or standard deviation) of each group of data in a single pass, with Welford's algorithm.
+// idx maps each element to its group.
+func variances(data []float64, idx []int, size, ddof int, std bool) []float64 {
+	counts := make([]int, size)
+	means := make([]float64, size)
+	m2s := make([]float64, size)
+	for i, o := range idx {
+		counts[o]++
+		delta := data[i] - means[o]
+		means[o] += delta / float64(counts[o])
+		m2s[o] += delta * (data[i] - means[o])
+	}
+
+	for o, m2 := range m2s {
+		m2s[o] = m2 / float64(counts[o]-ddof)
+		if std {
+			m2s[o] = math.Sqrt(m2s[o])
+		}
+	}
+	return m2s
+}
+
+// varianceDiff computes the gradients of the variances (or standard deviations) y of the groups of data, given their gradients dy:
+//		variance: 2(x - mean) / (N - ddof)
+//		standard deviation: (x - mean) / ((N - ddof) y)
+func varianceDiff(data, y, dy []float64, idx []int, ddof int, std bool) []float64 {
+	counts := make([]int, len(y))
+	means := make([]float64, len(y))
+	for i, o := range idx {
+		counts[o]++
+		means[o] += (data[i] - means[o]) / float64(counts[o])
+	}
+
+	retVal := make([]float64, len(data))
+	for i, o := range idx {
+		n := float64(counts[o] - ddof)
+		switch {
+		case !std:
+			retVal[i] = 2 * (data[i] - means[o]) / n * dy[o]
+		case y[o] != 0:
+			retVal[i] = (data[i] - means[o]) / (n * y[o]) * dy[o]
+		}
+	}
+	return retVal
+}
+
+// logSumExp computes log(Σ exp(x)) of t along an axis, subtracting the maximum of each group first.
+// The indices that map each element of t to its group are returned as well.
+func logSumExp(op Op, t types.Tensor, along int) (reduced interface{}, idx []int, err error) {
+	s := t.Shape()
+	if along >= len(s) {
+		err = NewError(ShapeError, "Axis %d is greater or equal to the length of the shape %v", along, s)
+		return
+	}
+
+	var size int
+	idx, size = reductionIndices(s, axes{along})
+	seen := make([]bool, size)
+	switch data := t.Data().(type) {
+	case []float64:
+		max := make([]float64, size)
+		for i, o := range idx {
+			if !seen[o] || data[i] > max[o] {
+				max[o] = data[i]
+				seen[o] = true
+			}
+		}
+
+		sum := make([]float64, size)
+		for i, o := range idx {
+			if !math.IsInf(max[o], 0) {
+				sum[o] += math.Exp(data[i] - max[o])
+			}
+		}
+
+		for o := range sum {
+			if !math.IsInf(max[o], 0) {
+				sum[o] = max[o] + math.Log(sum[o])
+			} else {
+				sum[o] = max[o]
+			}
+		}
+		reduced = sum
+	case []float32:
+		max := make([]float32, size)
+		for i, o := range idx {
+			if !seen[o] || data[i] > max[o] {
+				max[o] = data[i]
+				seen[o] = true
+			}
+		}
+
+		sum := make([]float64, size)
+		for i, o := range idx {
+			if !math.IsInf(float64(max[o]), 0) {
+				sum[o] += math.Exp(float64(data[i] - max[o]))
+			}
+		}
+
+		ret := make([]float32, size)
+		for o := range sum {
+			if !math.IsInf(float64(max[o]), 0) {
+				ret[o] = max[o] + float32(math.Log(sum[o]))
+			} else {
+				ret[o] = max[o]
+			}
+		}
+		reduced = ret
+	default:
+		err = nyi(op.String(), t.Dtype())
+	}
+	return
+}
+
+// reducedValue wraps the data of a reduction into a Value of the reduced shape
+func reducedValue(data interface{}, s types.Shape) (retVal Value, err error) {
+	switch d := data.(type) {
+	case []float64:
+		if s.IsScalar() {
+			return NewScalarValue(d[0]), nil
+		}
+		retVal = FromTensor(tf64.NewTensor(tf64.WithShape(s...), tf64.WithBacking(d)))
+	case []float32:
+		if s.IsScalar() {
+			return NewScalarValue(d[0]), nil
+		}
+		retVal = FromTensor(tf32.NewTensor(tf32.WithShape(s...), tf32.WithBacking(d)))
+	default:
+		err = nyi("reducedValue", data)
+	}
+	return
+}
+
+// gradFwdDiff is the forward derivative of a reduction into y, given diff, the op that computes the gradient of its input from
+// diffInputs and the gradient of y. As every element of the input is reduced into one element of y, the gradient when the gradient
+// of y is all ones holds the partial derivatives that reductionFwdDiff needs.
+func gradFwdDiff(diff Op, y, tangent *Node, along axes, diffInputs ...*Node) (retVal *Node, err error) {
+	var ones, partials *Node
+	if ones, err = onesLike(y); err != nil {
+		return
+	}
+	if partials, err = applyOp(diff, append(diffInputs, ones)...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	return reductionFwdDiff(partials, tangent, along)
+}
+
+// keepDims reshapes n, the result of reducing a tensor of shape s along the given axes, so that the reduced axes
+// are kept with a size of 1. The result can then be broadcast against the unreduced tensor. Scalars are returned as is.
+func keepDims(n *Node, s types.Shape, along axes) (retVal *Node, err error) {
+	if n.IsScalar() {
+		return n, nil
+	}
+
+	keep := s.Clone()
+	for _, a := range along {
+		keep[a] = 1
+	}
+	if retVal, err = Reshape(n, keep...); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
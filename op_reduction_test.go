@@ -1,8 +1,13 @@
 package gorgonia
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
+	tf32 "github.com/chewxy/gorgonia/tensor/f32"
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -187,3 +192,439 @@ func TestSumOpDiff(t *testing.T) {
 	assert.Equal(z.Value(), c.Value())
 
 }
+
+func TestMaxMinOp(t *testing.T) {
+	assert := assert.New(t)
+	backing := []float64{1, 5, 3, 4, 2, 6}
+
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(backing))))
+	max := Must(Max(x, 1))
+	min := Must(Min(x, 0))
+	all := Must(Min(x))
+	assert.Equal(types.Shape{2}, max.Shape())
+	assert.Equal(types.Shape{3}, min.Shape())
+	assert.True(all.IsScalar())
+
+	cost := Must(Add(Must(Sum(max)), Must(Sum(min))))
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]float64{5, 6}, max.Value().(Tensor).Tensor.Data())
+	assert.Equal([]float64{1, 2, 3}, min.Value().(Tensor).Tensor.Data())
+	assert.Equal(1.0, all.Value().(Scalar).v)
+
+	xG, _ := x.Grad()
+	assert.Equal([]float64{1, 1, 1, 0, 1, 1}, xG.(Tensor).Tensor.Data())
+
+	// lisp machine
+	g2 := NewGraph()
+	x2 := NewMatrix(g2, Float64, WithName("x"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(backing))))
+	Must(Add(Must(Sum(Must(Max(x2, 1)))), Must(Sum(Must(Min(x2, 0))))))
+
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	x2G, _ := x2.Grad()
+	assert.Equal([]float64{1, 1, 1, 0, 1, 1}, x2G.(Tensor).Tensor.Data())
+
+	// ties: only the first of the tied elements gets the gradient, like Argmax
+	tied := []float64{3, 1, 3, 1}
+	tieCases := []struct {
+		reduce func(*Node, ...int) (*Node, error)
+		want   []float64
+	}{
+		{Max, []float64{1, 0, 0, 0}},
+		{Min, []float64{0, 1, 0, 0}},
+	}
+	for _, tc := range tieCases {
+		g4 := NewGraph()
+		x4 := NewVector(g4, Float64, WithName("x"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking(tied))))
+		cost4 := Must(tc.reduce(x4))
+		if _, err = Grad(cost4, x4); err != nil {
+			t.Fatal(err)
+		}
+
+		prog4, locMap4, err := Compile(g4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m4 := NewTapeMachine(prog4, locMap4)
+		if err = m4.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		x4G, _ := x4.Grad()
+		assert.Equal(tc.want, x4G.(Tensor).Tensor.Data())
+
+		g5 := NewGraph()
+		x5 := NewVector(g5, Float64, WithName("x"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking(tied))))
+		Must(tc.reduce(x5))
+		m5 := NewLispMachine(g5)
+		if err = m5.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		x5G, _ := x5.Grad()
+		assert.Equal(tc.want, x5G.(Tensor).Tensor.Data())
+	}
+
+	// higher dimensions
+	g3 := NewGraph()
+	x3 := NewTensor(g3, Float64, 3, WithName("x"), WithShape(2, 3, 4), WithInit(RangedFrom(0)))
+	max3 := Must(Max(x3, 0, 2))
+	assert.Equal(types.Shape{3}, max3.Shape())
+
+	m3 := NewLispMachine(g3, ExecuteFwdOnly())
+	if err = m3.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{15, 19, 23}, max3.Value().(Tensor).Tensor.Data())
+
+	_, err = Min(x3, 3)
+	assert.NotNil(err)
+}
+
+func TestArgOps(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking([]float64{1, 5, 3, 4, 2, 6}))))
+	v := NewVector(g, Float64, WithName("v"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking([]float64{3, 1, 3, 1}))))
+	y := NewTensor(g, Float64, 3, WithName("y"), WithShape(2, 3, 2), WithInit(RangedFrom(0)))
+
+	amax0 := Must(Argmax(x, 0))
+	amax1 := Must(Argmax(x, 1))
+	amin1 := Must(Argmin(x, 1))
+	vmax := Must(Argmax(v, 0))
+	vmin := Must(Argmin(v, 0))
+	ymin := Must(Argmin(y, 1))
+
+	assert.Equal(types.Shape{3}, amax0.Shape())
+	assert.Equal(types.Shape{2}, amax1.Shape())
+	assert.True(vmax.IsScalar())
+	assert.Equal(types.Shape{2, 2}, ymin.Shape())
+	assert.Equal(Int, vmax.t)
+
+	m := NewLispMachine(g, ExecuteFwdOnly())
+	if err := m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]int{1, 0, 1}, amax0.Value().(Tensor).Tensor.Data())
+	assert.Equal([]int{1, 2}, amax1.Value().(Tensor).Tensor.Data())
+	assert.Equal([]int{0, 1}, amin1.Value().(Tensor).Tensor.Data())
+	assert.Equal(0, vmax.Value().(Scalar).v)
+	assert.Equal(1, vmin.Value().(Scalar).v)
+	assert.Equal([]int{0, 0, 0, 0}, ymin.Value().(Tensor).Tensor.Data())
+
+	_, err := Argmax(x, 2)
+	assert.NotNil(err)
+}
+
+func TestLogSumExpOps(t *testing.T) {
+	assert := assert.New(t)
+	// the first row would overflow if the max wasn't subtracted
+	backing := []float64{1000, 1001, 1002, -1, 0, 1}
+	weights := []float64{1, 2, 3, -1, 0, 1}
+	rows, cols := 2, 3
+
+	for axis := 0; axis < 2; axis++ {
+		name := fmt.Sprintf("axis %d", axis)
+
+		// group returns the indices of the elements that are reduced together
+		groups := [][]int{{0, 1, 2}, {3, 4, 5}}
+		if axis == 0 {
+			groups = [][]int{{0, 3}, {1, 4}, {2, 5}}
+		}
+
+		lse := make([]float64, len(groups))
+		lsm := make([]float64, rows*cols)
+		lseGrad := make([]float64, rows*cols)
+		lsmGrad := make([]float64, rows*cols)
+		for o, group := range groups {
+			max := math.Inf(-1)
+			for _, i := range group {
+				max = math.Max(max, backing[i])
+			}
+			var sum, wsum float64
+			for _, i := range group {
+				sum += math.Exp(backing[i] - max)
+				wsum += weights[i]
+			}
+			lse[o] = max + math.Log(sum)
+			for _, i := range group {
+				lsm[i] = backing[i] - lse[o]
+				lseGrad[i] = math.Exp(lsm[i])
+				lsmGrad[i] = weights[i] - math.Exp(lsm[i])*wsum
+			}
+		}
+
+		// the cost is sum(LogSumExp(x)) + sum(LogSoftMax(x) * w)
+		build := func() (g *ExprGraph, x, y, z, cost *Node) {
+			g = NewGraph()
+			x = NewMatrix(g, Float64, WithName("x"), WithShape(rows, cols), WithValue(tf64.NewTensor(tf64.WithShape(rows, cols), tf64.WithBacking(backing))))
+			w := NewMatrix(g, Float64, WithName("w"), WithShape(rows, cols), WithValue(tf64.NewTensor(tf64.WithShape(rows, cols), tf64.WithBacking(weights))))
+			y = Must(LogSumExp(x, axis))
+			z = Must(LogSoftMax(x, axis))
+			cost = Must(Add(Must(Sum(y)), Must(Sum(Must(HadamardProd(z, w))))))
+			return
+		}
+
+		g, x, y, z, cost := build()
+		assert.Equal(types.Shape{len(groups)}, y.Shape(), name)
+		assert.Equal(types.Shape{rows, cols}, z.Shape(), name)
+		if _, err := Grad(cost, x); err != nil {
+			t.Fatal(err)
+		}
+
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := NewTapeMachine(prog, locMap)
+		if err = m.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		xG, _ := x.Grad()
+		xGData := extractF64s(xG)
+		for i := range lseGrad {
+			assert.InDelta(lseGrad[i]+lsmGrad[i], xGData[i], 1e-10, name)
+		}
+
+		// lisp machine
+		_, x2, y2, z2, _ := build()
+		m2 := NewLispMachine(x2.g)
+		if err = m2.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		yData := extractF64s(y2.Value())
+		for i, v := range lse {
+			assert.InDelta(v, yData[i], 1e-10, name)
+		}
+		zData := extractF64s(z2.Value())
+		for i, v := range lsm {
+			assert.InDelta(v, zData[i], 1e-10, name)
+		}
+		x2G, _ := x2.Grad()
+		x2GData := extractF64s(x2G)
+		for i := range lseGrad {
+			assert.InDelta(lseGrad[i]+lsmGrad[i], x2GData[i], 1e-10, name)
+		}
+	}
+
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 3))
+	_, err := LogSoftMax(x, 2)
+	assert.NotNil(err)
+}
+
+func TestNormOp(t *testing.T) {
+	assert := assert.New(t)
+	// the second row is all zeroes, so its gradients would be NaNs if the norm wasn't stable at zero
+	backing := []float64{3, -4, 0, 0, 0, 0}
+	y3 := math.Cbrt(91)
+
+	norms := []struct {
+		p       float64
+		correct []float64
+		grad    []float64 // of the first row
+	}{
+		{1, []float64{7, 0}, []float64{1, -1, 0}},
+		{2, []float64{5, 0}, []float64{0.6, -0.8, 0}},
+		{3, []float64{y3, 0}, []float64{9 / (y3 * y3), -16 / (y3 * y3), 0}},
+		{math.Inf(1), []float64{4, 0}, []float64{0, -1, 0}},
+	}
+
+	for _, nt := range norms {
+		name := fmt.Sprintf("p = %v", nt.p)
+
+		// the cost is the sum of the norms of each row, weighted by 1 and 2
+		build := func() (g *ExprGraph, x, y *Node) {
+			g = NewGraph()
+			x = NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(backing))))
+			w := NewVector(g, Float64, WithName("w"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking([]float64{1, 2}))))
+			y = Must(Norm(x, nt.p, 1))
+			Must(Sum(Must(HadamardProd(y, w))))
+			return
+		}
+
+		g, x, y := build()
+		assert.Equal(types.Shape{2}, y.Shape(), name)
+		if _, err := Grad(g.Roots()[0], x); err != nil {
+			t.Fatal(err)
+		}
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = NewTapeMachine(prog, locMap, WithNaNWatch()).RunAll(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		xG, _ := x.Grad()
+		for i, v := range extractF64s(xG) {
+			if i < 3 {
+				assert.InDelta(nt.grad[i], v, 1e-12, name)
+			} else {
+				assert.Equal(0.0, v, name)
+			}
+		}
+
+		// lisp machine
+		_, x, y = build()
+		if err = NewLispMachine(x.g, WithNaNWatch()).RunAll(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for i, v := range extractF64s(y.Value()) {
+			assert.InDelta(nt.correct[i], v, 1e-12, name)
+		}
+		xG, _ = x.Grad()
+		for i, v := range extractF64s(xG) {
+			if i < 3 {
+				assert.InDelta(nt.grad[i], v, 1e-12, name)
+			} else {
+				assert.Equal(0.0, v, name)
+			}
+		}
+	}
+
+	// Frobenius norm, and reducing along many axes
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(backing))))
+	x3 := NewTensor(g, Float64, 3, WithName("x3"), WithShape(2, 3, 4), WithInit(RangedFrom(0)))
+	fro := Must(Norm(x, 2))
+	l1 := Must(Norm(x3, 1, 0, 2))
+	assert.True(fro.IsScalar())
+	assert.Equal(types.Shape{3}, l1.Shape())
+	if err := NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(5.0, fro.Value().(Scalar).v)
+	assert.Equal([]float64{60, 92, 124}, extractF64s(l1.Value()))
+
+	// only the first of the tied maxima gets the gradient of the max norm, like Argmax
+	g = NewGraph()
+	v := NewVector(g, Float64, WithName("v"), WithShape(3), WithValue(tf64.NewTensor(tf64.WithShape(3), tf64.WithBacking([]float64{-4, 4, 1}))))
+	Must(Norm(v, math.Inf(1)))
+	if err := NewLispMachine(g).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	vG, _ := v.Grad()
+	assert.Equal([]float64{-1, 0, 0}, extractF64s(vG))
+
+	_, err := Norm(x, 0)
+	assert.NotNil(err)
+	_, err = Norm(x, 2, 2)
+	assert.NotNil(err)
+}
+
+func TestVarianceOp(t *testing.T) {
+	assert := assert.New(t)
+	// the second row has a standard deviation of 0, so its gradients would be NaNs if Std wasn't stable at zero
+	backing := []float64{1, 2, 4, 5, 5, 5}
+	mean := 7.0 / 3
+	ss := 14.0 / 3 // the sum of the squared deviations of the first row
+
+	for _, std := range []bool{false, true} {
+		for ddof := 0; ddof < 2; ddof++ {
+			name := fmt.Sprintf("std %t, ddof %d", std, ddof)
+			n := float64(3 - ddof)
+
+			correct := ss / n
+			grad := make([]float64, 3)
+			for i, x := range backing[:3] {
+				grad[i] = 2 * (x - mean) / n
+			}
+			if std {
+				correct = math.Sqrt(correct)
+				for i, x := range backing[:3] {
+					grad[i] = (x - mean) / (n * correct)
+				}
+			}
+
+			// the cost is the sum of the variances of each row, weighted by 1 and 2
+			build := func() (g *ExprGraph, x, y *Node) {
+				g = NewGraph()
+				x = NewMatrix(g, Float64, WithName("x"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(backing))))
+				w := NewVector(g, Float64, WithName("w"), WithShape(2), WithValue(tf64.NewTensor(tf64.WithShape(2), tf64.WithBacking([]float64{1, 2}))))
+				if std {
+					y = Must(Std(x, ddof, 1))
+				} else {
+					y = Must(Var(x, ddof, 1))
+				}
+				Must(Sum(Must(HadamardProd(y, w))))
+				return
+			}
+
+			g, x, y := build()
+			assert.Equal(types.Shape{2}, y.Shape(), name)
+			if _, err := Grad(g.Roots()[0], x); err != nil {
+				t.Fatal(err)
+			}
+			prog, locMap, err := Compile(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = NewTapeMachine(prog, locMap, WithNaNWatch()).RunAll(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			xG, _ := x.Grad()
+			for i, v := range extractF64s(xG) {
+				if i < 3 {
+					assert.InDelta(grad[i], v, 1e-12, name)
+				} else {
+					assert.Equal(0.0, v, name)
+				}
+			}
+
+			// lisp machine
+			_, x, y = build()
+			if err = NewLispMachine(x.g, WithNaNWatch()).RunAll(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			yData := extractF64s(y.Value())
+			assert.InDelta(correct, yData[0], 1e-12, name)
+			assert.Equal(0.0, yData[1], name)
+			xG, _ = x.Grad()
+			for i, v := range extractF64s(xG) {
+				if i < 3 {
+					assert.InDelta(grad[i], v, 1e-12, name)
+				} else {
+					assert.Equal(0.0, v, name)
+				}
+			}
+		}
+	}
+
+	// a single pass over values with a large offset keeps its precision, and Float32 works too
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking([]float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}))))
+	x32 := NewMatrix(g, Float32, WithName("x32"), WithShape(2, 2), WithValue(tf32.NewTensor(tf32.WithShape(2, 2), tf32.WithBacking([]float32{1, 2, 3, 5}))))
+	v := Must(Var(x, 1))
+	s32 := Must(Std(x32, 0, 0))
+	assert.True(v.IsScalar())
+	if err := NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(30.0, v.Value().(Scalar).v)
+	assert.Equal([]float32{1, 1.5}, s32.Value().(Tensor).Data())
+
+	_, err := Var(x, 4)
+	assert.NotNil(err)
+	_, err = Std(x, 0, 1)
+	assert.NotNil(err)
+}
@@ -43,9 +43,69 @@ func (op atOp) inferShape(retType Type, inputs ...*Node) (retVal types.Shape, er
 	return scalarShape, nil
 }
 
-func (op atOp) DiffWRT(i int) []bool                       { return make([]bool, i) }
-func (op atOp) SymDiff(Nodes, *Node, *Node) (Nodes, error) { return nil, nondiffErr(op) }
-func (op atOp) String() string                             { return fmt.Sprintf("At(%v)", op.coordinates) }
+func (op atOp) DiffWRT(i int) []bool { return []bool{true} }
+func (op atOp) String() string       { return fmt.Sprintf("At(%v)", op.coordinates) }
+
+// SymDiff places the gradient at the coordinates. This is done by scaling a constant that is one at the coordinates
+// and zero everywhere else, so the gradient is itself differentiable.
+func (op atOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "AtOp only expects one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var mask *Node
+	if mask, err = oneHot(inputs[0], op.index(inputs[0].shape)); err != nil {
+		return
+	}
+
+	retVal = make(Nodes, 1)
+	if retVal[0], err = HadamardProd(mask, gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+// FwdDiff picks the tangent at the coordinates.
+func (op atOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op atOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "AtOp only expects one input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var dy interface{}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	i := op.index(inputs[0].shape)
+	return accumulateDeriv(xdv, func(d interface{}) error {
+		switch dx := d.(type) {
+		case []float64:
+			dx[i] += dy.([]float64)[0]
+		case []float32:
+			dx[i] += dy.([]float32)[0]
+		default:
+			return nyi("atOp.DoDiff", d)
+		}
+		return nil
+	})
+}
+
+// index returns the index of the coordinates in the row-major backing of a tensor of shape s
+func (op atOp) index(s types.Shape) (retVal int) {
+	for i, c := range op.coordinates {
+		retVal = retVal*s[i] + c
+	}
+	return
+}
 
 func (op atOp) Do(inputs ...Value) (retVal Value, err error) {
 	if len(inputs) != 1 {
@@ -67,7 +127,7 @@ func (op atOp) Do(inputs ...Value) (retVal Value, err error) {
 
 func (op atOp) WriteHash(h hash.Hash) {
 	fmt.Fprintf(h, "atOp")
-	if err := binary.Write(h, binary.LittleEndian, op.d); err != nil {
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
 		panic(err)
 	}
 	fmt.Fprintf(h, "at%v", op.coordinates)
@@ -79,8 +139,6 @@ func (op atOp) Hashcode() uint32 {
 	return h.Sum32()
 }
 
-func (op atOp) isStmt() bool { return true }
-
 type sizeOp struct {
 	axis, d int
 	val     int // if we know ahead of time what the size is...
@@ -125,11 +183,17 @@ func (op sizeOp) Do(inputs ...Value) (retVal Value, err error) {
 	var val interface{} // only ints, floats plz
 	switch t := inputs[0].(type) {
 	case Tensor:
-		sh := t.Shape()
-		if op.axis >= len(sh) {
-			err = NewError(ShapeError, "Shape is %v. Want size of %d", sh, op.axis)
+		// the size is known when the graph is built, and the node's shape is authoritative:
+		// a reduced row vector has the shape (1, n) but its value may have the shape (n)
+		size := op.val
+		if size == 0 {
+			sh := t.Shape()
+			if op.axis >= len(sh) {
+				err = NewError(ShapeError, "Shape is %v. Want size of %d", sh, op.axis)
+				return
+			}
+			size = sh[op.axis]
 		}
-		size := sh[op.axis]
 
 		// cast as ... types
 		switch t.Dtype() {
@@ -184,15 +248,17 @@ type repeatOp struct {
 	inputShape types.Shape
 	d          int
 
-	arg0Dim  int
-	children int
+	arg0Dim   int
+	arg0Shape types.Shape
+	children  int
 }
 
 func newRepeatOp(along axes, children Nodes) *repeatOp {
 	retVal := &repeatOp{
-		along:    along,
-		children: len(children),
-		arg0Dim:  children[0].Dims(),
+		along:     along,
+		children:  len(children),
+		arg0Dim:   children[0].Dims(),
+		arg0Shape: children[0].shape.Clone(),
 	}
 	if s, err := retVal.inferShape(nil, children...); err == nil {
 		retVal.inputShape = s
@@ -300,6 +366,11 @@ func (op repeatOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes,
 	return
 }
 
+// FwdDiff repeats the tangent.
+func (op repeatOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
 func (op repeatOp) DoDiff(inputs Nodes, output *Node) (err error) {
 	if len(inputs) < 2 {
 		err = NewError(GraphError, "repeat expects at least 2 inputs. Got %v instead", len(inputs))
@@ -403,6 +474,15 @@ func (op repeatOp) Do(inputs ...Value) (retVal Value, err error) {
 	switch iv := inputs[0].(type) {
 	case Tensor:
 		t = iv.Tensor
+
+		// a reduced row vector has the shape (1, n) but its value may have the shape (n), which would be repeated as a column
+		if s := t.Shape(); op.arg0Shape != nil && len(s) != len(op.arg0Shape) && s.TotalSize() == op.arg0Shape.TotalSize() {
+			t = tensor.Clone(t)
+			if err = t.Reshape(op.arg0Shape...); err != nil {
+				err = errors.Wrapf(err, reshapeFail, op.arg0Shape, t.DataSize())
+				return
+			}
+		}
 	case Scalar:
 		switch iv.t {
 		case Float64:
@@ -522,6 +602,11 @@ func (op sliceOp) inferShape(typ Type, inputs ...*Node) (s types.Shape, err erro
 		s = scalarShape
 	}
 
+	// selecting a single element along an axis of a higher dimensional tensor drops that axis (see the type)
+	if op.end-op.start == 1 && len(s) > 2 {
+		s = append(s[:op.along], s[op.along+1:]...)
+	}
+
 	return
 }
 
@@ -549,6 +634,11 @@ func (op sliceOp) SymDiff(inputs Nodes, outputNode, gradNode *Node) (retVal Node
 	return
 }
 
+// FwdDiff slices the tangent.
+func (op sliceOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
 func (op sliceOp) DoDiff(inputs Nodes, output *Node) (err error) {
 	if len(inputs) != 1 {
 		err = NewError(GraphError, "sliceOp should only have one or more inputs. Got %v instead", len(inputs))
@@ -730,7 +820,8 @@ func (op sliceIncrOp) DiffWRT(i int) []bool {
 		panic(err)
 	}
 
-	return []bool{true, false}
+	// the values of the tensor are never read - only its shape is. So the output only depends on the increment
+	return []bool{false, true}
 }
 
 func (op sliceIncrOp) SymDiff(inputs Nodes, outputNode, gradNode *Node) (retVal Nodes, err error) {
@@ -739,23 +830,19 @@ func (op sliceIncrOp) SymDiff(inputs Nodes, outputNode, gradNode *Node) (retVal
 		err = errors.Wrap(err, operationError)
 		return
 	}
-	retVal = Nodes{gradNode, slicedRes}
+	retVal = Nodes{nil, slicedRes}
 	return
 }
 
+// FwdDiff places the tangent of the increment in the slice.
+func (op sliceIncrOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
 func (op sliceIncrOp) DoDiff(inputs Nodes, output *Node) (err error) {
-	xdv := inputs[0].boundTo.(*dualValue)
 	ydv := inputs[1].boundTo.(*dualValue)
 	zdv := output.boundTo.(*dualValue)
 
-	// dzdx
-	add := newElemBinOp(addOpType, inputs[0], output)
-
-	if _, err = add.UnsafeDo(xdv.d, zdv.d); err != nil {
-		err = errors.Wrapf(err, unsafeDoFail, add)
-		return
-	}
-
 	// dzdy
 	var d Value
 	if d, err = op.sliceOp.Do(zdv.d); err != nil {
@@ -763,7 +850,7 @@ func (op sliceIncrOp) DoDiff(inputs Nodes, output *Node) (err error) {
 		return
 	}
 
-	add = newElemBinOp(addOpType, inputs[1], output)
+	add := newElemBinOp(addOpType, inputs[1], output)
 	if _, err = add.UnsafeDo(ydv.d, d); err != nil {
 		err = errors.Wrapf(err, doFail, add)
 	}
@@ -906,3 +993,1091 @@ func (op sliceIncrOp) String() string {
 	buf.WriteString("...]+=...")
 	return buf.String()
 }
+
+// reshapeOp reshapes a Tensor. The underlying data is unchanged.
+type reshapeOp struct {
+	from, to types.Shape
+	d        int // dimensions of the input
+}
+
+func newReshapeOp(from, to types.Shape, d int) reshapeOp {
+	return reshapeOp{
+		from: from.Clone(),
+		to:   to.Clone(),
+		d:    d,
+	}
+}
+
+// reshapeOp has these types:
+//		reshape :: Tensor d a → Tensor d' a
+//		reshape :: Tensor d a → a
+//		reshape :: a → Tensor d' a
+//
+// The latter two happen when a Tensor of a single element is reshaped into a scalar and back
+func (op reshapeOp) Type() Type {
+	a := newTypeVariable("a")
+
+	var from, to Type
+	from, to = a, a
+	if op.d > 0 {
+		from = newTensorType(op.d, a)
+	}
+	if op.to.Dims() > 0 {
+		to = newTensorType(op.to.Dims(), a)
+	}
+	return newFunctionType(from, to)
+}
+
+func (op reshapeOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "reshapeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	in := inputs[0]
+	if in.shape != nil && in.shape.TotalSize() != op.to.TotalSize() {
+		err = NewError(ShapeError, "Cannot reshape %v into %v", in.shape, op.to)
+		return
+	}
+
+	if op.to.IsScalar() {
+		return scalarShape, nil
+	}
+	return op.to.Clone(), nil
+}
+
+func (op reshapeOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op reshapeOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "reshapeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var n *Node
+	if n, err = Reshape(gradNode, op.from...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal = Nodes{n}
+	return
+}
+
+// FwdDiff reshapes the tangent.
+func (op reshapeOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op reshapeOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "reshapeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	// the reverse of a reshape is a reshape back into the original shape
+	back := newReshapeOp(op.to, op.from, output.Dims())
+	var d Value
+	if d, err = back.Do(ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, back)
+		return
+	}
+
+	add := newEBOByType(addOpType, xdv.d.Type(), d.Type())
+	if _, err = add.UnsafeDo(xdv.d, d); err != nil {
+		err = errors.Wrapf(err, unsafeDoFail, add)
+	}
+	return
+}
+
+func (op reshapeOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "reshapeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var t types.Tensor
+	switch v := inputs[0].(type) {
+	case Tensor:
+		if v.IsView() {
+			// materializing a view already makes a copy
+			t = v.Materialize()
+		} else {
+			t = tensor.Clone(v.Tensor)
+		}
+	case Scalar:
+		// a scalar can only be reshaped back into a single element tensor
+		switch v.t {
+		case Float64:
+			t = tf64.NewTensor(tf64.AsScalar(v.v.(float64)))
+		case Float32:
+			t = tf32.NewTensor(tf32.AsScalar(v.v.(float32)))
+		default:
+			err = nyi("reshapeOp.Do() Scalar Input", v)
+			return
+		}
+	default:
+		err = nyi("reshapeOp.Do()", inputs[0])
+		return
+	}
+	return op.reshape(t)
+}
+
+func (op reshapeOp) returnsPtr() bool    { return true }
+func (op reshapeOp) callsExtern() bool   { return false }
+func (op reshapeOp) overwriteInput() int { return 0 }
+
+func (op reshapeOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("reshape"))
+	fmt.Fprintf(h, "%v->%v", op.from, op.to)
+}
+
+func (op reshapeOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op reshapeOp) String() string { return fmt.Sprintf("Reshape%v", op.to) }
+
+// fulfils UnsafeDoer interface
+func (op reshapeOp) UnsafeDo(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "reshapeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	t, ok := inputs[0].(Tensor)
+	if !ok {
+		return op.Do(inputs...)
+	}
+
+	// views cannot be reshaped in place - they are not contiguous
+	if t.IsView() {
+		return op.reshape(t.Materialize())
+	}
+	return op.reshape(t.Tensor)
+}
+
+// fulfils UnaryOp interface
+func (op reshapeOp) isUnary() bool { return true }
+
+func (op reshapeOp) reshape(t types.Tensor) (retVal Value, err error) {
+	if op.to.IsScalar() {
+		return anyToValue(t.ScalarValue())
+	}
+
+	if err = t.Reshape(op.to...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, op.to, t.DataSize())
+		return
+	}
+	retVal = FromTensor(t)
+	return
+}
+
+// transposeOp permutes the axes of a Tensor. Unlike the thunked transposes in linAlgBinOp,
+// the result of a transposeOp is a Tensor with its data actually moved around.
+type transposeOp struct {
+	pattern []int
+	d       int
+}
+
+func newTransposeOp(pattern []int, d int) transposeOp {
+	return transposeOp{
+		pattern: pattern,
+		d:       d,
+	}
+}
+
+// transposeOp has this type:
+//		transpose :: Tensor d a → Tensor d a
+func (op transposeOp) Type() Type {
+	a := newTypeVariable("a")
+	tt := newTensorType(op.d, a)
+	return newFunctionType(tt, tt)
+}
+
+func (op transposeOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "transposeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	in := inputs[0]
+	if in.shape == nil {
+		err = NewError(ShapeError, "Cannot infer the shape of a transpose of %v without knowing its shape", in)
+		return
+	}
+
+	if len(in.shape) != len(op.pattern) {
+		err = NewError(ShapeError, "Cannot transpose %v with the pattern %v", in.shape, op.pattern)
+		return
+	}
+
+	retVal = make(types.Shape, len(op.pattern))
+	for i, axis := range op.pattern {
+		retVal[i] = in.shape[axis]
+	}
+	return
+}
+
+func (op transposeOp) DiffWRT(i int) []bool { return []bool{true} }
+
+func (op transposeOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "transposeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	var n *Node
+	if n, err = Transpose(gradNode, op.inverse()...); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal = Nodes{n}
+	return
+}
+
+// FwdDiff transposes the tangent.
+func (op transposeOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op transposeOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "transposeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	back := newTransposeOp(op.inverse(), op.d)
+	var d Value
+	if d, err = back.Do(ydv.d); err != nil {
+		err = errors.Wrapf(err, doFail, back)
+		return
+	}
+
+	add := newEBOByType(addOpType, xdv.d.Type(), d.Type())
+	if _, err = add.UnsafeDo(xdv.d, d); err != nil {
+		err = errors.Wrapf(err, unsafeDoFail, add)
+	}
+	return
+}
+
+func (op transposeOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "transposeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	t, ok := inputs[0].(Tensor)
+	if !ok {
+		err = NewError(RuntimeError, "Cannot transpose a scalar value")
+		return
+	}
+	return op.transpose(tensor.Clone(t.Tensor))
+}
+
+func (op transposeOp) returnsPtr() bool    { return true }
+func (op transposeOp) callsExtern() bool   { return false }
+func (op transposeOp) overwriteInput() int { return 0 }
+
+func (op transposeOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("transpose"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.d)); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%v", op.pattern)
+}
+
+func (op transposeOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op transposeOp) String() string { return fmt.Sprintf("Aᵀ%v", op.pattern) }
+
+// fulfils UnsafeDoer interface
+func (op transposeOp) UnsafeDo(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "transposeOp only takes one input. Got %d instead", len(inputs))
+		return
+	}
+
+	t, ok := inputs[0].(Tensor)
+	if !ok {
+		err = NewError(RuntimeError, "Cannot transpose a scalar value")
+		return
+	}
+	return op.transpose(t.Tensor)
+}
+
+// fulfils UnaryOp interface
+func (op transposeOp) isUnary() bool { return true }
+
+// inverse returns the pattern that undoes the transpose
+func (op transposeOp) inverse() []int {
+	retVal := make([]int, len(op.pattern))
+	for i, axis := range op.pattern {
+		retVal[axis] = i
+	}
+	return retVal
+}
+
+func (op transposeOp) transpose(t types.Tensor) (retVal Value, err error) {
+	// vectors only have one way to be transposed, and they don't accept patterns
+	pattern := op.pattern
+	if t.Shape().IsVector() {
+		pattern = nil
+	}
+
+	if err = t.T(pattern...); err != nil {
+		err = errors.Wrap(err, tFail)
+		return
+	}
+
+	switch tt := t.(type) {
+	case *tf64.Tensor:
+		tt.Transpose()
+	case *tf32.Tensor:
+		tt.Transpose()
+	case *ti.Tensor:
+		tt.Transpose()
+	default:
+		err = nyi("transposeOp", t)
+		return
+	}
+	retVal = FromTensor(t)
+	return
+}
+
+// concatOp concatenates its inputs along an axis
+type concatOp struct {
+	axis int
+	dims []int // dimensions of each of the inputs
+	retD int   // dimensions of the result
+}
+
+// concatOp has this type:
+//		concat :: Tensor d a → Tensor d a → ... → Tensor d a
+func (op concatOp) Type() Type {
+	a := newTypeVariable("a")
+
+	ts := make(Types, len(op.dims)+1)
+	for i, d := range op.dims {
+		ts[i] = newTensorType(d, a)
+	}
+	ts[len(op.dims)] = newTensorType(op.retD, a)
+	return newFunctionType(ts...)
+}
+
+func (op concatOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != len(op.dims) {
+		err = NewError(GraphError, "concatOp expects %d inputs. Got %d instead", len(op.dims), len(inputs))
+		return
+	}
+
+	shapes := make([]types.Shape, len(inputs))
+	for i, in := range inputs {
+		if in.shape == nil {
+			err = NewError(ShapeError, "Cannot concatenate %v: its shape is unknown", in)
+			return
+		}
+		shapes[i] = in.shape
+	}
+	return concatShape(op.axis, shapes...)
+}
+
+func (op concatOp) DiffWRT(inputs int) []bool {
+	retVal := make([]bool, inputs)
+	for i := range retVal {
+		retVal[i] = true
+	}
+	return retVal
+}
+
+// SymDiff routes each slice of the gradient back to the input it came from
+func (op concatOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	sizes := make([]int, len(inputs))
+	for i, in := range inputs {
+		sizes[i] = in.shape[op.axis]
+	}
+
+	if retVal, err = Split(gradNode, op.axis, sizes...); err != nil {
+		err = errors.Wrap(err, operationError)
+	}
+	return
+}
+
+// FwdDiff concatenates the tangents.
+func (op concatOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op concatOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	ydv := output.boundTo.(*dualValue)
+
+	var start int
+	for _, in := range inputs {
+		xdv := in.boundTo.(*dualValue)
+		size := in.shape[op.axis]
+
+		slice := newSliceOp(start, start+size, op.axis, output.Dims())
+		var d Value
+		if d, err = slice.Do(ydv.d); err != nil {
+			err = errors.Wrapf(err, doFail, slice)
+			return
+		}
+
+		back := newReshapeOp(d.Shape(), in.shape, d.Shape().Dims())
+		if d, err = back.Do(d); err != nil {
+			err = errors.Wrapf(err, doFail, back)
+			return
+		}
+
+		add := newEBOByType(addOpType, xdv.d.Type(), d.Type())
+		if _, err = add.UnsafeDo(xdv.d, d); err != nil {
+			err = errors.Wrapf(err, unsafeDoFail, add)
+			return
+		}
+		start += size
+	}
+	return
+}
+
+func (op concatOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != len(op.dims) {
+		err = NewError(GraphError, "concatOp expects %d inputs. Got %d instead", len(op.dims), len(inputs))
+		return
+	}
+
+	ts := make([]types.Tensor, len(inputs))
+	shapes := make([]types.Shape, len(inputs))
+	for i, in := range inputs {
+		t, ok := in.(Tensor)
+		if !ok {
+			err = NewError(RuntimeError, "Cannot concatenate a scalar value")
+			return
+		}
+
+		ts[i] = t.Materialize()
+		shapes[i] = ts[i].Shape().Clone()
+
+		// vectors may have lost their trailing dimension
+		for len(shapes[i]) <= op.axis {
+			shapes[i] = append(shapes[i], 1)
+		}
+	}
+
+	var s types.Shape
+	if s, err = concatShape(op.axis, shapes...); err != nil {
+		return
+	}
+
+	t := tensor.Zeroes(ts[0].Dtype(), s...)
+
+	// outer is the number of blocks before the axis, inner is the size of each element along the axis
+	outer, inner := 1, 1
+	for _, v := range s[:op.axis] {
+		outer *= v
+	}
+	for _, v := range s[op.axis+1:] {
+		inner *= v
+	}
+	stride := s[op.axis] * inner
+
+	var offset int
+	for i, in := range ts {
+		size := shapes[i][op.axis] * inner
+		for o := 0; o < outer; o++ {
+			if err = copyData(t.Data(), in.Data(), o*stride+offset, o*size, size); err != nil {
+				return
+			}
+		}
+		offset += size
+	}
+	retVal = FromTensor(t)
+	return
+}
+
+func (op concatOp) returnsPtr() bool    { return false }
+func (op concatOp) callsExtern() bool   { return false }
+func (op concatOp) overwriteInput() int { return -1 }
+
+func (op concatOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("concat"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.axis)); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%v->%v", op.dims, op.retD)
+}
+
+func (op concatOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op concatOp) String() string { return fmt.Sprintf("Concat{axis=%d}", op.axis) }
+
+// concatShape calculates the shape of concatenating tensors of the given shapes along the axis
+func concatShape(axis int, shapes ...types.Shape) (retVal types.Shape, err error) {
+	if len(shapes) == 0 {
+		err = NewError(ShapeError, "Cannot concatenate nothing")
+		return
+	}
+
+	first := shapes[0]
+	if axis < 0 || axis >= len(first) {
+		err = NewError(ShapeError, "Cannot concatenate %v along axis %d", first, axis)
+		return
+	}
+
+	retVal = first.Clone()
+	for _, s := range shapes[1:] {
+		if len(s) != len(first) {
+			err = NewError(ShapeError, "Cannot concatenate %v and %v: differing dimensions", first, s)
+			return
+		}
+
+		for i := range s {
+			if i != axis && s[i] != first[i] {
+				err = NewError(ShapeError, "Cannot concatenate %v and %v along axis %d", first, s, axis)
+				return
+			}
+		}
+		retVal[axis] += s[axis]
+	}
+	return
+}
+
+// copyData copies size elements of src starting at srcStart into dst starting at dstStart.
+func copyData(dst, src interface{}, dstStart, srcStart, size int) error {
+	switch d := dst.(type) {
+	case []float64:
+		copy(d[dstStart:dstStart+size], src.([]float64)[srcStart:srcStart+size])
+	case []float32:
+		copy(d[dstStart:dstStart+size], src.([]float32)[srcStart:srcStart+size])
+	case []int:
+		copy(d[dstStart:dstStart+size], src.([]int)[srcStart:srcStart+size])
+	case []bool:
+		copy(d[dstStart:dstStart+size], src.([]bool)[srcStart:srcStart+size])
+	default:
+		return nyi("copyData", dst)
+	}
+	return nil
+}
+
+// addData adds size elements of src starting at srcStart into dst starting at dstStart.
+func addData(dst, src interface{}, dstStart, srcStart, size int) error {
+	switch d := dst.(type) {
+	case []float64:
+		s := src.([]float64)[srcStart : srcStart+size]
+		for i, v := range s {
+			d[dstStart+i] += v
+		}
+	case []float32:
+		s := src.([]float32)[srcStart : srcStart+size]
+		for i, v := range s {
+			d[dstStart+i] += v
+		}
+	default:
+		return nyi("addData", dst)
+	}
+	return nil
+}
+
+// gatherOp picks out the slices of its first input at the indices given by its second input, along an axis.
+// The most common use is looking up rows of an embedding matrix.
+type gatherOp struct {
+	axis         int
+	paramsShape  types.Shape
+	indicesShape types.Shape
+}
+
+func newGatherOp(axis int, params, indices types.Shape) gatherOp {
+	return gatherOp{
+		axis:         axis,
+		paramsShape:  params,
+		indicesShape: indices,
+	}
+}
+
+// gatherOp has this type:
+//		gather :: Tensor d a → Tensor k Int → Tensor d-1+k a
+func (op gatherOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	return newFunctionType(newTensorType(op.paramsShape.Dims(), a), indicesType(op.indicesShape), gatherRetType(op.retShape(), a))
+}
+
+func (op gatherOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "gatherOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+	return op.retShape(), nil
+}
+
+func (op gatherOp) DiffWRT(inputs int) []bool { return []bool{true, false} }
+
+// SymDiff scatters the gradient back into the slices that were gathered
+func (op gatherOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	scatter := newScatterAddOp(op.axis, op.paramsShape, op.indicesShape, false)
+	retVal = make(Nodes, 2)
+	if retVal[0], err = applyOp(scatter, inputs[1], gradNode); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	retVal[0].setGroup(gradClust)
+	return
+}
+
+// DoDiff accumulates the gradient into the gathered slices only
+func (op gatherOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	xdv := inputs[0].boundTo.(*dualValue)
+	ydv := output.boundTo.(*dualValue)
+
+	var idx []int
+	if idx, err = indicesOf(op, inputs[1].Value(), op.paramsShape[op.axis]); err != nil {
+		return
+	}
+
+	var dy interface{}
+	if dy, err = valueData(ydv.d); err != nil {
+		return
+	}
+
+	dx, ok := xdv.d.(Tensor)
+	if !ok {
+		err = NewError(RuntimeError, "%v expects the derivative of its input to be a Tensor. Got %T instead", op, xdv.d)
+		return
+	}
+	return scatterAdd(dx.Data(), dy, op.paramsShape, op.axis, idx)
+}
+
+// FwdDiff gathers the tangent.
+func (op gatherOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op gatherOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 2 {
+		err = NewError(GraphError, "gatherOp expects 2 inputs. Got %d instead", len(inputs))
+		return
+	}
+
+	var params types.Tensor
+	if params, err = tensorOf(op, inputs[0]); err != nil {
+		return
+	}
+
+	var idx []int
+	if idx, err = indicesOf(op, inputs[1], op.paramsShape[op.axis]); err != nil {
+		return
+	}
+
+	s := op.retShape()
+	size := 1
+	for _, d := range s {
+		size *= d
+	}
+
+	t := tensor.Zeroes(params.Dtype(), size)
+	outer, inner := blocksOf(op.paramsShape, op.axis)
+	n := op.paramsShape[op.axis]
+	for o := 0; o < outer; o++ {
+		for k, i := range idx {
+			if err = copyData(t.Data(), params.Data(), (o*len(idx)+k)*inner, (o*n+i)*inner, inner); err != nil {
+				return
+			}
+		}
+	}
+
+	if s.IsScalar() {
+		return anyToValue(t.ScalarValue())
+	}
+
+	if err = t.Reshape(s...); err != nil {
+		err = errors.Wrapf(err, reshapeFail, s, t.DataSize())
+		return
+	}
+	retVal = FromTensor(t)
+	return
+}
+
+func (op gatherOp) returnsPtr() bool    { return false }
+func (op gatherOp) callsExtern() bool   { return false }
+func (op gatherOp) overwriteInput() int { return -1 }
+
+func (op gatherOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("gather"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.axis)); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%v,%v", op.paramsShape, op.indicesShape)
+}
+
+func (op gatherOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op gatherOp) String() string { return fmt.Sprintf("Gather{axis=%d}", op.axis) }
+
+func (op gatherOp) retShape() types.Shape {
+	return gatherShape(op.paramsShape, op.indicesShape, op.axis)
+}
+
+// scatterAddOp adds the slices of updates into a tensor at the indices given, along an axis.
+// It is the adjoint of gatherOp. When implicit is true, there is no tensor to add to: the updates are scattered into zeroes.
+//
+// The inputs are (dst, indices, updates), or (indices, updates) if implicit is true.
+type scatterAddOp struct {
+	axis         int
+	shape        types.Shape // shape of dst
+	indicesShape types.Shape
+	implicit     bool
+}
+
+func newScatterAddOp(axis int, shape, indices types.Shape, withDst bool) scatterAddOp {
+	return scatterAddOp{
+		axis:         axis,
+		shape:        shape,
+		indicesShape: indices,
+		implicit:     !withDst,
+	}
+}
+
+// scatterAddOp has this type:
+//		scatterAdd :: Tensor d a → Tensor k Int → Tensor d-1+k a → Tensor d a
+// or, if implicit:
+//		scatterAdd :: Tensor k Int → Tensor d-1+k a → Tensor d a
+func (op scatterAddOp) Type() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(op.shape.Dims(), a)
+	it := indicesType(op.indicesShape)
+	ut := gatherRetType(gatherShape(op.shape, op.indicesShape, op.axis), a)
+	if op.implicit {
+		return newFunctionType(it, ut, t)
+	}
+	return newFunctionType(t, it, ut, t)
+}
+
+func (op scatterAddOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "scatterAddOp expects %d inputs. Got %d instead", op.arity(), len(inputs))
+		return
+	}
+	return op.shape.Clone(), nil
+}
+
+func (op scatterAddOp) DiffWRT(inputs int) []bool {
+	if op.implicit {
+		return []bool{false, true}
+	}
+	return []bool{true, false, true}
+}
+
+// SymDiff passes the gradient through to dst, and gathers the gradient of the updates from the scattered slices
+func (op scatterAddOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	indices := inputs[op.arity()-2]
+	gather := newGatherOp(op.axis, op.shape, op.indicesShape)
+
+	var dupd *Node
+	if dupd, err = applyOp(gather, gradNode, indices); err != nil {
+		err = errors.Wrap(err, operationError)
+		return
+	}
+	dupd.setGroup(gradClust)
+
+	if op.implicit {
+		return Nodes{nil, dupd}, nil
+	}
+	return Nodes{gradNode, nil, dupd}, nil
+}
+
+// FwdDiff scatters the tangents.
+func (op scatterAddOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return linearFwdDiff(op, inputs, tangents)
+}
+
+func (op scatterAddOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	ydv := output.boundTo.(*dualValue)
+	indices := inputs[op.arity()-2]
+	updates := inputs[op.arity()-1]
+
+	if !op.implicit {
+		if err = addInto(inputs[0], ydv.d); err != nil {
+			return
+		}
+	}
+
+	gather := newGatherOp(op.axis, op.shape, op.indicesShape)
+	var d Value
+	if d, err = gather.Do(ydv.d, indices.Value()); err != nil {
+		err = errors.Wrapf(err, doFail, gather)
+		return
+	}
+	return addInto(updates, d)
+}
+
+func (op scatterAddOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != op.arity() {
+		err = NewError(GraphError, "scatterAddOp expects %d inputs. Got %d instead", op.arity(), len(inputs))
+		return
+	}
+
+	var idx []int
+	if idx, err = indicesOf(op, inputs[op.arity()-2], op.shape[op.axis]); err != nil {
+		return
+	}
+
+	var upd interface{}
+	if upd, err = valueData(inputs[op.arity()-1]); err != nil {
+		return
+	}
+
+	var t types.Tensor
+	if op.implicit {
+		t = tensor.Zeroes(dtypeOfData(upd), op.shape...)
+	} else {
+		var dst types.Tensor
+		if dst, err = tensorOf(op, inputs[0]); err != nil {
+			return
+		}
+		t = tensor.Clone(dst)
+	}
+
+	if err = scatterAdd(t.Data(), upd, op.shape, op.axis, idx); err != nil {
+		return
+	}
+	retVal = FromTensor(t)
+	return
+}
+
+func (op scatterAddOp) returnsPtr() bool    { return false }
+func (op scatterAddOp) callsExtern() bool   { return false }
+func (op scatterAddOp) overwriteInput() int { return -1 }
+
+func (op scatterAddOp) WriteHash(h hash.Hash) {
+	h.Write([]byte("scatterAdd"))
+	if err := binary.Write(h, binary.LittleEndian, byte(op.axis)); err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(h, "%v,%v,%t", op.shape, op.indicesShape, op.implicit)
+}
+
+func (op scatterAddOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op scatterAddOp) String() string { return fmt.Sprintf("ScatterAdd{axis=%d}", op.axis) }
+
+func (op scatterAddOp) arity() int {
+	if op.implicit {
+		return 2
+	}
+	return 3
+}
+
+// gatherShape calculates the shape of gathering the indices along the axis of a tensor of shape s
+func gatherShape(s, indices types.Shape, axis int) types.Shape {
+	retVal := make(types.Shape, 0, len(s)+len(indices))
+	retVal = append(retVal, s[:axis]...)
+	if !indices.IsScalar() {
+		retVal = append(retVal, indices...)
+	}
+	retVal = append(retVal, s[axis+1:]...)
+
+	if len(retVal) == 0 {
+		return scalarShape
+	}
+	return retVal
+}
+
+func gatherRetType(s types.Shape, a Type) Type {
+	if s.IsScalar() {
+		return a
+	}
+	return newTensorType(s.Dims(), a)
+}
+
+func indicesType(s types.Shape) Type {
+	if s.IsScalar() {
+		return Int
+	}
+	return newTensorType(s.Dims(), Int)
+}
+
+// blocksOf returns the number of blocks before the axis, and the size of each element along the axis
+func blocksOf(s types.Shape, axis int) (outer, inner int) {
+	outer, inner = 1, 1
+	for _, v := range s[:axis] {
+		outer *= v
+	}
+	for _, v := range s[axis+1:] {
+		inner *= v
+	}
+	return
+}
+
+// scatterAdd adds each slice of upd into the slice of dst at the corresponding index along the axis.
+// dst is a tensor of shape s. Only the slices at the indices are touched.
+func scatterAdd(dst, upd interface{}, s types.Shape, axis int, idx []int) (err error) {
+	outer, inner := blocksOf(s, axis)
+	n := s[axis]
+	for o := 0; o < outer; o++ {
+		for k, i := range idx {
+			if err = addData(dst, upd, (o*n+i)*inner, (o*len(idx)+k)*inner, inner); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// indicesOf returns the indices held by v, checking that they are all less than n
+func indicesOf(op Op, v Value, n int) (retVal []int, err error) {
+	switch vt := v.(type) {
+	case Scalar:
+		i, ok := vt.v.(int)
+		if !ok {
+			err = NewError(TypeError, "%v expects Int indices. Got %v instead", op, vt.t)
+			return
+		}
+		retVal = []int{i}
+	case Tensor:
+		var ok bool
+		if retVal, ok = vt.Materialize().Data().([]int); !ok {
+			err = NewError(TypeError, "%v expects Int indices. Got %v instead", op, vt.Dtype())
+			return
+		}
+	default:
+		err = NewError(TypeError, "%v expects Int indices. Got %v of %T instead", op, v, v)
+		return
+	}
+
+	for _, i := range retVal {
+		if i < 0 || i >= n {
+			err = NewError(RuntimeError, "%v: index %d is out of bounds for an axis of size %d", op, i, n)
+			return
+		}
+	}
+	return
+}
+
+// dtypeOfData returns the tensor dtype of a slice of data
+func dtypeOfData(data interface{}) types.Dtype {
+	switch data.(type) {
+	case []float32:
+		return types.Float32
+	case []int:
+		return types.Int
+	case []bool:
+		return types.Bool
+	}
+	return types.Float64
+}
+
+// identityOp returns its input unchanged. If stop is set, no gradient flows back through it: it is a constant as far as
+// differentiation is concerned.
+type identityOp struct {
+	stop bool
+}
+
+// identityOp has this type:
+//		identity :: a → a
+func (op identityOp) Type() Type {
+	a := newTypeVariable("a")
+	return newFunctionType(a, a)
+}
+
+func (op identityOp) inferShape(typ Type, inputs ...*Node) (retVal types.Shape, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "%v only takes one input. Got %d instead", op, len(inputs))
+		return
+	}
+	return inputs[0].shape.Clone(), nil
+}
+
+func (op identityOp) DiffWRT(i int) []bool { return []bool{!op.stop} }
+
+func (op identityOp) SymDiff(inputs Nodes, output, gradNode *Node) (retVal Nodes, err error) {
+	if op.stop {
+		err = nondiffErr(op)
+		return
+	}
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "%v only takes one input. Got %d instead", op, len(inputs))
+		return
+	}
+	return Nodes{gradNode}, nil
+}
+
+func (op identityOp) FwdDiff(inputs Nodes, output *Node, tangents Nodes) (*Node, error) {
+	return tangents[0], nil
+}
+
+func (op identityOp) DoDiff(inputs Nodes, output *Node) (err error) {
+	if op.stop {
+		return nondiffErr(op)
+	}
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "%v only takes one input. Got %d instead", op, len(inputs))
+		return
+	}
+
+	ydv := output.boundTo.(*dualValue)
+	return addInto(inputs[0], ydv.d)
+}
+
+// Do returns a copy of the input, so that the value of the result can't be changed through the value of the input
+func (op identityOp) Do(inputs ...Value) (retVal Value, err error) {
+	if len(inputs) != 1 {
+		err = NewError(GraphError, "%v only takes one input. Got %d instead", op, len(inputs))
+		return
+	}
+	return inputs[0].clone()
+}
+
+func (op identityOp) returnsPtr() bool    { return false }
+func (op identityOp) callsExtern() bool   { return false }
+func (op identityOp) overwriteInput() int { return -1 }
+
+func (op identityOp) WriteHash(h hash.Hash) {
+	if op.stop {
+		h.Write([]byte("stopGradient"))
+		return
+	}
+	h.Write([]byte("identity"))
+}
+
+func (op identityOp) Hashcode() uint32 {
+	h := fnv.New32a()
+	op.WriteHash(h)
+	return h.Sum32()
+}
+
+func (op identityOp) String() string {
+	if op.stop {
+		return "StopGradient"
+	}
+	return "Identity"
+}
+
+// fulfils UnaryOp interface
+func (op identityOp) isUnary() bool { return true }
+
+// isStopGradient returns true if n blocks the gradients flowing back through it
+func isStopGradient(n *Node) bool {
+	op, ok := n.op.(identityOp)
+	return ok && op.stop
+}
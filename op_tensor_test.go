@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	ti "github.com/chewxy/gorgonia/tensor/i"
 	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -403,3 +404,406 @@ func TestSliceOpDiff(t *testing.T) {
 	// t.Logf("%+v", A.Value())
 	// t.Logf("%+v", A.Grad())
 }
+
+func TestReshapeOp(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	A := NewMatrix(g, Float64, WithShape(2, 3), WithInit(RangedFrom(0)))
+	B := NewMatrix(g, Float64, WithShape(3, 2), WithInit(RangedFrom(0)))
+	r := Must(Reshape(A, 3, -1))
+	assert.Equal(types.Shape{3, 2}, r.Shape())
+	x := Must(Sum(Must(HadamardProd(r, B))))
+
+	_, err := Grad(x, A)
+	if err != nil {
+		t.Error(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machine := NewTapeMachine(prog, locMap)
+	if err = machine.RunAll(); err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal(types.Shape{3, 2}, r.Value().Shape())
+	assert.Equal([]float64{0, 1, 2, 3, 4, 5}, r.Value().(Tensor).Tensor.Data())
+	assert.Equal(types.Shape{2, 3}, A.Value().Shape(), "A should not have been reshaped")
+
+	aG, _ := A.Grad()
+	assert.Equal(types.Shape{2, 3}, aG.Shape())
+	assert.Equal([]float64{0, 1, 2, 3, 4, 5}, aG.(Tensor).Tensor.Data())
+
+	/* Lisp machine version */
+	g2 := NewGraph()
+	A = NewMatrix(g2, Float64, WithShape(2, 3), WithInit(RangedFrom(0)))
+	B = NewMatrix(g2, Float64, WithShape(3, 2), WithInit(RangedFrom(0)))
+	r = Must(Reshape(A, 3, 2))
+	x = Must(Sum(Must(HadamardProd(r, B))))
+
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Error(err)
+	}
+
+	aG, _ = A.Grad()
+	assert.Equal(types.Shape{2, 3}, aG.Shape())
+	assert.Equal([]float64{0, 1, 2, 3, 4, 5}, aG.(Tensor).Tensor.Data())
+
+	// reshaping a view (the columns 1 and 2 of A) copies out the viewed elements
+	for _, useTape := range []bool{true, false} {
+		g4 := NewGraph()
+		A = NewMatrix(g4, Float64, WithShape(2, 3), WithInit(RangedFrom(0)))
+		r = Must(Reshape(Must(Slice(A, nil, S(1, 3))), 4))
+
+		if useTape {
+			prog, locMap, err = Compile(g4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = NewTapeMachine(prog, locMap).RunAll()
+		} else {
+			err = NewLispMachine(g4, ExecuteFwdOnly()).RunAll()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal([]float64{1, 2, 4, 5}, r.Value().(Tensor).Tensor.Data())
+	}
+
+	// bad reshapes
+	g3 := NewGraph()
+	A = NewMatrix(g3, Float64, WithShape(2, 3))
+	_, err = Reshape(A, 4, 2)
+	assert.NotNil(err)
+	_, err = Reshape(A, -1, -1)
+	assert.NotNil(err)
+	_, err = Reshape(A, 4, -1)
+	assert.NotNil(err)
+}
+
+func TestTransposeOp(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	A := NewMatrix(g, Float64, WithShape(2, 3), WithInit(RangedFrom(0)))
+	B := NewMatrix(g, Float64, WithShape(3, 2), WithInit(RangedFrom(0)))
+	tr := Must(Transpose(A))
+	assert.Equal(types.Shape{3, 2}, tr.Shape())
+	x := Must(Sum(Must(HadamardProd(tr, B))))
+
+	_, err := Grad(x, A)
+	if err != nil {
+		t.Error(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machine := NewTapeMachine(prog, locMap)
+	if err = machine.RunAll(); err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal([]float64{0, 3, 1, 4, 2, 5}, tr.Value().(Tensor).Tensor.Data())
+	assert.Equal([]float64{0, 1, 2, 3, 4, 5}, A.Value().(Tensor).Tensor.Data(), "A should not have been transposed")
+
+	aG, _ := A.Grad()
+	assert.Equal(types.Shape{2, 3}, aG.Shape())
+	assert.Equal([]float64{0, 2, 4, 1, 3, 5}, aG.(Tensor).Tensor.Data())
+
+	/* Lisp machine version */
+	g2 := NewGraph()
+	A = NewMatrix(g2, Float64, WithShape(2, 3), WithInit(RangedFrom(0)))
+	B = NewMatrix(g2, Float64, WithShape(3, 2), WithInit(RangedFrom(0)))
+	tr = Must(Transpose(A, 1, 0))
+	x = Must(Sum(Must(HadamardProd(tr, B))))
+
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Error(err)
+	}
+
+	aG, _ = A.Grad()
+	assert.Equal([]float64{0, 2, 4, 1, 3, 5}, aG.(Tensor).Tensor.Data())
+
+	// 3-Tensors
+	g3 := NewGraph()
+	T := NewTensor(g3, Float64, 3, WithShape(2, 3, 4), WithInit(RangedFrom(0)))
+	tr = Must(Transpose(T, 1, 2, 0))
+	assert.Equal(types.Shape{3, 4, 2}, tr.Shape())
+	W := NewVector(g3, Float64, WithShape(24), WithInit(RangedFrom(0)))
+	x = Must(Sum(Must(HadamardProd(Must(Reshape(tr, 24)), W))))
+	if _, err = Grad(x, T); err != nil {
+		t.Error(err)
+	}
+
+	prog, locMap, err = Compile(g3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine = NewTapeMachine(prog, locMap)
+	if err = machine.RunAll(); err != nil {
+		t.Error(err)
+	}
+
+	data := tr.Value().(Tensor).Tensor.Data().([]float64)
+	assert.Equal([]float64{0, 12, 1, 13, 2, 14, 3, 15}, data[:8])
+	tG, _ := T.Grad()
+	assert.Equal(types.Shape{2, 3, 4}, tG.Shape())
+	gd := tG.(Tensor).Tensor.Data().([]float64)
+	assert.Equal([]float64{0, 2, 4, 6, 8, 10, 12, 14}, gd[:8])
+	assert.Equal(1.0, gd[12])
+
+	// bad axes
+	_, err = Transpose(T, 0, 0, 1)
+	assert.NotNil(err)
+	_, err = Transpose(T, 0, 1)
+	assert.NotNil(err)
+}
+
+func TestConcatOp(t *testing.T) {
+	assert := assert.New(t)
+
+	for axis := 0; axis < 2; axis++ {
+		g := NewGraph()
+		A := NewMatrix(g, Float64, WithName("A"), WithShape(2, 2), WithInit(RangedFrom(0)))
+		B := NewMatrix(g, Float64, WithName("B"), WithShape(2, 2), WithValue(tf64.NewTensor(tf64.WithShape(2, 2), tf64.WithBacking(tf64.RangeFloat64(4, 8)))))
+		c := Must(Concat(axis, A, B))
+
+		// weight the result so that the gradients differ per element
+		s := types.Shape{2, 2}
+		s[axis] = 4
+		W := NewMatrix(g, Float64, WithName("W"), WithShape(s...), WithInit(RangedFrom(0)))
+		assert.Equal(s, c.Shape())
+		x := Must(Sum(Must(HadamardProd(c, W))))
+
+		if _, err := Grad(x, A, B); err != nil {
+			t.Fatal(err)
+		}
+
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		machine := NewTapeMachine(prog, locMap)
+		if err = machine.RunAll(); err != nil {
+			t.Error(err)
+		}
+
+		aG, _ := A.Grad()
+		bG, _ := B.Grad()
+		switch axis {
+		case 0:
+			assert.Equal([]float64{0, 1, 2, 3, 4, 5, 6, 7}, c.Value().(Tensor).Tensor.Data())
+			assert.Equal([]float64{0, 1, 2, 3}, aG.(Tensor).Tensor.Data())
+			assert.Equal([]float64{4, 5, 6, 7}, bG.(Tensor).Tensor.Data())
+		case 1:
+			assert.Equal([]float64{0, 1, 4, 5, 2, 3, 6, 7}, c.Value().(Tensor).Tensor.Data())
+			assert.Equal([]float64{0, 1, 4, 5}, aG.(Tensor).Tensor.Data())
+			assert.Equal([]float64{2, 3, 6, 7}, bG.(Tensor).Tensor.Data())
+		}
+
+		/* Lisp machine version */
+		g2 := NewGraph()
+		A = NewMatrix(g2, Float64, WithName("A"), WithShape(2, 2), WithInit(RangedFrom(0)))
+		B = NewMatrix(g2, Float64, WithName("B"), WithShape(2, 2), WithValue(tf64.NewTensor(tf64.WithShape(2, 2), tf64.WithBacking(tf64.RangeFloat64(4, 8)))))
+		W = NewMatrix(g2, Float64, WithName("W"), WithShape(s...), WithInit(RangedFrom(0)))
+		c = Must(Concat(axis, A, B))
+		x = Must(Sum(Must(HadamardProd(c, W))))
+
+		m2 := NewLispMachine(g2)
+		if err = m2.RunAll(); err != nil {
+			t.Error(err)
+		}
+
+		aG, _ = A.Grad()
+		bG, _ = B.Grad()
+		switch axis {
+		case 0:
+			assert.Equal([]float64{0, 1, 2, 3}, aG.(Tensor).Tensor.Data())
+			assert.Equal([]float64{4, 5, 6, 7}, bG.(Tensor).Tensor.Data())
+		case 1:
+			assert.Equal([]float64{0, 1, 4, 5}, aG.(Tensor).Tensor.Data())
+			assert.Equal([]float64{2, 3, 6, 7}, bG.(Tensor).Tensor.Data())
+		}
+	}
+
+	// mismatched shapes
+	g := NewGraph()
+	A := NewMatrix(g, Float64, WithName("A"), WithShape(2, 2))
+	B := NewMatrix(g, Float64, WithName("B"), WithShape(3, 3))
+	_, err := Concat(0, A, B)
+	assert.NotNil(err)
+}
+
+func TestStackSplit(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	A := NewMatrix(g, Float64, WithName("A"), WithShape(2, 3), WithInit(RangedFrom(0)))
+	B := NewMatrix(g, Float64, WithName("B"), WithShape(2, 3), WithValue(tf64.NewTensor(tf64.WithShape(2, 3), tf64.WithBacking(tf64.RangeFloat64(6, 12)))))
+	st := Must(Stack(1, A, B))
+	assert.Equal(types.Shape{2, 2, 3}, st.Shape())
+
+	parts, err := Split(st, 2, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(2, len(parts))
+	assert.Equal(types.Shape{2, 2, 1}, parts[0].Shape())
+	assert.Equal(types.Shape{2, 2, 2}, parts[1].Shape())
+
+	W := NewVector(g, Float64, WithName("W"), WithShape(8), WithInit(RangedFrom(0)))
+	x := Must(Sum(Must(HadamardProd(Must(Reshape(parts[1], 8)), W))))
+	if _, err = Grad(x, A, B); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machine := NewTapeMachine(prog, locMap)
+	if err = machine.RunAll(); err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal([]float64{0, 1, 2, 6, 7, 8, 3, 4, 5, 9, 10, 11}, st.Value().(Tensor).Tensor.Data())
+	assert.Equal([]float64{0, 6, 3, 9}, parts[0].Value().(Tensor).Tensor.Data())
+	assert.Equal([]float64{1, 2, 7, 8, 4, 5, 10, 11}, parts[1].Value().(Tensor).Tensor.Data())
+
+	aG, _ := A.Grad()
+	bG, _ := B.Grad()
+	assert.Equal([]float64{0, 0, 1, 0, 4, 5}, aG.(Tensor).Tensor.Data())
+	assert.Equal([]float64{0, 2, 3, 0, 6, 7}, bG.(Tensor).Tensor.Data())
+
+	_, err = Split(st, 2, 1, 1)
+	assert.NotNil(err)
+}
+
+func TestGatherOp(t *testing.T) {
+	assert := assert.New(t)
+	ids := []int{2, 0, 2}
+
+	g := NewGraph()
+	E := NewMatrix(g, Float64, WithName("E"), WithShape(4, 3), WithInit(RangedFrom(0)))
+	idx := NewVector(g, Int, WithName("idx"), WithShape(3), WithValue(ti.NewTensor(ti.WithShape(3), ti.WithBacking(ids))))
+	rows := Must(Gather(E, idx, 0))
+	assert.Equal(types.Shape{3, 3}, rows.Shape())
+
+	cost := Must(Sum(rows))
+	if _, err := Grad(cost, E); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	correctGrad := []float64{1, 1, 1, 0, 0, 0, 2, 2, 2, 0, 0, 0}
+	assert.Equal([]float64{6, 7, 8, 0, 1, 2, 6, 7, 8}, rows.Value().(Tensor).Tensor.Data())
+	eG, _ := E.Grad()
+	assert.Equal(correctGrad, eG.(Tensor).Tensor.Data())
+
+	// lisp machine
+	g2 := NewGraph()
+	E2 := NewMatrix(g2, Float64, WithName("E"), WithShape(4, 3), WithInit(RangedFrom(0)))
+	idx2 := NewVector(g2, Int, WithName("idx"), WithShape(3), WithValue(ti.NewTensor(ti.WithShape(3), ti.WithBacking(ids))))
+	Must(Sum(Must(Gather(E2, idx2, 0))))
+
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	e2G, _ := E2.Grad()
+	assert.Equal(correctGrad, e2G.(Tensor).Tensor.Data())
+
+	// gathering along other axes, and with scalar indices
+	g3 := NewGraph()
+	x := NewMatrix(g3, Float64, WithName("x"), WithShape(2, 4), WithInit(RangedFrom(0)))
+	cols := NewVector(g3, Int, WithName("cols"), WithShape(2), WithValue(ti.NewTensor(ti.WithShape(2), ti.WithBacking([]int{3, 1}))))
+	one := NewScalar(g3, Int, WithName("one"), WithValue(1))
+	gc := Must(Gather(x, cols, 1))
+	gr := Must(Gather(x, one, 0))
+	assert.Equal(types.Shape{2, 2}, gc.Shape())
+	assert.Equal(types.Shape{4}, gr.Shape())
+
+	m3 := NewLispMachine(g3, ExecuteFwdOnly())
+	if err = m3.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal([]float64{3, 1, 7, 5}, gc.Value().(Tensor).Tensor.Data())
+	assert.Equal([]float64{4, 5, 6, 7}, gr.Value().(Tensor).Tensor.Data())
+
+	_, err = Gather(x, cols, 2)
+	assert.NotNil(err)
+	_, err = Gather(x, gc, 0)
+	assert.NotNil(err)
+}
+
+func TestScatterAddOp(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewMatrix(g, Float64, WithName("x"), WithShape(4, 2), WithInit(RangedFrom(0)))
+	u := NewMatrix(g, Float64, WithName("u"), WithShape(3, 2), WithValue(tf64.NewTensor(tf64.WithShape(3, 2), tf64.WithBacking([]float64{10, 20, 30, 40, 50, 60}))))
+	idx := NewVector(g, Int, WithName("idx"), WithShape(3), WithValue(ti.NewTensor(ti.WithShape(3), ti.WithBacking([]int{3, 0, 3}))))
+	W := NewMatrix(g, Float64, WithName("W"), WithShape(4, 2), WithInit(RangedFrom(0)))
+
+	sa := Must(ScatterAdd(x, idx, u, 0))
+	assert.Equal(types.Shape{4, 2}, sa.Shape())
+
+	cost := Must(Sum(Must(HadamardProd(sa, W))))
+	if _, err := Grad(cost, x, u); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]float64{30, 41, 2, 3, 4, 5, 66, 87}, sa.Value().(Tensor).Tensor.Data())
+	xG, _ := x.Grad()
+	uG, _ := u.Grad()
+	assert.Equal([]float64{0, 1, 2, 3, 4, 5, 6, 7}, xG.(Tensor).Tensor.Data())
+	assert.Equal([]float64{6, 7, 0, 1, 6, 7}, uG.(Tensor).Tensor.Data())
+
+	// lisp machine
+	g2 := NewGraph()
+	x2 := NewMatrix(g2, Float64, WithName("x"), WithShape(4, 2), WithInit(RangedFrom(0)))
+	u2 := NewMatrix(g2, Float64, WithName("u"), WithShape(3, 2), WithValue(tf64.NewTensor(tf64.WithShape(3, 2), tf64.WithBacking([]float64{10, 20, 30, 40, 50, 60}))))
+	idx2 := NewVector(g2, Int, WithName("idx"), WithShape(3), WithValue(ti.NewTensor(ti.WithShape(3), ti.WithBacking([]int{3, 0, 3}))))
+	W2 := NewMatrix(g2, Float64, WithName("W"), WithShape(4, 2), WithInit(RangedFrom(0)))
+	Must(Sum(Must(HadamardProd(Must(ScatterAdd(x2, idx2, u2, 0)), W2))))
+
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	x2G, _ := x2.Grad()
+	u2G, _ := u2.Grad()
+	assert.Equal(xG.(Tensor).Tensor.Data(), x2G.(Tensor).Tensor.Data())
+	assert.Equal(uG.(Tensor).Tensor.Data(), u2G.(Tensor).Tensor.Data())
+
+	_, err = ScatterAdd(x, idx, W, 0)
+	assert.NotNil(err)
+}
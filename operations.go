@@ -1,6 +1,9 @@
 package gorgonia
 
 import (
+	"math"
+	"strings"
+
 	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/pkg/errors"
 )
@@ -65,6 +68,23 @@ func binOpNode(op BinaryOp, a, b *Node) (retVal *Node, err error) {
 	stabLogf("Creating node for %v, a: %p, b: %p", op, a, b)
 	enterLoggingContext()
 	defer leaveLoggingContext()
+
+	// broadcast the operands if their shapes differ
+	if ebo, ok := op.(elemBinOp); ok {
+		var x, y *Node
+		if x, y, err = autoBroadcast(a, b); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+
+		if x != a || y != b {
+			a, b = x, y
+			bc := newElemBinOp(ebo.binOpType(), a, b)
+			bc.retSame = ebo.retSame
+			op = bc
+		}
+	}
+
 	// maybe make stabilization a build tag?
 	if stabilization {
 		enterLoggingContext()
@@ -150,6 +170,28 @@ func Mul(a, b *Node) (retVal *Node, err error) {
 	return binOpNode(op, a, b)
 }
 
+// BatchedMatMul multiplies each matrix in a with the corresponding matrix in b: (B, M, K) × (B, K, N) → (B, M, N).
+// The optional transes are transA and transB, which transpose the matrices of a and b (not the batch axis) before multiplying.
+func BatchedMatMul(a, b *Node, transes ...bool) (retVal *Node, err error) {
+	if len(transes) > 2 {
+		err = NewError(GraphError, "BatchedMatMul takes at most two transpose flags. Got %d instead", len(transes))
+		return
+	}
+
+	op := linAlgBinOp{āBinaryOperator: batchedMatMulOperator}
+	if len(transes) > 0 {
+		op.transA = transes[0]
+	}
+	if len(transes) > 1 {
+		op.transB = transes[1]
+	}
+
+	if _, err = batchedMatMulShape(a.shape, b.shape, op.transA, op.transB); err != nil {
+		return
+	}
+	return binOpNode(op, a, b)
+}
+
 func OuterProd(a, b *Node) (retVal *Node, err error) {
 	if !a.IsVector() || !b.IsVector() {
 		err = NewError(GraphError, "Expected only vectors to be able to do OuterProd") //for now
@@ -161,12 +203,189 @@ func OuterProd(a, b *Node) (retVal *Node, err error) {
 	return binOpNode(op, a, b)
 }
 
+// Einsum performs the Einstein summation described by the subscripts on the operands. For example:
+//		Einsum("ij,jk->ik", a, b)		// matrix multiplication
+//		Einsum("bij,bjk->bik", a, b)	// batched matrix multiplication
+//		Einsum("ij->ji", a)			// transpose
+//		Einsum("ij,ij->", a, b)		// sum of the elementwise product
+// If there is no "->", the output subscripts are the letters that appear exactly once, in alphabetical order.
+// Letters that appear in only one operand and not in the output are summed out. Repeated letters within an
+// operand (i.e. diagonals and traces) are not supported.
+//
+// Each pair of operands is contracted with a transpose, a reshape and a batched matrix multiplication. More than two
+// operands are contracted from left to right.
+func Einsum(subscripts string, operands ...*Node) (retVal *Node, err error) {
+	if len(operands) == 0 {
+		err = NewError(GraphError, "Einsum expects at least one operand")
+		return
+	}
+
+	var ins []string
+	var out string
+	if ins, out, err = parseEinsum(subscripts, len(operands)); err != nil {
+		return
+	}
+
+	shapes := make([]types.Shape, len(operands))
+	for i, n := range operands {
+		if n.IsScalar() || n.shape == nil {
+			err = NewError(ShapeError, "Einsum expects Tensor operands with known shapes. Got %v instead", n)
+			return
+		}
+		shapes[i] = n.shape
+	}
+	if _, err = einsumSizes(ins, shapes); err != nil {
+		return
+	}
+
+	retVal, sub := operands[0], ins[0]
+	if len(operands) == 1 {
+		return einsumReduce(retVal, sub, out)
+	}
+
+	for i, b := range operands[1:] {
+		// the intermediate results keep the letters that are needed by the output or the operands that follow
+		res := out
+		if i+2 < len(ins) {
+			res = einsumSubscripts(sub+ins[i+1], out+strings.Join(ins[i+2:], ""))
+		}
+		if retVal, err = einsumPair(retVal, sub, b, ins[i+1], res); err != nil {
+			return
+		}
+		sub = res
+	}
+	return
+}
+
+// einsumReduce sums out the letters of sub that are not in out, and transposes the result into the order of out
+func einsumReduce(n *Node, sub, out string) (retVal *Node, err error) {
+	retVal = n
+	kept := einsumSubscripts(sub, out)
+	if len(kept) < len(sub) {
+		var along []int
+		for i, r := range sub {
+			if !strings.ContainsRune(out, r) {
+				along = append(along, i)
+			}
+		}
+		if retVal, err = Sum(retVal, along...); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+
+		// a summed matrix keeps its reduced axis (a (2, 3) matrix summed along 0 is (1, 3))
+		sizes := make(map[rune]int)
+		for i, r := range sub {
+			sizes[r] = n.shape[i]
+		}
+		if s := einsumShape(kept, sizes); !s.IsScalar() && len(s) != len(retVal.shape) {
+			if retVal, err = Reshape(retVal, s...); err != nil {
+				err = errors.Wrap(err, operationError)
+				return
+			}
+		}
+	}
+
+	if pattern, identity := einsumPattern(kept, out); !identity {
+		if retVal, err = Transpose(retVal, pattern...); err != nil {
+			err = errors.Wrap(err, operationError)
+		}
+	}
+	return
+}
+
+// einsumPair contracts a and b into the subscripts out
+func einsumPair(a *Node, as string, b *Node, bs string, out string) (retVal *Node, err error) {
+	aKeep, bKeep := einsumSubscripts(as, bs+out), einsumSubscripts(bs, as+out)
+	if a, err = einsumReduce(a, as, aKeep); err != nil {
+		return
+	}
+	if b, err = einsumReduce(b, bs, bKeep); err != nil {
+		return
+	}
+
+	// an operand that has been summed into a scalar simply scales the other
+	switch {
+	case len(aKeep) == 0 && len(bKeep) == 0:
+		return HadamardProd(a, b)
+	case len(aKeep) == 0:
+		if b, err = einsumReduce(b, bKeep, out); err != nil {
+			return
+		}
+		return HadamardProd(a, b)
+	case len(bKeep) == 0:
+		if a, err = einsumReduce(a, aKeep, out); err != nil {
+			return
+		}
+		return HadamardProd(a, b)
+	}
+	return applyOp(newEinsumOp(aKeep, bKeep, out), a, b)
+}
+
+// MatInverse computes the inverse of the square matrix a
+func MatInverse(a *Node) (retVal *Node, err error) {
+	op := denseLinAlgOp{denseLinAlgOperator: matInverseOperator}
+	if _, err = squareSize(op, a.shape); err != nil {
+		return
+	}
+	return applyOp(op, a)
+}
+
+// LogDet computes the log of the absolute value of the determinant of the square matrix a
+func LogDet(a *Node) (retVal *Node, err error) {
+	op := denseLinAlgOp{denseLinAlgOperator: logDetOperator}
+	if _, err = squareSize(op, a.shape); err != nil {
+		return
+	}
+	return applyOp(op, a)
+}
+
+// Cholesky computes the lower triangular matrix L such that a = LLᵀ. a has to be symmetric and positive definite -
+// only its lower triangle is read. The gradient is symmetric.
+func Cholesky(a *Node) (retVal *Node, err error) {
+	op := denseLinAlgOp{denseLinAlgOperator: choleskyOperator}
+	if _, err = squareSize(op, a.shape); err != nil {
+		return
+	}
+	return applyOp(op, a)
+}
+
+// TriangularSolve solves ax = b for x, where a is a lower (or upper) triangular matrix. Only the triangle of a is read.
+// b may be a vector or a matrix.
+func TriangularSolve(a, b *Node, lower bool) (retVal *Node, err error) {
+	return solveNode(denseLinAlgOp{denseLinAlgOperator: triSolveOperator, lower: lower}, a, b)
+}
+
+// Solve solves ax = b for x, where a is a square matrix. b may be a vector or a matrix.
+func Solve(a, b *Node) (retVal *Node, err error) {
+	return solveNode(denseLinAlgOp{denseLinAlgOperator: solveOperator}, a, b)
+}
+
+func solveNode(op denseLinAlgOp, a, b *Node) (retVal *Node, err error) {
+	if !b.IsVector() && !b.IsMatrix() {
+		err = NewError(ShapeError, "%v expects the right hand side to be a vector or a matrix. Got %v instead", op, b.t)
+		return
+	}
+
+	op.d = b.Dims()
+	if _, err = op.inferShape(nil, a, b); err != nil {
+		return
+	}
+	return applyOp(op, a, b)
+}
+
 // HadamardDiv: pointwise a / b
 func HadamardDiv(a, b *Node) (retVal *Node, err error) {
 	op := newElemBinOp(divOpType, a, b)
 	return binOpNode(op, a, b)
 }
 
+// Atan2: pointwise atan2(a, b), the angle of the point (b, a)
+func Atan2(a, b *Node) (retVal *Node, err error) {
+	op := newElemBinOp(atan2OpType, a, b)
+	return binOpNode(op, a, b)
+}
+
 func Div(a, b *Node) (retVal *Node, err error) {
 	if a.IsScalar() || b.IsScalar() {
 		return HadamardDiv(a, b)
@@ -190,8 +409,163 @@ func Gte(a, b *Node, retSame bool) (retVal *Node, err error) {
 	return binOpNode(op, a, b)
 }
 
+// Lt: pointwise a < b. retSame indicates if the return value should be the same type as the input values
+func Lt(a, b *Node, retSame bool) (retVal *Node, err error) {
+	op := newElemBinOp(ltOpType, a, b)
+	op.retSame = retSame
+	return binOpNode(op, a, b)
+}
+
+// Lte: pointwise a <= b. retSame indicates if the return value should be the same type as the input values
+func Lte(a, b *Node, retSame bool) (retVal *Node, err error) {
+	op := newElemBinOp(lteOpType, a, b)
+	op.retSame = retSame
+	return binOpNode(op, a, b)
+}
+
+// Eq: pointwise a == b. retSame indicates if the return value should be the same type as the input values
+func Eq(a, b *Node, retSame bool) (retVal *Node, err error) {
+	op := newElemBinOp(eqOpType, a, b)
+	op.retSame = retSame
+	return binOpNode(op, a, b)
+}
+
+// Ne: pointwise a != b. retSame indicates if the return value should be the same type as the input values
+func Ne(a, b *Node, retSame bool) (retVal *Node, err error) {
+	op := newElemBinOp(neOpType, a, b)
+	op.retSame = retSame
+	return binOpNode(op, a, b)
+}
+
+// And: pointwise logical a && b. a and b have to be Bool
+func And(a, b *Node) (retVal *Node, err error) {
+	return applyOp(newLogicalOp(andOpType, a, b), a, b)
+}
+
+// Or: pointwise logical a || b. a and b have to be Bool
+func Or(a, b *Node) (retVal *Node, err error) {
+	return applyOp(newLogicalOp(orOpType, a, b), a, b)
+}
+
+// Xor: pointwise logical exclusive or. a and b have to be Bool
+func Xor(a, b *Node) (retVal *Node, err error) {
+	return applyOp(newLogicalOp(xorOpType, a, b), a, b)
+}
+
+// Not: pointwise logical !a. a has to be Bool
+func Not(a *Node) (retVal *Node, err error) {
+	return applyOp(newLogicalOp(notOpType, a), a)
+}
+
+// Where picks the elements of a where cond is true, and the elements of b where cond is false.
+// cond is a Bool mask, typically created with one of the comparison ops with retSame set to false.
+// a and b may be scalars, or are broadcast to the shape of cond if needed. The gradient flows to a or b according to the mask.
+func Where(cond, a, b *Node) (retVal *Node, err error) {
+	if dt, err := dtypeOf(cond.t); err != nil || dt != Bool {
+		return nil, NewError(TypeError, "Where expects cond to be a Bool mask. Got %v instead", cond.t)
+	}
+
+	if cond.shape == nil {
+		err = NewError(ShapeError, "Where cannot infer the shape: %v has no shape", cond)
+		return
+	}
+
+	children := Nodes{cond, a, b}
+	for i, n := range children[1:] {
+		if n.IsScalar() {
+			continue
+		}
+		if n.shape == nil {
+			err = NewError(ShapeError, "Where cannot infer the shape: %v has no shape", n)
+			return
+		}
+		if n.shape.Eq(cond.shape) {
+			continue
+		}
+
+		var s types.Shape
+		if s, err = broadcastShape(n.shape, cond.shape); err != nil || !s.Eq(cond.shape) {
+			err = NewError(ShapeError, "Where cannot broadcast %v into the shape of the mask %v", n.shape, cond.shape)
+			return
+		}
+		if children[i+1], err = applyOp(newBroadcastOp(n.shape, cond.shape), n); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	return applyOp(newWhereOp(cond.shape, children[1], children[2]), children...)
+}
+
+// Clamp clamps the elements of a to the range [min, max]. min and max may be constants (float64, float32 or int), or scalar *Nodes.
+// The gradient flows through the elements that are within the range, and is zero for the elements that were clamped.
+// If the bounds are *Nodes, the gradients of the clamped elements flow to the bound that clamped them.
+func Clamp(a *Node, min, max interface{}) (retVal *Node, err error) {
+	op := clampOp{d: a.Dims()}
+
+	minNode, minIsNode := min.(*Node)
+	maxNode, maxIsNode := max.(*Node)
+	if !minIsNode && !maxIsNode {
+		if op.min, err = clampBound(min); err != nil {
+			return
+		}
+		if op.max, err = clampBound(max); err != nil {
+			return
+		}
+		if op.min > op.max {
+			err = NewError(RuntimeError, "Clamp: min (%v) is greater than max (%v)", op.min, op.max)
+			return
+		}
+		return applyOp(op, a)
+	}
+
+	var dt Dtype
+	if dt, err = dtypeOf(a.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, a.t)
+		return
+	}
+
+	bounds := []*Node{minNode, maxNode}
+	for i, b := range []interface{}{min, max} {
+		if bounds[i] == nil {
+			var v float64
+			if v, err = clampBound(b); err != nil {
+				return
+			}
+			if bounds[i], err = floatConstant(dt, v); err != nil {
+				return
+			}
+		}
+		if !bounds[i].IsScalar() {
+			err = NewError(ShapeError, "Clamp expects the bounds to be scalars. Got %v instead", bounds[i].shape)
+			return
+		}
+	}
+
+	op.nodeBounds = true
+	return applyOp(op, a, bounds[0], bounds[1])
+}
+
+func clampBound(b interface{}) (float64, error) {
+	switch v := b.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	}
+	return 0, NewError(TypeError, "Clamp expects the bounds to be float64, float32, int or *Node. Got %T instead", b)
+}
+
 /* UNARY STUFF */
 
+// Identity returns a node with the same value as a. Gradients flow through it unchanged.
+func Identity(a *Node) (retVal *Node, err error) { return applyOp(identityOp{}, a) }
+
+// StopGradient returns a node with the same value as a, through which no gradients flow. As far as differentiation is
+// concerned, the result is a constant - this is useful for target networks, straight-through estimators and the like.
+func StopGradient(a *Node) (retVal *Node, err error) { return applyOp(identityOp{stop: true}, a) }
+
 func unaryOpNode(op Op, a *Node) (retVal *Node, err error) {
 	stabLogf("Creating node for %v, a: %p", op, a)
 	enterLoggingContext()
@@ -254,6 +628,48 @@ func Cos(a *Node) (retVal *Node, err error) {
 	return unaryOpNode(op, a)
 }
 
+// Tan: pointwise tangent
+func Tan(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(tanOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Asin: pointwise arcsine
+func Asin(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(asinOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Acos: pointwise arccosine
+func Acos(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(acosOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Atan: pointwise arctangent
+func Atan(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(atanOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Sinh: pointwise hyperbolic sine
+func Sinh(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(sinhOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Cosh: pointwise hyperbolic cosine
+func Cosh(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(coshOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Atanh: pointwise inverse hyperbolic tangent
+func Atanh(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(atanhOpType, a)
+	return unaryOpNode(op, a)
+}
+
 func Exp(a *Node) (retVal *Node, err error) {
 	op := newElemUnaryOp(expOpType, a)
 	return unaryOpNode(op, a)
@@ -269,6 +685,12 @@ func Log2(a *Node) (retVal *Node, err error) {
 	return unaryOpNode(op, a)
 }
 
+// Log10: pointwise base 10 logarithm
+func Log10(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(log10OpType, a)
+	return unaryOpNode(op, a)
+}
+
 func Neg(a *Node) (retVal *Node, err error) {
 	op := newElemUnaryOp(negOpType, a)
 	return unaryOpNode(op, a)
@@ -309,6 +731,30 @@ func Log1p(a *Node) (retVal *Node, err error) {
 	return unaryOpNode(op, a)
 }
 
+// Erf: pointwise error function
+func Erf(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(erfOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Erfc: pointwise complementary error function, 1-erf(a). It is more accurate than 1-Erf(a) for large a
+func Erfc(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(erfcOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Lgamma: pointwise natural logarithm of the absolute value of the gamma function
+func Lgamma(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(lgammaOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Digamma: pointwise digamma function ψ(a), the derivative of Lgamma. The poles (0, -1, -2...) are NaN
+func Digamma(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(digammaOpType, a)
+	return unaryOpNode(op, a)
+}
+
 // more complex unaries
 
 func SoftMax(a *Node) (retVal *Node, err error) {
@@ -345,11 +791,94 @@ func StableSoftMax(a *Node) (retVal *Node, err error) {
 	return
 }
 
+// LogSumExp computes log(Σ exp(a)) along the given axis. The maximum along the axis is subtracted before
+// exponentiating, so it does not overflow like Log(Sum(Exp(a))) would.
+func LogSumExp(a *Node, axis int) (retVal *Node, err error) {
+	if err = checkSoftMaxAxis("LogSumExp", a, axis); err != nil {
+		return
+	}
+
+	op := newLogSumExpOp(axis, a.shape, a.Dims())
+	return applyOp(op, a)
+}
+
+// LogSoftMax computes log(softmax(a)) along the given axis, as a - LogSumExp(a, axis).
+// Log(SoftMax(a)) is computed the same way when stabilization is on.
+func LogSoftMax(a *Node, axis int) (retVal *Node, err error) {
+	if err = checkSoftMaxAxis("LogSoftMax", a, axis); err != nil {
+		return
+	}
+
+	op := newLogSoftMaxOp(axis, a.shape, a.Dims())
+	return applyOp(op, a)
+}
+
+func checkSoftMaxAxis(name string, a *Node, axis int) error {
+	if _, ok := a.t.(*TensorType); !ok || a.IsScalar() {
+		return NewError(TypeError, "%s expects a Tensor. Got %v instead", name, a.t)
+	}
+	if a.shape == nil {
+		return NewError(ShapeError, "%s cannot infer the shape: %v has no shape", name, a)
+	}
+	if axis < 0 || axis >= len(a.shape) {
+		return NewError(ShapeError, "%s cannot be done along axis %d of a tensor of shape %v", name, axis, a.shape)
+	}
+	return nil
+}
+
 func Softplus(a *Node) (retVal *Node, err error) {
 	op := newElemUnaryOp(softplusOpType, a)
 	return unaryOpNode(op, a)
 }
 
+// LeakyReLU is x if x > 0 and alpha·x otherwise.
+func LeakyReLU(a *Node, alpha float64) (retVal *Node, err error) {
+	op := newElemUnaryOp(leakyReluOpType, a)
+	op.alpha = alpha
+	return unaryOpNode(op, a)
+}
+
+// ELU is the exponential linear unit: x if x > 0 and alpha·(exp(x)-1) otherwise.
+func ELU(a *Node, alpha float64) (retVal *Node, err error) {
+	op := newElemUnaryOp(eluOpType, a)
+	op.alpha = alpha
+	return unaryOpNode(op, a)
+}
+
+// SELU is the scaled exponential linear unit, with the constants from Klambauer et al. (2017).
+func SELU(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(seluOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// GELU is the Gaussian error linear unit x·Φ(x), where Φ is the CDF of the standard normal distribution.
+// The exact form is computed, not the tanh approximation.
+func GELU(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(geluOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// Swish is x·sigmoid(x). It is also known as SiLU.
+func Swish(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(swishOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// SiLU is the sigmoid linear unit. It is the same as Swish.
+func SiLU(a *Node) (retVal *Node, err error) { return Swish(a) }
+
+// Mish is x·tanh(softplus(x)).
+func Mish(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(mishOpType, a)
+	return unaryOpNode(op, a)
+}
+
+// HardSigmoid is the piecewise linear approximation of the sigmoid, min(max(x/6 + 0.5, 0), 1).
+func HardSigmoid(a *Node) (retVal *Node, err error) {
+	op := newElemUnaryOp(hardSigmoidOpType, a)
+	return unaryOpNode(op, a)
+}
+
 /* Aggregate Functions */
 
 func At(a *Node, coords ...int) (retVal *Node, err error) {
@@ -379,16 +908,96 @@ func Max(a *Node, along ...int) (retVal *Node, err error) {
 		return a, nil
 	}
 
-	dims := a.Dims()
-	if len(along) == 0 {
-		along = intRange(0, dims)
+	if along, err = reductionAxes(a, along); err != nil {
+		return
+	}
+
+	op := newMaxOp(along, a.shape.Clone(), a.Dims())
+	return applyOp(op, a)
+}
+
+// Min returns the smallest values of a along the given axes. If no axes are given, a is reduced to a scalar.
+// The gradient flows to every element that is equal to the minimum.
+func Min(a *Node, along ...int) (retVal *Node, err error) {
+	if a.IsScalar() {
+		return a, nil
+	}
+
+	if along, err = reductionAxes(a, along); err != nil {
+		return
+	}
+
+	op := newMinOp(along, a.shape.Clone(), a.Dims())
+	return applyOp(op, a)
+}
+
+// Norm computes the p-norm of a along the given axes. If no axes are given, a is reduced to a scalar - for a matrix, the
+// 2-norm is then the Frobenius norm. p has to be positive, and may be math.Inf(1) for the maximum norm.
+// The gradient is 0 where the norm is 0, so a zero vector does not produce NaN gradients.
+func Norm(a *Node, p float64, along ...int) (retVal *Node, err error) {
+	if p <= 0 || math.IsNaN(p) {
+		err = NewError(RuntimeError, "Norm expects p to be positive. Got %v instead", p)
+		return
+	}
+
+	if a.IsScalar() {
+		return Abs(a)
 	}
 
-	op := newMaxOp(along, dims)
+	if along, err = reductionAxes(a, along); err != nil {
+		return
+	}
 
+	op := newNormOp(p, along, a.shape.Clone(), a.Dims())
 	return applyOp(op, a)
 }
 
+// Argmax returns the indices of the largest values of a along the given axis. Ties resolve to the first index.
+// The result is an Int tensor (or an Int if a is a vector), and is not differentiable.
+func Argmax(a *Node, axis int) (retVal *Node, err error) {
+	return argExtremum(a, axis, false)
+}
+
+// Argmin returns the indices of the smallest values of a along the given axis. Ties resolve to the first index.
+// The result is an Int tensor (or an Int if a is a vector), and is not differentiable.
+func Argmin(a *Node, axis int) (retVal *Node, err error) {
+	return argExtremum(a, axis, true)
+}
+
+func argExtremum(a *Node, axis int, min bool) (retVal *Node, err error) {
+	if a.IsScalar() {
+		err = NewError(GraphError, "Cannot find the index of the extremum of a scalar")
+		return
+	}
+
+	if _, err = reductionAxes(a, []int{axis}); err != nil {
+		return
+	}
+
+	op := newArgOp(axis, a.shape.Clone(), a.Dims(), min)
+	return applyOp(op, a)
+}
+
+// reductionAxes checks the axes that a is to be reduced along. No axes means all axes.
+func reductionAxes(a *Node, along []int) ([]int, error) {
+	s := a.Shape()
+	if len(along) == 0 {
+		return intRange(0, len(s)), nil
+	}
+
+	for i, axis := range along {
+		if axis < 0 || axis >= len(s) {
+			return nil, NewError(ShapeError, "Cannot reduce along axis %d of a tensor of shape %v", axis, s)
+		}
+		for _, other := range along[:i] {
+			if other == axis {
+				return nil, NewError(ShapeError, "Axis %d is repeated in %v", axis, along)
+			}
+		}
+	}
+	return along, nil
+}
+
 func Mean(a *Node, along ...int) (retVal *Node, err error) {
 	if a.IsScalar() {
 		// can't mean a scalar... return error
@@ -424,6 +1033,42 @@ func Mean(a *Node, along ...int) (retVal *Node, err error) {
 	return
 }
 
+// Var computes the variance of a along the given axes. If no axes are given, a is reduced to a scalar.
+// The sum of the squared deviations is divided by N - ddof, where N is the number of elements reduced into each variance:
+// a ddof of 0 gives the population variance, and a ddof of 1 gives the sample variance.
+func Var(a *Node, ddof int, along ...int) (retVal *Node, err error) {
+	return variance(a, ddof, false, along)
+}
+
+// Std computes the standard deviation of a along the given axes. See Var for the meaning of ddof.
+// The gradient is 0 where the standard deviation is 0.
+func Std(a *Node, ddof int, along ...int) (retVal *Node, err error) {
+	return variance(a, ddof, true, along)
+}
+
+func variance(a *Node, ddof int, std bool, along []int) (retVal *Node, err error) {
+	if _, ok := a.t.(*TensorType); !ok || a.IsScalar() {
+		err = NewError(TypeError, "Var and Std expect a Tensor. Got %v instead", a.t)
+		return
+	}
+
+	if along, err = reductionAxes(a, along); err != nil {
+		return
+	}
+
+	n := 1
+	for _, axis := range along {
+		n *= a.shape[axis]
+	}
+	if ddof < 0 || n <= ddof {
+		err = NewError(RuntimeError, "Var and Std cannot reduce %d elements with a ddof of %d", n, ddof)
+		return
+	}
+
+	op := newVarianceOp(ddof, std, along, a.shape.Clone(), a.Dims())
+	return applyOp(op, a)
+}
+
 func Sum(a *Node, along ...int) (retVal *Node, err error) {
 	if a.IsScalar() {
 		retVal = a // or error?
@@ -532,6 +1177,273 @@ func SizeOf(axis int, x *Node) (retVal *Node, err error) {
 	return applyOp(op, x)
 }
 
+// Reshape reshapes a *Node into the given shape. At most one of the dimensions may be -1,
+// in which case it is inferred from the total size of the *Node. The underlying data is not changed.
+func Reshape(n *Node, shape ...int) (retVal *Node, err error) {
+	if n.shape == nil {
+		err = NewError(ShapeError, "Cannot reshape %v: its shape is unknown", n)
+		return
+	}
+
+	to := make(types.Shape, len(shape))
+	copy(to, shape)
+
+	infer := -1
+	size := 1
+	for i, s := range to {
+		switch {
+		case s == -1 && infer == -1:
+			infer = i
+		case s == -1:
+			err = NewError(ShapeError, "Cannot reshape %v into %v: only one dimension can be inferred", n.shape, shape)
+			return
+		case s <= 0:
+			err = NewError(ShapeError, "Cannot reshape %v into %v: dimensions must be positive", n.shape, shape)
+			return
+		default:
+			size *= s
+		}
+	}
+
+	total := n.shape.TotalSize()
+	if infer >= 0 {
+		if total%size != 0 {
+			err = NewError(ShapeError, "Cannot reshape %v into %v", n.shape, shape)
+			return
+		}
+		to[infer] = total / size
+	}
+
+	if to.TotalSize() != total {
+		err = NewError(ShapeError, "Cannot reshape %v into %v. Total sizes differ", n.shape, shape)
+		return
+	}
+
+	op := newReshapeOp(n.shape, to, n.Dims())
+	return applyOp(op, n)
+}
+
+// Transpose permutes the axes of a *Node. If no axes are given, the axes are reversed (i.e. a matrix transpose)
+func Transpose(n *Node, axes ...int) (retVal *Node, err error) {
+	if _, ok := n.t.(*TensorType); !ok {
+		err = NewError(GraphError, "Cannot transpose non Tensor types. Got %T", n.t)
+		return
+	}
+
+	if n.shape == nil {
+		err = NewError(ShapeError, "Cannot transpose %v: its shape is unknown", n)
+		return
+	}
+
+	dims := len(n.shape)
+	if len(axes) == 0 {
+		axes = make([]int, dims)
+		for i := range axes {
+			axes[i] = dims - 1 - i
+		}
+	}
+
+	if len(axes) != dims {
+		err = NewError(ShapeError, "Cannot transpose %v with axes %v", n.shape, axes)
+		return
+	}
+
+	seen := make([]bool, dims)
+	for _, a := range axes {
+		if a < 0 || a >= dims || seen[a] {
+			err = NewError(ShapeError, "Axes %v is not a valid permutation for %v", axes, n.shape)
+			return
+		}
+		seen[a] = true
+	}
+
+	pattern := make([]int, dims)
+	copy(pattern, axes)
+	op := newTransposeOp(pattern, n.Dims())
+	return applyOp(op, n)
+}
+
+// Concat concatenates the *Nodes along the given axis. All the *Nodes must have the same shape, except along the axis.
+func Concat(axis int, nodes ...*Node) (retVal *Node, err error) {
+	if len(nodes) == 0 {
+		err = NewError(GraphError, "Concat requires at least one *Node")
+		return
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+
+	shapes := make([]types.Shape, len(nodes))
+	dims := make([]int, len(nodes))
+	for i, n := range nodes {
+		if _, ok := n.t.(*TensorType); !ok {
+			err = NewError(GraphError, "Cannot concatenate non Tensor types. Got %T", n.t)
+			return
+		}
+
+		if n.shape == nil {
+			err = NewError(ShapeError, "Cannot concatenate %v: its shape is unknown", n)
+			return
+		}
+		shapes[i] = n.shape
+		dims[i] = n.Dims()
+	}
+
+	var s types.Shape
+	if s, err = concatShape(axis, shapes...); err != nil {
+		return
+	}
+
+	op := concatOp{
+		axis: axis,
+		dims: dims,
+		retD: s.Dims(),
+	}
+	return applyOp(op, nodes...)
+}
+
+// Stack joins the *Nodes along a new axis. All the *Nodes must have the same shape.
+func Stack(axis int, nodes ...*Node) (retVal *Node, err error) {
+	if len(nodes) == 0 {
+		err = NewError(GraphError, "Stack requires at least one *Node")
+		return
+	}
+
+	first := nodes[0].shape
+	if first == nil {
+		err = NewError(ShapeError, "Cannot stack %v: its shape is unknown", nodes[0])
+		return
+	}
+
+	if axis < 0 || axis > len(first) {
+		err = NewError(ShapeError, "Cannot stack %v along axis %d", first, axis)
+		return
+	}
+
+	// each *Node gets a new axis of size 1, and the results are concatenated along that axis
+	s := make(types.Shape, 0, len(first)+1)
+	s = append(s, first[:axis]...)
+	s = append(s, 1)
+	s = append(s, first[axis:]...)
+
+	reshaped := make(Nodes, len(nodes))
+	for i, n := range nodes {
+		if !first.Eq(n.shape) {
+			err = NewError(ShapeError, "Cannot stack %v and %v: all shapes must be the same", first, n.shape)
+			return
+		}
+
+		if reshaped[i], err = Reshape(n, s...); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+	}
+	return Concat(axis, reshaped...)
+}
+
+// Split splits a *Node along the axis into pieces of the given sizes. The sizes must add up to the size of the axis.
+func Split(n *Node, axis int, sizes ...int) (retVal Nodes, err error) {
+	if _, ok := n.t.(*TensorType); !ok {
+		err = NewError(GraphError, "Cannot split non Tensor types. Got %T", n.t)
+		return
+	}
+
+	if n.shape == nil {
+		err = NewError(ShapeError, "Cannot split %v: its shape is unknown", n)
+		return
+	}
+
+	if axis < 0 || axis >= len(n.shape) {
+		err = NewError(ShapeError, "Cannot split %v along axis %d", n.shape, axis)
+		return
+	}
+
+	var total int
+	for _, size := range sizes {
+		if size <= 0 {
+			err = NewError(ShapeError, "Cannot split %v into pieces of sizes %v", n.shape, sizes)
+			return
+		}
+		total += size
+	}
+
+	if total != n.shape[axis] {
+		err = NewError(ShapeError, "Cannot split %v into pieces of sizes %v along axis %d", n.shape, sizes, axis)
+		return
+	}
+
+	var start int
+	for _, size := range sizes {
+		op := newSliceOp(start, start+size, axis, n.Dims())
+
+		var sliced *Node
+		if sliced, err = applyOp(op, n); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+
+		// slices are views, and they lose the axis if the size is 1.
+		// Reshaping makes them contiguous and restores the axis.
+		s := n.shape.Clone()
+		s[axis] = size
+		if sliced, err = Reshape(sliced, s...); err != nil {
+			err = errors.Wrap(err, operationError)
+			return
+		}
+
+		retVal = append(retVal, sliced)
+		start += size
+	}
+	return
+}
+
+// Gather picks out the slices of params at the given indices along the axis. The indices must be Ints.
+// The shape of the result is the shape of params, with the axis replaced by the shape of the indices.
+// For example, gathering a vector of 3 indices along axis 0 of a (vocab, dim) embedding matrix gives a (3, dim) matrix.
+//
+// The gradient only accumulates into the slices that were gathered.
+func Gather(params, indices *Node, axis int) (retVal *Node, err error) {
+	if err = checkGather(params, indices, axis); err != nil {
+		return
+	}
+
+	op := newGatherOp(axis, params.shape.Clone(), indices.shape.Clone())
+	return applyOp(op, params, indices)
+}
+
+// ScatterAdd adds the slices of updates into dst at the given indices along the axis. Repeated indices are accumulated.
+// It is the counterpart of Gather: updates must have the shape of Gather(dst, indices, axis).
+func ScatterAdd(dst, indices, updates *Node, axis int) (retVal *Node, err error) {
+	if err = checkGather(dst, indices, axis); err != nil {
+		return
+	}
+
+	expected := gatherShape(dst.shape, indices.shape, axis)
+	if !updates.shape.Eq(expected) {
+		err = NewError(ShapeError, "ScatterAdd expects updates of shape %v. Got %v instead", expected, updates.shape)
+		return
+	}
+
+	op := newScatterAddOp(axis, dst.shape.Clone(), indices.shape.Clone(), true)
+	return applyOp(op, dst, indices, updates)
+}
+
+func checkGather(params, indices *Node, axis int) error {
+	if params.IsScalar() {
+		return NewError(GraphError, "Cannot gather from a scalar")
+	}
+
+	if axis < 0 || axis >= len(params.shape) {
+		return NewError(ShapeError, "Cannot gather along axis %d of a tensor of shape %v", axis, params.shape)
+	}
+
+	if dt, err := dtypeOf(indices.t); err != nil || dt != Int {
+		return NewError(TypeError, "Expected the indices to be Ints. Got %v instead", indices.t)
+	}
+	return nil
+}
+
 // Slice slices a *Node. For T[:] slices, pass in nil. Will error out if node's type is not a Tensor
 func Slice(n *Node, slices ...types.Slice) (retVal *Node, err error) {
 	if _, ok := n.t.(*TensorType); !ok {
@@ -1,6 +1,7 @@
 package gorgonia
 
 import (
+	"math"
 	"testing"
 
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
@@ -47,7 +48,16 @@ func TestSoftMax(t *testing.T) {
 	logsm := Must(Neg(Must(Log(sm))))
 	cost := Must(Slice(logsm, S(2)))
 
-	grads, _ := Grad(cost, sm)
+	// with stabilization, log(softmax(x)) is computed as logsoftmax(x). It is differentiated through the softmax once
+	// it is in the wrt of Grad
+	if _, ok := logsm.children[0].op.(logOfSoftMaxOp); !ok {
+		t.Errorf("Expected Log(SoftMax(x)) to be stabilized. Got %v instead", logsm.children[0].op)
+	}
+
+	grads, err := Grad(cost, sm)
+	if err != nil {
+		t.Fatal(err)
+	}
 	prog, locMap, err := Compile(g)
 	if err != nil {
 		t.Error(err)
@@ -64,11 +74,31 @@ func TestSoftMax(t *testing.T) {
 		t.Error(err)
 	}
 
-	// machine 2, graph 2
+	xs := []float64{0.1, 0.2, -0.3, 0.4, 0.5}
+	var sum float64
+	for _, v := range xs {
+		sum += math.Exp(v)
+	}
+	assert.InDelta(-math.Log(math.Exp(-0.3)/sum), cost.Value().(Scalar).v, 1e-10)
+
+	// the gradient of -log(sm[2]) is -1/sm[2] at 2, and 0 everywhere else
+	correct := make([]float64, len(xs))
+	correct[2] = -sum / math.Exp(-0.3)
+	for i, v := range extractF64s(smg) {
+		assert.InDelta(correct[i], v, 1e-10)
+	}
+
+	// machine 2, graph 2. The lisp machine has no wrt to tell it to differentiate through the softmax, so log(softmax(x))
+	// isn't stabilized in the first place
+	saved := stabilization
+	stabilization = false
+	defer func() {
+		stabilization = saved
+	}()
 
 	g2 := NewGraph()
 	xT2 := tf64.NewTensor(tf64.WithBacking([]float64{0.1, 0.2, -0.3, 0.4, 0.5}))
-	x2 := NewVector(g, Float64, WithShape(5, 1), WithValue(xT2))
+	x2 := NewVector(g2, Float64, WithShape(5, 1), WithValue(xT2))
 	sm2 := Must(SoftMax(x2))
 	logsm2 := Must(Neg(Must(Log(sm2))))
 	Must(Slice(logsm2, S(2)))
@@ -252,3 +282,458 @@ mattest3:
 	assert.Equal([]float64{-1.49, -1}, extractF64s(sz.Value()))
 
 }
+
+func TestCmpOps(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(5), WithInit(RangedFrom(0)))
+	y := NewVector(g, Float64, WithName("y"), WithShape(5), WithValue(tf64.NewTensor(tf64.WithBacking([]float64{4, 3, 2, 1, 0}))))
+	two := NewConstant(2.0)
+
+	lt := Must(Lt(x, y, true))
+	lte := Must(Lte(x, two, true))
+	eq := Must(Eq(x, y, false))
+	ne := Must(Ne(two, x, false))
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]float64{1, 1, 0, 0, 0}, lt.Value().(Tensor).Tensor.Data())
+	assert.Equal([]float64{1, 1, 1, 0, 0}, lte.Value().(Tensor).Tensor.Data())
+	assert.Equal([]bool{false, false, true, false, false}, eq.Value().(Tensor).Tensor.Data())
+	assert.Equal([]bool{true, true, false, true, true}, ne.Value().(Tensor).Tensor.Data())
+}
+
+func TestLogicalOps(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(5), WithInit(RangedFrom(0)))
+	one := NewConstant(1.0)
+	three := NewConstant(3.0)
+
+	gt := Must(Gt(x, one, false))
+	lt := Must(Lt(x, three, false))
+
+	and := Must(And(gt, lt))
+	or := Must(Or(gt, lt))
+	xor := Must(Xor(gt, lt))
+	not := Must(Not(gt))
+	scalar := Must(And(gt, NewConstant(true)))
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]bool{false, false, true, false, false}, and.Value().(Tensor).Tensor.Data())
+	assert.Equal([]bool{true, true, true, true, true}, or.Value().(Tensor).Tensor.Data())
+	assert.Equal([]bool{true, true, false, true, true}, xor.Value().(Tensor).Tensor.Data())
+	assert.Equal([]bool{true, true, false, false, false}, not.Value().(Tensor).Tensor.Data())
+	assert.Equal([]bool{false, false, true, true, true}, scalar.Value().(Tensor).Tensor.Data())
+
+	// logical ops only work on Bools
+	_, err = And(x, x)
+	assert.NotNil(err)
+}
+
+func TestWhere(t *testing.T) {
+	assert := assert.New(t)
+	build := func() (g *ExprGraph, x, y, z, cost *Node) {
+		g = NewGraph()
+		x = NewVector(g, Float64, WithName("x"), WithShape(5), WithInit(RangedFrom(0)))
+		y = NewVector(g, Float64, WithName("y"), WithShape(5), WithValue(tf64.NewTensor(tf64.WithShape(5), tf64.WithBacking([]float64{10, 20, 30, 40, 50}))))
+		cond := Must(Gt(x, NewConstant(2.0), false))
+
+		z = Must(Where(cond, Must(HadamardProd(x, x)), y))
+		cost = Must(Sum(z))
+		return
+	}
+
+	correctZ := []float64{10, 20, 30, 9, 16}
+	correctClipped := []float64{0, 1, 2, 2, 2}
+	correctXGrad := []float64{0, 0, 0, 6, 8}
+	correctYGrad := []float64{1, 1, 1, 0, 0}
+
+	g, x, y, z, cost := build()
+	clipped := Must(Where(Must(Gt(x, NewConstant(2.0), false)), NewConstant(2.0), x))
+	if _, err := Grad(cost, x, y); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewTapeMachine(prog, locMap)
+	if err = m.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(correctZ, extractF64s(z.Value()))
+	assert.Equal(correctClipped, extractF64s(clipped.Value()))
+	xG, _ := x.Grad()
+	yG, _ := y.Grad()
+	assert.Equal(correctXGrad, extractF64s(xG))
+	assert.Equal(correctYGrad, extractF64s(yG))
+
+	// lisp machine
+	g2, x2, y2, z2, _ := build()
+	m2 := NewLispMachine(g2)
+	if err = m2.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(correctZ, extractF64s(z2.Value()))
+	x2G, _ := x2.Grad()
+	y2G, _ := y2.Grad()
+	assert.Equal(correctXGrad, extractF64s(x2G))
+	assert.Equal(correctYGrad, extractF64s(y2G))
+
+	// cond has to be a Bool mask
+	_, err = Where(x, x, y)
+	assert.NotNil(err)
+}
+
+// unaryOpCase describes a pointwise unary function and its float64 reference. If diff is nil,
+// the reference derivative is found by central differences of ref.
+type unaryOpCase struct {
+	name string
+	fn   func(*Node) (*Node, error)
+	ref  func(float64) float64
+	diff func(float64) float64
+	xs   []float64 // overrides the default inputs
+}
+
+// checkUnaryOps checks that each function compiles to a single op, and that its values and its gradients
+// (on the tape machine and the lisp machine) agree with the reference
+func checkUnaryOps(t *testing.T, xs []float64, cases []unaryOpCase) {
+	assert := assert.New(t)
+	build := func(fn func(*Node) (*Node, error), xs []float64) (g *ExprGraph, x, y *Node) {
+		g = NewGraph()
+		x = NewVector(g, Float64, WithName("x"), WithShape(len(xs)), WithValue(tf64.NewTensor(tf64.WithShape(len(xs)), tf64.WithBacking(xs))))
+		y = Must(fn(x))
+		Must(Sum(y))
+		return
+	}
+
+	for _, c := range cases {
+		xs := xs
+		if c.xs != nil {
+			xs = c.xs
+		}
+
+		correctY := make([]float64, len(xs))
+		correctGrad := make([]float64, len(xs))
+		for i, v := range xs {
+			const h = 1e-6
+			correctY[i] = c.ref(v)
+			if c.diff != nil {
+				correctGrad[i] = c.diff(v)
+			} else {
+				correctGrad[i] = (c.ref(v+h) - c.ref(v-h)) / (2 * h)
+			}
+		}
+
+		// the function is a single op
+		g, x, y := build(c.fn, xs)
+		_, ok := y.op.(elemUnaryOp)
+		assert.True(ok, c.name)
+
+		// symbolic differentiation
+		if _, err := Grad(g.Roots()[0], x); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		xG, _ := x.Grad()
+		for i, v := range extractF64s(xG) {
+			assert.InDelta(correctGrad[i], v, 1e-6, c.name+" symbolic gradient")
+		}
+
+		// automatic differentiation
+		_, x, y = build(c.fn, xs)
+		if err = NewLispMachine(x.g).RunAll(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		xG, _ = x.Grad()
+		for i, v := range extractF64s(y.Value()) {
+			assert.InDelta(correctY[i], v, 1e-9, c.name)
+		}
+		for i, v := range extractF64s(xG) {
+			assert.InDelta(correctGrad[i], v, 1e-6, c.name+" autodiff gradient")
+		}
+
+		// Float32 uses the float32 kernels
+		g = NewGraph()
+		x = NewScalar(g, Float32, WithName("x"), WithValue(float32(xs[1])))
+		y = Must(c.fn(x))
+		if err = NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		assert.InDelta(c.ref(float64(float32(xs[1]))), y.Value().(Scalar).v.(float32), 1e-5, c.name+" Float32")
+	}
+}
+
+func TestActivations(t *testing.T) {
+	assert := assert.New(t)
+
+	elu := func(x, alpha float64) float64 {
+		if x > 0 {
+			return x
+		}
+		return alpha * (math.Exp(x) - 1)
+	}
+	sigmoid := func(x float64) float64 { return 1 / (1 + math.Exp(-x)) }
+
+	checkUnaryOps(t, []float64{-4, -1.5, -0.3, 0.2, 1, 3.5}, []unaryOpCase{
+		{name: "LeakyReLU", fn: func(a *Node) (*Node, error) { return LeakyReLU(a, 0.2) }, ref: func(x float64) float64 { return math.Max(x, 0.2*x) }},
+		{name: "ELU", fn: func(a *Node) (*Node, error) { return ELU(a, 0.5) }, ref: func(x float64) float64 { return elu(x, 0.5) }},
+		{name: "SELU", fn: SELU, ref: func(x float64) float64 { return 1.0507009873554805 * elu(x, 1.6732632423543772) }},
+		{name: "GELU", fn: GELU, ref: func(x float64) float64 { return 0.5 * x * (1 + math.Erf(x/math.Sqrt2)) }},
+		{name: "Swish", fn: Swish, ref: func(x float64) float64 { return x * sigmoid(x) }},
+		{name: "SiLU", fn: SiLU, ref: func(x float64) float64 { return x * sigmoid(x) }},
+		{name: "Mish", fn: Mish, ref: func(x float64) float64 { return x * math.Tanh(math.Log1p(math.Exp(x))) }},
+		{name: "HardSigmoid", fn: HardSigmoid, ref: func(x float64) float64 { return math.Min(math.Max(x/6+0.5, 0), 1) }},
+	})
+
+	// the alpha of a parameterized activation is part of its identity
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(2))
+	a := Must(LeakyReLU(x, 0.1))
+	b := Must(LeakyReLU(x, 0.2))
+	c := Must(LeakyReLU(x, 0.1))
+	assert.NotEqual(a, b)
+	assert.Equal(a, c)
+	assert.Equal("leakyrelu(0.2)", b.op.String())
+}
+
+func TestSpecialFunctions(t *testing.T) {
+	assert := assert.New(t)
+
+	// the series ψ(x) = -γ + Σ 1/(k+1) - 1/(k+x) and ψ₁(x) = Σ 1/(k+x)², truncated with their tails
+	const terms = 1000000
+	digamma := func(x float64) float64 {
+		var s float64
+		for k := terms - 1; k >= 0; k-- {
+			s += 1/float64(k+1) - 1/(float64(k)+x)
+		}
+		return -0.5772156649015329 + s + (x-1)/terms
+	}
+	trigamma := func(x float64) float64 {
+		var s float64
+		for k := terms - 1; k >= 0; k-- {
+			s += 1 / ((float64(k) + x) * (float64(k) + x))
+		}
+		return s + 1/(terms+x)
+	}
+	lgamma := func(x float64) float64 {
+		r, _ := math.Lgamma(x)
+		return r
+	}
+
+	checkUnaryOps(t, []float64{-0.8, -0.3, 0.2, 0.6, 0.9}, []unaryOpCase{
+		{name: "Tan", fn: Tan, ref: math.Tan},
+		{name: "Asin", fn: Asin, ref: math.Asin},
+		{name: "Acos", fn: Acos, ref: math.Acos},
+		{name: "Atan", fn: Atan, ref: math.Atan, xs: []float64{-20, -0.3, 0.5, 3}},
+		{name: "Sinh", fn: Sinh, ref: math.Sinh, xs: []float64{-3, -0.3, 0.5, 2}},
+		{name: "Cosh", fn: Cosh, ref: math.Cosh, xs: []float64{-3, -0.3, 0.5, 2}},
+		{name: "Atanh", fn: Atanh, ref: math.Atanh},
+		{name: "Log10", fn: Log10, ref: math.Log10, xs: []float64{0.2, 1.5, 30}},
+		{name: "Erf", fn: Erf, ref: math.Erf, xs: []float64{-3, -0.3, 0.5, 2}},
+		{name: "Erfc", fn: Erfc, ref: math.Erfc, xs: []float64{-3, -0.3, 0.5, 2}},
+		{name: "Lgamma", fn: Lgamma, ref: lgamma, diff: digamma, xs: []float64{-2.5, -0.3, 0.5, 2, 15}},
+		{name: "Digamma", fn: Digamma, ref: digamma, diff: trigamma, xs: []float64{-2.5, -0.3, 0.5, 2, 15}},
+	})
+
+	// the poles of digamma are NaN
+	assert.True(math.IsNaN(_digammaf64(0)))
+	assert.True(math.IsNaN(_digammaf64(-3)))
+}
+
+func TestAtan2(t *testing.T) {
+	assert := assert.New(t)
+	build := func() (g *ExprGraph, a, b, z *Node) {
+		g = NewGraph()
+		a = NewVector(g, Float64, WithName("a"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking([]float64{1, -1, 0.5, 2}))))
+		b = NewScalar(g, Float64, WithName("b"), WithValue(-1.0))
+		z = Must(Atan2(a, b))
+		Must(Sum(z))
+		return
+	}
+
+	as := []float64{1, -1, 0.5, 2}
+	correctZ := make([]float64, len(as))
+	correctAGrad := make([]float64, len(as))
+	var correctBGrad float64
+	for i, a := range as {
+		correctZ[i] = math.Atan2(a, -1)
+		correctAGrad[i] = -1 / (a*a + 1)
+		correctBGrad += -a / (a*a + 1)
+	}
+
+	// symbolic differentiation
+	g, a, b, z := build()
+	if _, err := Grad(g.Roots()[0], a, b); err != nil {
+		t.Fatal(err)
+	}
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	aG, _ := a.Grad()
+	bG, _ := b.Grad()
+	for i, v := range extractF64s(aG) {
+		assert.InDelta(correctAGrad[i], v, 1e-12)
+	}
+	assert.InDelta(correctBGrad, bG.(Scalar).v.(float64), 1e-12)
+
+	// automatic differentiation
+	_, a, b, z = build()
+	if err = NewLispMachine(a.g).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range extractF64s(z.Value()) {
+		assert.InDelta(correctZ[i], v, 1e-12)
+	}
+	aG, _ = a.Grad()
+	bG, _ = b.Grad()
+	for i, v := range extractF64s(aG) {
+		assert.InDelta(correctAGrad[i], v, 1e-12)
+	}
+	assert.InDelta(correctBGrad, bG.(Scalar).v.(float64), 1e-12)
+}
+
+func TestClamp(t *testing.T) {
+	assert := assert.New(t)
+	xs := []float64{-2, -0.5, 0.5, 2}
+	correctZ := []float64{-1, -0.5, 0.5, 1}
+
+	// the cost is Σz², so the gradient of z is 2z
+	correctXGrad := []float64{0, -1, 1, 0}
+	correctMinGrad := -2.0
+	correctMaxGrad := 2.0
+
+	build := func(nodeBounds bool) (g *ExprGraph, x, min, max, z *Node) {
+		g = NewGraph()
+		x = NewVector(g, Float64, WithName("x"), WithShape(4), WithValue(tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking(append([]float64{}, xs...)))))
+		if nodeBounds {
+			min = NewScalar(g, Float64, WithName("min"), WithValue(-1.0))
+			max = NewScalar(g, Float64, WithName("max"), WithValue(1.0))
+			z = Must(Clamp(x, min, max))
+		} else {
+			z = Must(Clamp(x, -1, float32(1)))
+		}
+		Must(Sum(Must(Square(z))))
+		return
+	}
+
+	for _, nodeBounds := range []bool{false, true} {
+		// symbolic differentiation
+		g, x, min, max, z := build(nodeBounds)
+		wrt := Nodes{x}
+		if nodeBounds {
+			wrt = append(wrt, min, max)
+		}
+		if _, err := Grad(g.Roots()[0], wrt...); err != nil {
+			t.Fatal(err)
+		}
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range extractF64s(z.Value()) {
+			assert.Equal(correctZ[i], v)
+		}
+		xG, _ := x.Grad()
+		for i, v := range extractF64s(xG) {
+			assert.Equal(correctXGrad[i], v)
+		}
+		if nodeBounds {
+			minG, _ := min.Grad()
+			maxG, _ := max.Grad()
+			assert.Equal(correctMinGrad, minG.(Scalar).v.(float64))
+			assert.Equal(correctMaxGrad, maxG.(Scalar).v.(float64))
+		}
+
+		// automatic differentiation
+		g, x, min, max, z = build(nodeBounds)
+		if err = NewLispMachine(g).RunAll(); err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range extractF64s(z.Value()) {
+			assert.Equal(correctZ[i], v)
+		}
+		xG, _ = x.Grad()
+		for i, v := range extractF64s(xG) {
+			assert.Equal(correctXGrad[i], v)
+		}
+		if nodeBounds {
+			minG, _ := min.Grad()
+			maxG, _ := max.Grad()
+			assert.Equal(correctMinGrad, minG.(Scalar).v.(float64))
+			assert.Equal(correctMaxGrad, maxG.(Scalar).v.(float64))
+		}
+	}
+
+	// scalars and float32
+	g := NewGraph()
+	s := NewScalar(g, Float32, WithValue(float32(3)))
+	z := Must(Clamp(s, 0, 2))
+	if err := NewLispMachine(g, ExecuteFwdOnly()).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(float32(2), z.Value().(Scalar).v)
+
+	// UnsafeDo clamps the input in place
+	T := tf64.NewTensor(tf64.WithShape(4), tf64.WithBacking(append([]float64{}, xs...)))
+	v, err := clampOp{min: -1, max: 1, d: 1}.UnsafeDo(FromTensor(T))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(v.(Tensor).Tensor == T)
+	assert.Equal(correctZ, T.Data())
+
+	/* Idiots */
+
+	g = NewGraph()
+	x := NewVector(g, Float64, WithShape(4))
+	if _, err = Clamp(x, 1, -1); err == nil {
+		t.Error("Expected an error when min > max")
+	}
+	if _, err = Clamp(x, "a", 1); err == nil {
+		t.Error("Expected an error for a bound that is not a number")
+	}
+	if _, err = Clamp(x, NewVector(g, Float64, WithShape(2)), 1); err == nil {
+		t.Error("Expected an error for a bound that is not a scalar")
+	}
+}
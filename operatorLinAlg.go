@@ -11,6 +11,7 @@ const (
 	matVecMulOperator                        // emits S/DGEMV BLAS calls
 	vecDotOperator                           // emits S/DDOT BLAS calls
 	outerProdOperator                        // emits S/DGER BLAS calls
+	batchedMatMulOperator                    // emits one S/DGEMM BLAS call per batch
 
 	maxĀBinaryOperator // delimits all possible linalg operators. Add above this line
 )
@@ -42,133 +43,117 @@ func (op āBinaryOperator) DiffWRT(inputs int) []bool {
 
 // todo: write explanation.
 func matMulDiffExpr(transA, transB bool, x, y, z, gradZ *Node) (retVal Nodes, err error) {
+	return mulDiffExpr(matMulOperator, transA, transB, x, y, gradZ)
+}
+
+func matMulDiff(transA, transB bool, x, y, z *Node) (err error) {
+	return mulDiff(matMulOperator, transA, transB, x, y, z)
+}
+
+// batchedMatMulDiffExpr is the same as matMulDiffExpr, applied to each matrix in the batch
+func batchedMatMulDiffExpr(transA, transB bool, x, y, z, gradZ *Node) (retVal Nodes, err error) {
+	return mulDiffExpr(batchedMatMulOperator, transA, transB, x, y, gradZ)
+}
+
+func batchedMatMulDiff(transA, transB bool, x, y, z *Node) (err error) {
+	return mulDiff(batchedMatMulOperator, transA, transB, x, y, z)
+}
+
+// mulDiffExpr creates the derivatives of z = op(x) × op(y), where op is an optional transpose.
+// o is either matMulOperator or batchedMatMulOperator.
+func mulDiffExpr(o āBinaryOperator, transA, transB bool, x, y, gradZ *Node) (retVal Nodes, err error) {
 	var dzdx, dzdy *Node
 	op := linAlgBinOp{
-		āBinaryOperator: matMulOperator,
+		āBinaryOperator: o,
 	}
 	switch {
 	case transA && transB:
 		op.transA = transA
 		op.transB = transB
-		if dzdx, err = binOpNode(op, y, z); err == nil {
-			dzdy, err = binOpNode(op, z, x)
+		if dzdx, err = binOpNode(op, y, gradZ); err == nil {
+			dzdy, err = binOpNode(op, gradZ, x)
 		}
 	case !transA && transB:
-		if dzdx, err = binOpNode(op, z, y); err == nil {
+		if dzdx, err = binOpNode(op, gradZ, y); err == nil {
 			op.transA = true
-			dzdy, err = binOpNode(op, z, x)
+			dzdy, err = binOpNode(op, gradZ, x)
 		}
 	case transA && !transB:
 		op.transB = true
-		if dzdx, err = binOpNode(op, y, z); err == nil {
+		if dzdx, err = binOpNode(op, y, gradZ); err == nil {
 			op.transB = false
-			dzdy, err = binOpNode(op, x, z)
+			dzdy, err = binOpNode(op, x, gradZ)
 		}
 	case !transA && !transB:
 		op.transB = true
-		if dzdx, err = binOpNode(op, z, y); err == nil {
+		if dzdx, err = binOpNode(op, gradZ, y); err == nil {
 			op.transA = true
 			op.transB = false
-			dzdy, err = binOpNode(op, x, z)
+			dzdy, err = binOpNode(op, x, gradZ)
 		}
 	}
 	retVal = Nodes{dzdx, dzdy}
 	return
 }
 
-func matMulDiff(transA, transB bool, x, y, z *Node) (err error) {
+// mulDiff accumulates the derivatives of z = op(x) × op(y) into x and y.
+// o is either matMulOperator or batchedMatMulOperator.
+func mulDiff(o āBinaryOperator, transA, transB bool, x, y, z *Node) (err error) {
 	xdv := x.boundTo.(*dualValue)
 	ydv := y.boundTo.(*dualValue)
 	zdv := z.boundTo.(*dualValue)
 
-	op := linAlgBinOp{
-		āBinaryOperator: matMulOperator,
-	}
+	dx := linAlgBinOp{āBinaryOperator: o}
+	dy := linAlgBinOp{āBinaryOperator: o}
+	var dxIn, dyIn []Value
 
 	switch {
 	case transA && transB:
-		op.transA = transA
-		op.transB = transB
-
-		// dzdx
-		err = op.IncrDo(xdv.d, ydv.Value, zdv.Value)
-		if ver, ok := err.(Valuer); ok {
-			xdv.SetDeriv(ver.Value()) // ignore errors on purpose
-		} else if err != nil {
-			return
-		}
-
-		// dzdy
-		err = op.IncrDo(ydv.d, zdv.Value, xdv.Value)
-		if ver, ok := err.(Valuer); ok {
-			ydv.SetDeriv(ver.Value()) // ignore errors on purpose
-			return nil
-		}
-		return
-
+		dx.transA, dx.transB = true, true
+		dy.transA, dy.transB = true, true
+		dxIn = []Value{ydv.Value, zdv.d}
+		dyIn = []Value{zdv.d, xdv.Value}
 	case !transA && transB:
-		// dzdx
-		err = op.IncrDo(xdv.d, zdv.Value, ydv.Value)
-		if ver, ok := err.(Valuer); ok {
-			xdv.SetDeriv(ver.Value()) // ignore errors on purpose
-		} else if err != nil {
-			return
-		}
-
-		// dzdy
-		op.transA = true
-		err = op.IncrDo(ydv.d, zdv.Value, xdv.Value)
-		if ver, ok := err.(Valuer); ok {
-			ydv.SetDeriv(ver.Value()) // ignore errors on purpose
-			return nil
-		}
-		return
-
+		dy.transA = true
+		dxIn = []Value{zdv.d, ydv.Value}
+		dyIn = []Value{zdv.d, xdv.Value}
 	case transA && !transB:
-		// dzdx
-		op.transB = true
-		err = op.IncrDo(xdv.d, ydv.Value, zdv.Value)
-		if ver, ok := err.(Valuer); ok {
-			xdv.SetDeriv(ver.Value()) // ignore errors on purpose
-			return nil
-		}
-
-		// dzdy
-		op.transA = false
-		op.transB = false
-		err = op.IncrDo(ydv.d, xdv.Value, zdv.Value)
-		if ver, ok := err.(Valuer); ok {
-			ydv.SetDeriv(ver.Value()) // ignore errors on purpose
-		} else if err != nil {
-			return
-		}
-
-		return
+		dx.transB = true
+		dxIn = []Value{ydv.Value, zdv.d}
+		dyIn = []Value{xdv.Value, zdv.d}
 	case !transA && !transB:
-		op.transB = true
-		err = op.IncrDo(xdv.d, zdv.Value, ydv.Value)
-		if ver, ok := err.(Valuer); ok {
-			xdv.SetDeriv(ver.Value()) // ignore errors on purpose
-		} else if err != nil {
-			return
-		}
+		dx.transB = true
+		dy.transA = true
+		dxIn = []Value{zdv.d, ydv.Value}
+		dyIn = []Value{xdv.Value, zdv.d}
+	}
 
-		op.transA = true
-		op.transB = false
-		err = op.IncrDo(ydv.d, xdv.Value, zdv.Value)
-		if ver, ok := err.(Valuer); ok {
-			ydv.SetDeriv(ver.Value()) // ignore errors on purpose
-			return nil
-		}
+	// dzdx
+	err = dx.IncrDo(xdv.d, dxIn...)
+	if ver, ok := err.(Valuer); ok {
+		xdv.SetDeriv(ver.Value()) // ignore errors on purpose
+	} else if err != nil {
 		return
 	}
 
-	panic("unreachable")
+	// dzdy
+	err = dy.IncrDo(ydv.d, dyIn...)
+	if ver, ok := err.(Valuer); ok {
+		ydv.SetDeriv(ver.Value()) // ignore errors on purpose
+		return nil
+	}
+	return
 }
 
 func matVecMulDiffExpr(transA, transB bool, x, y, z, gradZ *Node) (retVal Nodes, err error) {
 	var dzdx, dzdy *Node
-	if dzdx, err = OuterProd(gradZ, y); err == nil {
+	if transA {
+		// z = xᵀy, so dz/dx = y ⊗ gradZ
+		dzdx, err = OuterProd(y, gradZ)
+	} else {
+		dzdx, err = OuterProd(gradZ, y)
+	}
+	if err == nil {
 		op := linAlgBinOp{
 			āBinaryOperator: matVecMulOperator,
 			transA:          !transA,
@@ -190,7 +175,12 @@ func matVecMulDiff(transA, transB bool, x, y, z *Node) (err error) {
 		āBinaryOperator: outerProdOperator,
 	}
 
-	err = op.IncrDo(xdv.d, zdv.d, ydv.Value)
+	if transA {
+		// z = xᵀy, so dz/dx = y ⊗ dz
+		err = op.IncrDo(xdv.d, ydv.Value, zdv.d)
+	} else {
+		err = op.IncrDo(xdv.d, zdv.d, ydv.Value)
+	}
 	if ver, ok := err.(Valuer); ok {
 		xdv.SetDeriv(ver.Value()) // ignore errors on purpose
 	} else if err != nil {
@@ -241,10 +231,17 @@ func vecDotDiff(transA, transB bool, x, y, z *Node) (err error) {
 	return
 }
 
+// z = x ⊗ y:
+//		dz/dx = gradZ × y
+//		dz/dy = gradZᵀ × x
 func outerProdDiffExpr(transA, transB bool, x, y, z, gradZ *Node) (retVal Nodes, err error) {
 	var dzdx, dzdy *Node
-	if dzdx, err = Mul(x, gradZ); err == nil {
-		if dzdy, err = Mul(y, gradZ); err == nil {
+	op := linAlgBinOp{
+		āBinaryOperator: matVecMulOperator,
+	}
+	if dzdx, err = binOpNode(op, gradZ, y); err == nil {
+		op.transA = true
+		if dzdy, err = binOpNode(op, gradZ, x); err == nil {
 			retVal = Nodes{dzdx, dzdy}
 		}
 	}
@@ -256,17 +253,22 @@ func outerProdDiff(transA, transB bool, x, y, z *Node) (err error) {
 	ydv := y.boundTo.(*dualValue)
 	zdv := z.boundTo.(*dualValue)
 
-	mul := newElemBinOp(mulOpType, x, z)
-	err = mul.IncrDo(xdv.d, xdv.Value, zdv.d)
+	op := linAlgBinOp{
+		āBinaryOperator: matVecMulOperator,
+	}
+
+	err = op.IncrDo(xdv.d, zdv.d, ydv.Value)
 	if ver, ok := err.(Valuer); ok {
 		xdv.SetDeriv(ver.Value()) // ignore errors on purpose
 	} else if err != nil {
 		return
 	}
-	err = mul.IncrDo(ydv.d, ydv.Value, zdv.d)
+
+	op.transA = true
+	err = op.IncrDo(ydv.d, zdv.d, xdv.Value)
 	if ver, ok := err.(Valuer); ok {
 		ydv.SetDeriv(ver.Value()) // ignore errors on purpose
-		return
+		return nil
 	}
 	return
 }
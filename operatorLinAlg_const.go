@@ -7,7 +7,7 @@ var āBinOpStrs = [maxĀBinaryOperator]string{
 	"×",
 	"⋅",
 	"⊗",
-	// "×××",
+	"×××",
 }
 
 var āBinOpDiffExprs = [maxĀBinaryOperator]func(tA, tB bool, x, y, z, grad *Node) (Nodes, error){
@@ -15,6 +15,7 @@ var āBinOpDiffExprs = [maxĀBinaryOperator]func(tA, tB bool, x, y, z, grad *Nod
 	matVecMulDiffExpr,
 	vecDotDiffExpr,
 	outerProdDiffExpr,
+	batchedMatMulDiffExpr,
 }
 
 var āBinOpDiffs = [maxĀBinaryOperator]func(tA, tB bool, x, y, z *Node) error{
@@ -22,6 +23,7 @@ var āBinOpDiffs = [maxĀBinaryOperator]func(tA, tB bool, x, y, z *Node) error{
 	matVecMulDiff,
 	vecDotDiff,
 	outerProdDiff,
+	batchedMatMulDiff,
 }
 
 var āBinOpTypes = [maxĀBinaryOperator]func() Type{
@@ -29,6 +31,7 @@ var āBinOpTypes = [maxĀBinaryOperator]func() Type{
 	matVecMulType,
 	vecDotType,
 	outerProdType,
+	batchedMatMulType,
 }
 
 /* TYPES FOR LINALG BINARY OP*/
@@ -78,3 +81,14 @@ func outerProdType() Type {
 
 	return newFunctionType(v, v, m)
 }
+
+// batchedMatMulOp is a function with this type:
+//		batchedMatMulOp :: (Float a) ⇒ Tensor 3 a → Tensor 3 a → Tensor 3 a
+//
+// For the moment only floats are allowed
+func batchedMatMulType() Type {
+	a := newTypeVariable("a", withTVConstraints(floats))
+	t := newTensorType(3, a)
+
+	return newFunctionType(t, t, t)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/chewxy/gorgonia/tensor/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,9 +53,10 @@ func matMulDiffTest(op linAlgBinOp, t *testing.T) (X, Y, Z, A, B, C *Node, err e
 		return
 	}
 
-	// symdiff
+	// symdiff - the gradient of Z is all ones, just like the derivative of C in variableDV
 	Z.op = op
-	ns, err := op.SymDiff(Nodes{X, Y}, Z, onef64)
+	gradZ := NewMatrix(g, Float64, WithShape(2, 2), WithName("gradZ"), WithValue(tf64.Ones(2, 2)))
+	ns, err := op.SymDiff(Nodes{X, Y}, Z, gradZ)
 	if err != nil {
 		return
 	}
@@ -67,8 +69,8 @@ func matMulDiffTest(op linAlgBinOp, t *testing.T) (X, Y, Z, A, B, C *Node, err e
 	Y.deriv = dZdY
 
 	// run the whole graph
-	sg := g.SubgraphRoots(dZdX, dZdY)
-	prog, locMap, err := CompileFunctionNEW(sg, Nodes{X, Y}, ns)
+	sg := g.SubgraphRoots(dZdX, dZdY, Z)
+	prog, locMap, err := CompileFunctionNEW(sg, Nodes{X, Y}, Nodes{dZdX, dZdY, Z})
 	if err != nil {
 		return
 	}
@@ -188,3 +190,118 @@ func TestMatMulDiffImplEquality(t *testing.T) {
 	assert.Equal(xG, aG)
 	assert.Equal(yG, bG)
 }
+
+// naiveBatchedMatMulCost calculates Σ W ⊙ (op(a) × op(b)) for a batch of matrices, where op is an optional transpose.
+func naiveBatchedMatMulCost(a, b, w []float64, batch, m, k, n int, transA, transB bool) float64 {
+	var cost float64
+	for i := 0; i < batch; i++ {
+		for r := 0; r < m; r++ {
+			for c := 0; c < n; c++ {
+				var v float64
+				for j := 0; j < k; j++ {
+					ai := i*m*k + r*k + j
+					if transA {
+						ai = i*m*k + j*m + r
+					}
+					bi := i*k*n + j*n + c
+					if transB {
+						bi = i*k*n + c*k + j
+					}
+					v += a[ai] * b[bi]
+				}
+				cost += w[i*m*n+r*n+c] * v
+			}
+		}
+	}
+	return cost
+}
+
+func TestBatchedMatMul(t *testing.T) {
+	assert := assert.New(t)
+	batch, m, k, n := 2, 2, 3, 4
+	aBack := tf64.RangeFloat64(0, batch*m*k)
+	bBack := tf64.RangeFloat64(0, batch*k*n)
+	wBack := make([]float64, batch*m*n)
+	for i := range wBack {
+		wBack[i] = float64(i%3) - 1
+	}
+
+	for _, trans := range [][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+		transA, transB := trans[0], trans[1]
+		aShape := []int{batch, m, k}
+		if transA {
+			aShape = []int{batch, k, m}
+		}
+		bShape := []int{batch, k, n}
+		if transB {
+			bShape = []int{batch, n, k}
+		}
+
+		// the cost is linear in a and b, so the gradients can be found exactly by perturbing one element at a time
+		cost := naiveBatchedMatMulCost(aBack, bBack, wBack, batch, m, k, n, transA, transB)
+		aGrad := make([]float64, len(aBack))
+		for i := range aBack {
+			perturbed := append([]float64(nil), aBack...)
+			perturbed[i]++
+			aGrad[i] = naiveBatchedMatMulCost(perturbed, bBack, wBack, batch, m, k, n, transA, transB) - cost
+		}
+		bGrad := make([]float64, len(bBack))
+		for i := range bBack {
+			perturbed := append([]float64(nil), bBack...)
+			perturbed[i]++
+			bGrad[i] = naiveBatchedMatMulCost(aBack, perturbed, wBack, batch, m, k, n, transA, transB) - cost
+		}
+
+		build := func() (g *ExprGraph, A, B, C, cost *Node) {
+			g = NewGraph()
+			A = NewTensor(g, Float64, 3, WithName("A"), WithShape(aShape...), WithValue(tf64.NewTensor(tf64.WithShape(aShape...), tf64.WithBacking(append([]float64(nil), aBack...)))))
+			B = NewTensor(g, Float64, 3, WithName("B"), WithShape(bShape...), WithValue(tf64.NewTensor(tf64.WithShape(bShape...), tf64.WithBacking(append([]float64(nil), bBack...)))))
+			W := NewVector(g, Float64, WithName("W"), WithShape(len(wBack)), WithValue(tf64.NewTensor(tf64.WithShape(len(wBack)), tf64.WithBacking(wBack))))
+			C = Must(BatchedMatMul(A, B, transA, transB))
+			cost = Must(Sum(Must(HadamardProd(Must(Reshape(C, len(wBack))), W))))
+			return
+		}
+
+		g, A, B, C, c := build()
+		assert.Equal(types.Shape{batch, m, n}, C.Shape())
+		if _, err := Grad(c, A, B); err != nil {
+			t.Fatal(err)
+		}
+
+		prog, locMap, err := Compile(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		machine := NewTapeMachine(prog, locMap)
+		if err = machine.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.InDelta(cost, c.Value().(Scalar).v, 1e-10)
+		aG, _ := A.Grad()
+		bG, _ := B.Grad()
+		assert.Equal(aGrad, aG.(Tensor).Tensor.Data())
+		assert.Equal(bGrad, bG.(Tensor).Tensor.Data())
+
+		// lisp machine
+		g2, A2, B2, _, _ := build()
+		m2 := NewLispMachine(g2)
+		if err = m2.RunAll(); err != nil {
+			t.Fatal(err)
+		}
+
+		a2G, _ := A2.Grad()
+		b2G, _ := B2.Grad()
+		assert.Equal(aGrad, a2G.(Tensor).Tensor.Data())
+		assert.Equal(bGrad, b2G.(Tensor).Tensor.Data())
+	}
+
+	g := NewGraph()
+	A := NewTensor(g, Float64, 3, WithName("A"), WithShape(2, 2, 3))
+	B := NewTensor(g, Float64, 3, WithName("B"), WithShape(3, 3, 2))
+	_, err := BatchedMatMul(A, B)
+	assert.NotNil(err)
+	_, err = BatchedMatMul(A, A)
+	assert.NotNil(err)
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/chewxy/math32"
 	"github.com/pkg/errors"
 )
 
@@ -65,6 +66,8 @@ func (o scalarBinOp) Do(same bool, vals ...Value) (retVal Value, err error) {
 			r = af / bf
 		case powOpType:
 			r = math.Pow(af, bf)
+		case atan2OpType:
+			r = math.Atan2(af, bf)
 		case ltOpType:
 			r = af < bf
 		case gtOpType:
@@ -102,6 +105,8 @@ func (o scalarBinOp) Do(same bool, vals ...Value) (retVal Value, err error) {
 			r = af / bf
 		case powOpType:
 			r = float32(math.Pow(float64(af), float64(bf)))
+		case atan2OpType:
+			r = math32.Atan2(af, bf)
 		case ltOpType:
 			r = af < bf
 		case gtOpType:
@@ -146,7 +151,7 @@ func (o tBinOp) isArith() bool                  { return o.ʘBinaryOperatorType.
 
 func (o tBinOp) Do(same bool, inputs ...Value) (Value, error) {
 	if same {
-		o.do(inputs, types.AsSameType())
+		return o.do(inputs, types.AsSameType())
 	}
 	return o.do(inputs)
 }
@@ -516,13 +521,194 @@ func hadamardDivDiff(x, y, z *Node) (err error) {
 	return nil
 }
 
-// TODO: go back in time, pay more attention to calculus class in high school and learn how to differentiate x^y
-func hadamardPowDiffExpr(x, y, z, grad *Node) (retVal Nodes, err error) {
-	return nil, NewError(NotYetImplemented, "hadamardPowDiffExpr not yet implemented")
+// z = x^y:
+//		dz/dx = y·z/x
+//		dz/dy = z·ln(x)
+func hadamardPowDiffExpr(x, y, z, gradZ *Node) (retVal Nodes, err error) {
+	var dzdx, dzdy *Node
+	if dzdx, err = HadamardDiv(z, x); err == nil {
+		WithGroupName(gradClust)(dzdx)
+		if dzdx, err = HadamardProd(dzdx, y); err == nil {
+			WithGroupName(gradClust)(dzdx)
+			if dzdx, err = HadamardProd(dzdx, gradZ); err == nil {
+				WithGroupName(gradClust)(dzdx)
+				if dzdy, err = Log(x); err == nil {
+					WithGroupName(gradClust)(dzdy)
+					if dzdy, err = HadamardProd(z, dzdy); err == nil {
+						WithGroupName(gradClust)(dzdy)
+						if dzdy, err = HadamardProd(dzdy, gradZ); err == nil {
+							WithGroupName(gradClust)(dzdy)
+							retVal = Nodes{dzdx, dzdy}
+						}
+					}
+				}
+			}
+		}
+	}
+	return
 }
 
 func hadamardPowDiff(x, y, z *Node) (err error) {
-	return NewError(NotYetImplemented, "hadamardPowDiff not yet implemented")
+	xdv := x.boundTo.(*dualValue)
+	ydv := y.boundTo.(*dualValue)
+	zdv := z.boundTo.(*dualValue)
+
+	// dz/dx = y·z/x · dz
+	var d Value
+	div := newEBOByType(divOpType, zdv.Value.Type(), xdv.Value.Type())
+	if d, err = div.Do(zdv.Value, xdv.Value); err != nil {
+		err = errors.Wrapf(err, doFail, div)
+		return
+	}
+
+	mul := newEBOByType(mulOpType, d.Type(), ydv.Value.Type())
+	if d, err = mul.Do(d, ydv.Value); err != nil {
+		err = errors.Wrapf(err, doFail, mul)
+		return
+	}
+
+	mul = newEBOByType(mulOpType, d.Type(), zdv.d.Type())
+	if err = mul.IncrDo(xdv.d, d, zdv.d); err != nil {
+		var ver Valuer
+		var ok bool
+		if ver, ok = err.(Valuer); !ok {
+			return
+		}
+
+		xdv.SetDeriv(ver.Value()) // ignore errors on purpose
+	}
+
+	// dz/dy = z·ln(x) · dz
+	ln := newElemUnaryOp(lnOpType, x)
+	if d, err = ln.Do(xdv.Value); err != nil {
+		err = errors.Wrapf(err, doFail, ln)
+		return
+	}
+
+	mul = newEBOByType(mulOpType, zdv.Value.Type(), d.Type())
+	if d, err = mul.Do(zdv.Value, d); err != nil {
+		err = errors.Wrapf(err, doFail, mul)
+		return
+	}
+
+	mul = newEBOByType(mulOpType, d.Type(), zdv.d.Type())
+	if err = mul.IncrDo(ydv.d, d, zdv.d); err != nil {
+		var ver Valuer
+		var ok bool
+		if ver, ok = err.(Valuer); !ok {
+			return
+		}
+
+		ydv.SetDeriv(ver.Value()) // ignore errors on purpose
+	}
+
+	return nil
+}
+
+// z = atan2(x, y):
+//		dz/dx = y/(x²+y²)
+//		dz/dy = -x/(x²+y²)
+func atan2DiffExpr(x, y, z, gradZ *Node) (retVal Nodes, err error) {
+	var xx, yy, denom, dzdx, dzdy *Node
+	if xx, err = Square(x); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(xx)
+	if yy, err = Square(y); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(yy)
+	if denom, err = Add(xx, yy); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(denom)
+
+	if dzdx, err = HadamardDiv(y, denom); err == nil {
+		WithGroupName(gradClust)(dzdx)
+		if dzdx, err = HadamardProd(dzdx, gradZ); err == nil {
+			WithGroupName(gradClust)(dzdx)
+			if dzdy, err = HadamardDiv(x, denom); err == nil {
+				WithGroupName(gradClust)(dzdy)
+				if dzdy, err = Neg(dzdy); err == nil {
+					WithGroupName(gradClust)(dzdy)
+					if dzdy, err = HadamardProd(dzdy, gradZ); err == nil {
+						WithGroupName(gradClust)(dzdy)
+						retVal = Nodes{dzdx, dzdy}
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+func atan2Diff(x, y, z *Node) (err error) {
+	xdv := x.boundTo.(*dualValue)
+	ydv := y.boundTo.(*dualValue)
+	zdv := z.boundTo.(*dualValue)
+
+	// denom = x²+y²
+	var xx, yy, denom Value
+	sq := newElemUnaryOp(squareOpType, x)
+	if xx, err = sq.Do(xdv.Value); err != nil {
+		err = errors.Wrapf(err, doFail, sq)
+		return
+	}
+	sq = newElemUnaryOp(squareOpType, y)
+	if yy, err = sq.Do(ydv.Value); err != nil {
+		err = errors.Wrapf(err, doFail, sq)
+		return
+	}
+	add := newEBOByType(addOpType, xx.Type(), yy.Type())
+	if denom, err = add.Do(xx, yy); err != nil {
+		err = errors.Wrapf(err, doFail, add)
+		return
+	}
+
+	// dz/dx = y/denom · dz
+	var d Value
+	div := newEBOByType(divOpType, ydv.Value.Type(), denom.Type())
+	if d, err = div.Do(ydv.Value, denom); err != nil {
+		err = errors.Wrapf(err, doFail, div)
+		return
+	}
+
+	mul := newEBOByType(mulOpType, d.Type(), zdv.d.Type())
+	if err = mul.IncrDo(xdv.d, d, zdv.d); err != nil {
+		var ver Valuer
+		var ok bool
+		if ver, ok = err.(Valuer); !ok {
+			return
+		}
+
+		xdv.SetDeriv(ver.Value()) // ignore errors on purpose
+	}
+
+	// dz/dy = -x/denom · dz
+	div = newEBOByType(divOpType, xdv.Value.Type(), denom.Type())
+	if d, err = div.Do(xdv.Value, denom); err != nil {
+		err = errors.Wrapf(err, doFail, div)
+		return
+	}
+
+	neg := newElemUnaryOp(negOpType, x)
+	if d, err = neg.Do(d); err != nil {
+		err = errors.Wrapf(err, doFail, neg)
+		return
+	}
+
+	mul = newEBOByType(mulOpType, d.Type(), zdv.d.Type())
+	if err = mul.IncrDo(ydv.d, d, zdv.d); err != nil {
+		var ver Valuer
+		var ok bool
+		if ver, ok = err.(Valuer); !ok {
+			return
+		}
+
+		ydv.SetDeriv(ver.Value()) // ignore errors on purpose
+	}
+
+	return nil
 }
 
 func nondiffBinOpExpr(x, y, z, grad *Node) (retVal Nodes, err error) {
@@ -16,6 +16,8 @@ var (
 	tdivf64 = tf64BinOp(tf64.PointwiseDiv)
 	tpowf64 = tf64BinOp(tf64.PointwisePow)
 
+	tatan2f64 = tf64BinOp(tf64.Atan2)
+
 	// cmp
 	tltf64  = tf64CmpOp(tf64.Lt)
 	tgtf64  = tf64CmpOp(tf64.Gt)
@@ -32,6 +34,8 @@ var (
 	tdivf32 = tf32BinOp(tf32.PointwiseDiv)
 	tpowf32 = tf32BinOp(tf32.PointwisePow)
 
+	tatan2f32 = tf32BinOp(tf32.Atan2)
+
 	// cmp
 	tltf32  = tf32CmpOp(tf32.Lt)
 	tgtf32  = tf32CmpOp(tf32.Gt)
@@ -55,6 +59,7 @@ const (
 	mulOpType
 	divOpType
 	powOpType
+	atan2OpType
 
 	// cmp
 	ltOpType
@@ -80,6 +85,7 @@ var ʘBinOpStrs = [maxʘBinaryOpType]string{
 	"⊙",
 	"÷",
 	"^",
+	"atan2",
 
 	// cmp ops
 	"<",
@@ -93,17 +99,17 @@ var ʘBinOpStrs = [maxʘBinaryOpType]string{
 // ʘBinOpCommutative is the array that stores whether a binary operator is commutative
 // It should be held constant.
 var ʘBinOpCommutative = [maxʘBinaryOpType]bool{
-	true, false, true, false, false,
+	true, false, true, false, false, false,
 	false, false, false, false, true, true,
 }
 
 var ʘBinOpDiffExprs = [maxʘBinaryOpType]func(x, y, z, gradZ *Node) (Nodes, error){
-	addDiffExpr, subDiffExpr, hadamardProdDiffExpr, hadamardDivDiffExpr, hadamardPowDiffExpr,
+	addDiffExpr, subDiffExpr, hadamardProdDiffExpr, hadamardDivDiffExpr, hadamardPowDiffExpr, atan2DiffExpr,
 	nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr, nondiffBinOpExpr,
 }
 
 var ʘBinOpDiffFns = [maxʘBinaryOpType]func(x, y, z *Node) error{
-	addDiff, subDiff, hadamardProdDiff, hadamardDivDiff, hadamardPowDiff,
+	addDiff, subDiff, hadamardProdDiff, hadamardDivDiff, hadamardPowDiff, atan2Diff,
 	nondiffBinOp, nondiffBinOp, nondiffBinOp, nondiffBinOp, nondiffBinOp, nondiffBinOp,
 }
 
@@ -135,7 +141,7 @@ func (b ʘBinaryOperatorType) diffWRT(inputs int) []bool {
 // isArith indicates if the binary operator is an arithmetic type
 func (b ʘBinaryOperatorType) isArith() bool {
 	switch b {
-	case addOpType, subOpType, mulOpType, divOpType, powOpType:
+	case addOpType, subOpType, mulOpType, divOpType, powOpType, atan2OpType:
 		return true
 	default:
 		return false
@@ -149,6 +155,7 @@ var tf64BinOps = [maxʘBinaryOpType]*tf64BinOp{
 	&tmulf64,
 	&tdivf64,
 	&tpowf64,
+	&tatan2f64,
 	nil, // lt
 	nil, // gt
 	nil, // lte
@@ -163,6 +170,7 @@ var tf64CmpOps = [maxʘBinaryOpType]*tf64CmpOp{
 	nil, // mul
 	nil, // div
 	nil, // pow
+	nil, // atan2
 	&tltf64,
 	&tgtf64,
 	&tltef64,
@@ -177,6 +185,7 @@ var tf32BinOps = [maxʘBinaryOpType]*tf32BinOp{
 	&tmulf32,
 	&tdivf32,
 	&tpowf32,
+	&tatan2f32,
 	nil, // lt
 	nil, // gt
 	nil, // lte
@@ -191,6 +200,7 @@ var tf32CmpOps = [maxʘBinaryOpType]*tf32CmpOp{
 	nil, // mul
 	nil, // div
 	nil, // pow
+	nil, // atan2
 	&tltf32,
 	&tgtf32,
 	&tltef32,
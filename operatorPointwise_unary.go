@@ -1,6 +1,13 @@
 package gorgonia
 
-import "github.com/pkg/errors"
+import (
+	"math"
+
+	tf32 "github.com/chewxy/gorgonia/tensor/f32"
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/pkg/errors"
+)
 
 // a ʘUnaryOperator is essentially a function that takes a float32 or float64 and returns the same
 // pros : no overloading = clear understanding
@@ -62,6 +69,48 @@ func (f *sf32UnaryOperator) unaryOpType() ʘUnaryOperatorType {
 		return expm1OpType
 	case &softplusf32:
 		return softplusOpType
+	case &leakyReluf32:
+		return leakyReluOpType
+	case &eluf32:
+		return eluOpType
+	case &seluf32:
+		return seluOpType
+	case &geluf32:
+		return geluOpType
+	case &swishf32:
+		return swishOpType
+	case &mishf32:
+		return mishOpType
+	case &hardSigmoidf32:
+		return hardSigmoidOpType
+	case &normCDFf32:
+		return normCDFOpType
+	case &tanf32:
+		return tanOpType
+	case &asinf32:
+		return asinOpType
+	case &acosf32:
+		return acosOpType
+	case &atanf32:
+		return atanOpType
+	case &sinhf32:
+		return sinhOpType
+	case &coshf32:
+		return coshOpType
+	case &atanhf32:
+		return atanhOpType
+	case &log10f32:
+		return log10OpType
+	case &erff32:
+		return erfOpType
+	case &erfcf32:
+		return erfcOpType
+	case &lgammaf32:
+		return lgammaOpType
+	case &digammaf32:
+		return digammaOpType
+	case &trigammaf32:
+		return trigammaOpType
 	}
 	return maxʘUnaryOperator
 }
@@ -110,6 +159,48 @@ func (f *sf64UnaryOperator) unaryOpType() ʘUnaryOperatorType {
 		return expm1OpType
 	case &softplusf64:
 		return softplusOpType
+	case &leakyReluf64:
+		return leakyReluOpType
+	case &eluf64:
+		return eluOpType
+	case &seluf64:
+		return seluOpType
+	case &geluf64:
+		return geluOpType
+	case &swishf64:
+		return swishOpType
+	case &mishf64:
+		return mishOpType
+	case &hardSigmoidf64:
+		return hardSigmoidOpType
+	case &normCDFf64:
+		return normCDFOpType
+	case &tanf64:
+		return tanOpType
+	case &asinf64:
+		return asinOpType
+	case &acosf64:
+		return acosOpType
+	case &atanf64:
+		return atanOpType
+	case &sinhf64:
+		return sinhOpType
+	case &coshf64:
+		return coshOpType
+	case &atanhf64:
+		return atanhOpType
+	case &log10f64:
+		return log10OpType
+	case &erff64:
+		return erfOpType
+	case &erfcf64:
+		return erfcOpType
+	case &lgammaf64:
+		return lgammaOpType
+	case &digammaf64:
+		return digammaOpType
+	case &trigammaf64:
+		return trigammaOpType
 	}
 
 	return maxʘUnaryOperator
@@ -882,3 +973,525 @@ func softplusDiff(x, y *Node) (err error) {
 	}
 	return
 }
+
+/* ACTIVATION FUNCTIONS */
+
+// unaryOpAlpha returns the alpha of the parameterized operator that computed y.
+// If y was not computed by an operator of type u, the default alpha of u is returned.
+func unaryOpAlpha(u ʘUnaryOperatorType, y *Node) float64 {
+	if op, ok := y.op.(elemUnaryOp); ok && op.unaryOpType() == u {
+		return op.alpha
+	}
+	return u.defaultAlpha()
+}
+
+// positive returns a Bool mask of x > 0
+func positive(x *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var zero *Node
+	if zero, err = floatConstant(dt, 0); err != nil {
+		return
+	}
+
+	if retVal, err = Gt(x, zero, false); err == nil {
+		WithGroupName(gradClust)(retVal)
+	}
+	return
+}
+
+// pointwiseDiff accumulates f'(x)·gradY into the derivative of x. df is f' in float64 - Float32 values are converted.
+func pointwiseDiff(x, y *Node, df func(float64) float64) (err error) {
+	xdv := x.boundTo.(*dualValue)
+	ydv := y.boundTo.(*dualValue)
+
+	var d Value
+	switch v := xdv.Value.(type) {
+	case Scalar:
+		switch v.t {
+		case Float64:
+			d = NewScalarValue(df(v.v.(float64)))
+		case Float32:
+			d = NewScalarValue(float32(df(float64(v.v.(float32)))))
+		default:
+			return nyi("pointwiseDiff", v.t)
+		}
+	case Tensor:
+		var t types.Tensor
+		switch vt := v.Tensor.(type) {
+		case *tf64.Tensor:
+			t, err = vt.Apply(df)
+		case *tf32.Tensor:
+			t, err = vt.Apply(func(a float32) float32 { return float32(df(float64(a))) })
+		default:
+			return nyi("pointwiseDiff", v.Tensor)
+		}
+		if err != nil {
+			return errors.Wrapf(err, autodiffFail, y)
+		}
+
+		dT := FromTensor(t)
+		defer returnTensor(dT)
+		d = dT
+	default:
+		return nyi("pointwiseDiff", xdv.Value)
+	}
+
+	mul := newElemBinOp(mulOpType, x, y)
+	err = mul.IncrDo(xdv.d, d, ydv.d)
+	if ver, ok := err.(Valuer); ok {
+		xdv.SetDeriv(ver.Value()) // ignore errors on purpose
+		return nil
+	}
+	return
+}
+
+// gradY if x > 0, alpha·gradY otherwise
+func leakyReluDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var alpha, pos *Node
+	if alpha, err = floatConstant(dt, unaryOpAlpha(leakyReluOpType, y)); err != nil {
+		return
+	}
+	if pos, err = positive(x); err != nil {
+		return
+	}
+	if retVal, err = HadamardProd(gradY, alpha); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = Where(pos, gradY, retVal)
+	}
+	return
+}
+
+func leakyReluDiff(x, y *Node) (err error) {
+	alpha := unaryOpAlpha(leakyReluOpType, y)
+	return pointwiseDiff(x, y, func(a float64) float64 { return _leakyReluDiff(a, alpha) })
+}
+
+// for x ≤ 0, y = α(exp(x)-1), so the derivative α·exp(x) is y+α
+func eluDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var alpha, pos *Node
+	if alpha, err = floatConstant(dt, unaryOpAlpha(eluOpType, y)); err != nil {
+		return
+	}
+	if pos, err = positive(x); err != nil {
+		return
+	}
+	if retVal, err = Add(y, alpha); err == nil {
+		WithGroupName(gradClust)(retVal)
+		if retVal, err = HadamardProd(retVal, gradY); err == nil {
+			WithGroupName(gradClust)(retVal)
+			retVal, err = Where(pos, gradY, retVal)
+		}
+	}
+	return
+}
+
+func eluDiff(x, y *Node) (err error) {
+	alpha := unaryOpAlpha(eluOpType, y)
+	return pointwiseDiff(x, y, func(a float64) float64 { return _eluDiff(a, alpha) })
+}
+
+// λ·gradY if x > 0, (y+λα)·gradY otherwise
+func seluDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var scale, scaledAlpha, pos, neg *Node
+	if scale, err = floatConstant(dt, seluScale); err != nil {
+		return
+	}
+	if scaledAlpha, err = floatConstant(dt, seluScale*seluAlpha); err != nil {
+		return
+	}
+	if pos, err = positive(x); err != nil {
+		return
+	}
+	if neg, err = Add(y, scaledAlpha); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(neg)
+	if neg, err = HadamardProd(neg, gradY); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(neg)
+	if retVal, err = HadamardProd(gradY, scale); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = Where(pos, retVal, neg)
+	}
+	return
+}
+
+func seluDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _seluDiff) }
+
+// normPDF is φ(x) = exp(-x²/2)/√(2π)
+func normPDF(x *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var negHalf, norm *Node
+	if negHalf, err = floatConstant(dt, -0.5); err != nil {
+		return
+	}
+	if norm, err = floatConstant(dt, 1/math.Sqrt(2*math.Pi)); err != nil {
+		return
+	}
+
+	if retVal, err = Square(x); err == nil {
+		WithGroupName(gradClust)(retVal)
+		if retVal, err = HadamardProd(retVal, negHalf); err == nil {
+			WithGroupName(gradClust)(retVal)
+			if retVal, err = Exp(retVal); err == nil {
+				WithGroupName(gradClust)(retVal)
+				if retVal, err = HadamardProd(retVal, norm); err == nil {
+					WithGroupName(gradClust)(retVal)
+				}
+			}
+		}
+	}
+	return
+}
+
+// Φ(x) + x·φ(x)
+func geluDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var cdf, pdf *Node
+	if cdf, err = unaryOpNode(newElemUnaryOp(normCDFOpType, x), x); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(cdf)
+	if pdf, err = normPDF(x); err != nil {
+		return
+	}
+
+	if retVal, err = HadamardProd(x, pdf); err == nil {
+		WithGroupName(gradClust)(retVal)
+		if retVal, err = Add(cdf, retVal); err == nil {
+			WithGroupName(gradClust)(retVal)
+			retVal, err = HadamardProd(retVal, gradY)
+		}
+	}
+	return
+}
+
+func geluDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _geluDiff) }
+
+// σ(x) + x·σ(x)(1-σ(x)), which is y + σ(x)(1-y)
+func swishDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var one, sig *Node
+	if one, err = floatConstant(dt, 1); err != nil {
+		return
+	}
+	if sig, err = Sigmoid(x); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(sig)
+
+	if retVal, err = Sub(one, y); err == nil {
+		WithGroupName(gradClust)(retVal)
+		if retVal, err = HadamardProd(sig, retVal); err == nil {
+			WithGroupName(gradClust)(retVal)
+			if retVal, err = Add(y, retVal); err == nil {
+				WithGroupName(gradClust)(retVal)
+				retVal, err = HadamardProd(retVal, gradY)
+			}
+		}
+	}
+	return
+}
+
+func swishDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _swishDiff) }
+
+// tanh(softplus(x)) + x·σ(x)·(1 - tanh²(softplus(x)))
+func mishDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var one, t, sig, sech2 *Node
+	if one, err = floatConstant(dt, 1); err != nil {
+		return
+	}
+	if t, err = Softplus(x); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(t)
+	if t, err = Tanh(t); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(t)
+	if sig, err = Sigmoid(x); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(sig)
+	if sech2, err = Square(t); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(sech2)
+	if sech2, err = Sub(one, sech2); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(sech2)
+
+	if retVal, err = HadamardProd(x, sig); err == nil {
+		WithGroupName(gradClust)(retVal)
+		if retVal, err = HadamardProd(retVal, sech2); err == nil {
+			WithGroupName(gradClust)(retVal)
+			if retVal, err = Add(t, retVal); err == nil {
+				WithGroupName(gradClust)(retVal)
+				retVal, err = HadamardProd(retVal, gradY)
+			}
+		}
+	}
+	return
+}
+
+func mishDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _mishDiff) }
+
+// gradY/6 if -3 < x < 3, 0 otherwise
+func hardSigmoidDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		return
+	}
+
+	var zero, three, sixth, inside *Node
+	if zero, err = floatConstant(dt, 0); err != nil {
+		return
+	}
+	if three, err = floatConstant(dt, 3); err != nil {
+		return
+	}
+	if sixth, err = floatConstant(dt, 1.0/6.0); err != nil {
+		return
+	}
+	if inside, err = Abs(x); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(inside)
+	if inside, err = Lt(inside, three, false); err != nil {
+		return
+	}
+	WithGroupName(gradClust)(inside)
+
+	if retVal, err = HadamardProd(gradY, sixth); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = Where(inside, retVal, zero)
+	}
+	return
+}
+
+func hardSigmoidDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _hardSigmoidDiff) }
+
+func normCDFDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = normPDF(x); err == nil {
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func normCDFDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _normPDFf64) }
+
+/* TRIGONOMETRIC, HYPERBOLIC AND SPECIAL FUNCTIONS */
+
+// constantLike creates a scalar constant with the same Dtype as x
+func constantLike(x *Node, v float64) (retVal *Node, err error) {
+	var dt Dtype
+	if dt, err = dtypeOf(x.t); err != nil {
+		err = errors.Wrapf(err, dtypeExtractionFail, x.t)
+		return
+	}
+	return floatConstant(dt, v)
+}
+
+// onePlusSquare returns 1+x², or 1-x² if sign is negative
+func onePlusSquare(x *Node, sign float64) (retVal *Node, err error) {
+	var one *Node
+	if one, err = constantLike(x, 1); err != nil {
+		return
+	}
+
+	if retVal, err = Square(x); err == nil {
+		WithGroupName(gradClust)(retVal)
+		if sign < 0 {
+			retVal, err = Sub(one, retVal)
+		} else {
+			retVal, err = Add(one, retVal)
+		}
+		if err == nil {
+			WithGroupName(gradClust)(retVal)
+		}
+	}
+	return
+}
+
+// 1 + tan²(x)
+func tanDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = onePlusSquare(y, 1); err == nil {
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func tanDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _tanDiff) }
+
+// 1/√(1-x²)
+func asinDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = onePlusSquare(x, -1); err == nil {
+		if retVal, err = Sqrt(retVal); err == nil {
+			WithGroupName(gradClust)(retVal)
+			retVal, err = HadamardDiv(gradY, retVal)
+		}
+	}
+	return
+}
+
+func asinDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _asinDiff) }
+
+// -1/√(1-x²)
+func acosDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = asinDiffExpr(x, y, gradY); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = Neg(retVal)
+	}
+	return
+}
+
+func acosDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _acosDiff) }
+
+// 1/(1+x²)
+func atanDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = onePlusSquare(x, 1); err == nil {
+		retVal, err = HadamardDiv(gradY, retVal)
+	}
+	return
+}
+
+func atanDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _atanDiff) }
+
+func sinhDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = Cosh(x); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func sinhDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, math.Cosh) }
+
+func coshDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = Sinh(x); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func coshDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, math.Sinh) }
+
+// 1/(1-x²)
+func atanhDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = onePlusSquare(x, -1); err == nil {
+		retVal, err = HadamardDiv(gradY, retVal)
+	}
+	return
+}
+
+func atanhDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _atanhDiff) }
+
+// 1/(x·ln(10))
+func log10DiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	var ln10 *Node
+	if ln10, err = constantLike(x, math.Ln10); err != nil {
+		return
+	}
+
+	if retVal, err = HadamardProd(x, ln10); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = HadamardDiv(gradY, retVal)
+	}
+	return
+}
+
+func log10Diff(x, y *Node) (err error) { return pointwiseDiff(x, y, _log10Diff) }
+
+// scale·exp(-x²), which is the derivative of erf when scale is 2/√π, and of erfc when it is -2/√π
+func scaledGaussian(x *Node, scale float64) (retVal *Node, err error) {
+	var c *Node
+	if c, err = constantLike(x, scale); err != nil {
+		return
+	}
+
+	if retVal, err = Square(x); err == nil {
+		WithGroupName(gradClust)(retVal)
+		if retVal, err = Neg(retVal); err == nil {
+			WithGroupName(gradClust)(retVal)
+			if retVal, err = Exp(retVal); err == nil {
+				WithGroupName(gradClust)(retVal)
+				if retVal, err = HadamardProd(retVal, c); err == nil {
+					WithGroupName(gradClust)(retVal)
+				}
+			}
+		}
+	}
+	return
+}
+
+func erfDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = scaledGaussian(x, 2/math.SqrtPi); err == nil {
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func erfDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _erfDiff) }
+
+func erfcDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = scaledGaussian(x, -2/math.SqrtPi); err == nil {
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func erfcDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _erfcDiff) }
+
+func lgammaDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = Digamma(x); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func lgammaDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _digammaf64) }
+
+func digammaDiffExpr(x, y, gradY *Node) (retVal *Node, err error) {
+	if retVal, err = unaryOpNode(newElemUnaryOp(trigammaOpType, x), x); err == nil {
+		WithGroupName(gradClust)(retVal)
+		retVal, err = HadamardProd(retVal, gradY)
+	}
+	return
+}
+
+func digammaDiff(x, y *Node) (err error) { return pointwiseDiff(x, y, _trigammaf64) }
@@ -39,6 +39,31 @@ var (
 	// softplus isn't necessarily only a numerical stabilization op
 	// (you can use it elsewhere), but I included it under numerical optimization
 
+	// modern activation functions
+	leakyReluf64   = sf64UnaryOperator(_defaultLeakyReluf64)
+	eluf64         = sf64UnaryOperator(_defaultEluf64)
+	seluf64        = sf64UnaryOperator(_seluf64)
+	geluf64        = sf64UnaryOperator(_geluf64)
+	swishf64       = sf64UnaryOperator(_swishf64)
+	mishf64        = sf64UnaryOperator(_mishf64)
+	hardSigmoidf64 = sf64UnaryOperator(_hardSigmoidf64)
+	normCDFf64     = sf64UnaryOperator(_normCDFf64)
+
+	// trigonometric, hyperbolic and special functions
+	tanf64      = sf64UnaryOperator(math.Tan)
+	asinf64     = sf64UnaryOperator(math.Asin)
+	acosf64     = sf64UnaryOperator(math.Acos)
+	atanf64     = sf64UnaryOperator(math.Atan)
+	sinhf64     = sf64UnaryOperator(math.Sinh)
+	coshf64     = sf64UnaryOperator(math.Cosh)
+	atanhf64    = sf64UnaryOperator(math.Atanh)
+	log10f64    = sf64UnaryOperator(math.Log10)
+	erff64      = sf64UnaryOperator(math.Erf)
+	erfcf64     = sf64UnaryOperator(math.Erfc)
+	lgammaf64   = sf64UnaryOperator(_lgammaf64)
+	digammaf64  = sf64UnaryOperator(_digammaf64)
+	trigammaf64 = sf64UnaryOperator(_trigammaf64)
+
 	/* Float32 */
 
 	// non differentiable
@@ -67,6 +92,31 @@ var (
 	log1pf32    = sf32UnaryOperator(math32.Log1p)
 	expm1f32    = sf32UnaryOperator(math32.Expm1)
 	softplusf32 = sf32UnaryOperator(_softplusf32)
+
+	// modern activation functions
+	leakyReluf32   = sf32UnaryOperator(_defaultLeakyReluf32)
+	eluf32         = sf32UnaryOperator(_defaultEluf32)
+	seluf32        = sf32UnaryOperator(_seluf32)
+	geluf32        = sf32UnaryOperator(_geluf32)
+	swishf32       = sf32UnaryOperator(_swishf32)
+	mishf32        = sf32UnaryOperator(_mishf32)
+	hardSigmoidf32 = sf32UnaryOperator(_hardSigmoidf32)
+	normCDFf32     = sf32UnaryOperator(_normCDFf32)
+
+	// trigonometric, hyperbolic and special functions
+	tanf32      = sf32UnaryOperator(math32.Tan)
+	asinf32     = sf32UnaryOperator(math32.Asin)
+	acosf32     = sf32UnaryOperator(math32.Acos)
+	atanf32     = sf32UnaryOperator(math32.Atan)
+	sinhf32     = sf32UnaryOperator(math32.Sinh)
+	coshf32     = sf32UnaryOperator(math32.Cosh)
+	atanhf32    = sf32UnaryOperator(math32.Atanh)
+	log10f32    = sf32UnaryOperator(math32.Log10)
+	erff32      = sf32UnaryOperator(math32.Erf)
+	erfcf32     = sf32UnaryOperator(math32.Erfc)
+	lgammaf32   = sf32UnaryOperator(_lgammaf32)
+	digammaf32  = sf32UnaryOperator(_digammaf32)
+	trigammaf32 = sf32UnaryOperator(_trigammaf32)
 )
 
 type ʘUnaryOperatorType byte
@@ -98,6 +148,31 @@ const (
 	expm1OpType
 	softplusOpType
 
+	// modern activation functions
+	leakyReluOpType // parameterized by alpha
+	eluOpType       // parameterized by alpha
+	seluOpType
+	geluOpType
+	swishOpType // also known as SiLU
+	mishOpType
+	hardSigmoidOpType
+	normCDFOpType // Φ(x), which the derivative of GELU needs
+
+	// trigonometric, hyperbolic and special functions
+	tanOpType
+	asinOpType
+	acosOpType
+	atanOpType
+	sinhOpType
+	coshOpType
+	atanhOpType
+	log10OpType
+	erfOpType
+	erfcOpType
+	lgammaOpType
+	digammaOpType
+	trigammaOpType // ψ₁(x), which the derivative of digamma needs
+
 	maxʘUnaryOperator // delimits end of all possible unary ops
 )
 
@@ -109,6 +184,20 @@ func (u ʘUnaryOperatorType) String() string {
 	return ʘUnaryOpStrs[u]
 }
 
+// parameterized returns true if the operator takes an alpha parameter
+func (u ʘUnaryOperatorType) parameterized() bool { return u == leakyReluOpType || u == eluOpType }
+
+// defaultAlpha is the alpha a parameterized operator is created with
+func (u ʘUnaryOperatorType) defaultAlpha() float64 {
+	switch u {
+	case leakyReluOpType:
+		return defaultLeakyReluAlpha
+	case eluOpType:
+		return defaultEluAlpha
+	}
+	return 0
+}
+
 // ʘUnaryOpStrs is the string representation for a unaryOpType
 // It should be held constant.
 var ʘUnaryOpStrs = [maxʘUnaryOperator]string{
@@ -118,6 +207,11 @@ var ʘUnaryOpStrs = [maxʘUnaryOperator]string{
 	"inv", "cube", "tanh", "sigmoid",
 
 	"log1p", "expm1", "softplus",
+
+	"leakyrelu", "elu", "selu", "gelu", "swish", "mish", "hardsigmoid", "ncdf",
+
+	"tan", "asin", "acos", "atan", "sinh", "cosh", "atanh",
+	"log10", "erf", "erfc", "lgamma", "digamma", "trigamma",
 }
 
 // ʘUnaryOpDifferentiable is the array of whether a unary operator is differentiable
@@ -129,6 +223,11 @@ var ʘUnaryOpDifferentiable = [maxʘUnaryOperator]bool{
 	true, true, true, true,
 
 	true, true, true,
+
+	true, true, true, true, true, true, true, true,
+
+	true, true, true, true, true, true, true,
+	true, true, true, true, true, false,
 }
 
 var ʘUnaryOpDiffExprs = [maxʘUnaryOperator]func(x, y, gradY *Node) (*Node, error){
@@ -138,6 +237,11 @@ var ʘUnaryOpDiffExprs = [maxʘUnaryOperator]func(x, y, gradY *Node) (*Node, err
 	inverseDiffExpr, cubeDiffExpr, tanhDiffExpr, sigmoidDiffExpr,
 
 	log1pDiffExpr, expm1DiffExpr, softplusDiffExpr,
+
+	leakyReluDiffExpr, eluDiffExpr, seluDiffExpr, geluDiffExpr, swishDiffExpr, mishDiffExpr, hardSigmoidDiffExpr, normCDFDiffExpr,
+
+	tanDiffExpr, asinDiffExpr, acosDiffExpr, atanDiffExpr, sinhDiffExpr, coshDiffExpr, atanhDiffExpr,
+	log10DiffExpr, erfDiffExpr, erfcDiffExpr, lgammaDiffExpr, digammaDiffExpr, nondiffUnaryOpExpr,
 }
 
 var ʘUnaryOpDiffFns = [maxʘUnaryOperator]func(x, y *Node) error{
@@ -147,6 +251,11 @@ var ʘUnaryOpDiffFns = [maxʘUnaryOperator]func(x, y *Node) error{
 	inverseDiff, cubeDiff, tanhDiff, sigmoidDiff,
 
 	log1pDiff, expm1Diff, softplusDiff,
+
+	leakyReluDiff, eluDiff, seluDiff, geluDiff, swishDiff, mishDiff, hardSigmoidDiff, normCDFDiff,
+
+	tanDiff, asinDiff, acosDiff, atanDiff, sinhDiff, coshDiff, atanhDiff,
+	log10Diff, erfDiff, erfcDiff, lgammaDiff, digammaDiff, nondiffUnaryOp,
 }
 
 var sf64UnaryOperators = [maxʘUnaryOperator]*sf64UnaryOperator{
@@ -170,6 +279,29 @@ var sf64UnaryOperators = [maxʘUnaryOperator]*sf64UnaryOperator{
 	&log1pf64,
 	&expm1f64,
 	&softplusf64,
+
+	&leakyReluf64,
+	&eluf64,
+	&seluf64,
+	&geluf64,
+	&swishf64,
+	&mishf64,
+	&hardSigmoidf64,
+	&normCDFf64,
+
+	&tanf64,
+	&asinf64,
+	&acosf64,
+	&atanf64,
+	&sinhf64,
+	&coshf64,
+	&atanhf64,
+	&log10f64,
+	&erff64,
+	&erfcf64,
+	&lgammaf64,
+	&digammaf64,
+	&trigammaf64,
 }
 
 var sf32UnaryOperators = [maxʘUnaryOperator]*sf32UnaryOperator{
@@ -193,4 +325,27 @@ var sf32UnaryOperators = [maxʘUnaryOperator]*sf32UnaryOperator{
 	&log1pf32,
 	&expm1f32,
 	&softplusf32,
+
+	&leakyReluf32,
+	&eluf32,
+	&seluf32,
+	&geluf32,
+	&swishf32,
+	&mishf32,
+	&hardSigmoidf32,
+	&normCDFf32,
+
+	&tanf32,
+	&asinf32,
+	&acosf32,
+	&atanf32,
+	&sinhf32,
+	&coshf32,
+	&atanhf32,
+	&log10f32,
+	&erff32,
+	&erfcf32,
+	&lgammaf32,
+	&digammaf32,
+	&trigammaf32,
 }
@@ -213,7 +213,12 @@ func buildIntervals(sorted Nodes) map[*Node]*interval {
 			nInter.addRange(instrNum, instructions)
 			continue
 		}
-		nInter.addRange(instrNum, instrNum)
+		// derivatives are read after the program has run, so they cannot be overwritten
+		if len(n.derivOf) > 0 {
+			nInter.addRange(instrNum, instructions)
+		} else {
+			nInter.addRange(instrNum, instrNum)
+		}
 		// nInter.setFrom(instrNum)
 		// nInter.setTo(instrNum)
 
@@ -2,6 +2,7 @@ package gorgonia
 
 import (
 	"math"
+	"sort"
 
 	tf32 "github.com/chewxy/gorgonia/tensor/f32"
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
@@ -195,6 +196,13 @@ func (s *RMSPropSolver) Step(model Nodes) (err error) {
 			s.cache[i] = cached
 		}
 
+		if rows, ok := sparseRows(n); ok {
+			if err = s.sparseStep(dv, cached, rows); err != nil {
+				return
+			}
+			continue
+		}
+
 		var dt Dtype
 		dt, err = dtypeOf(dv.Type())
 		if err != nil {
@@ -394,6 +402,22 @@ func (s *RMSPropSolver) Step(model Nodes) (err error) {
 	return nil
 }
 
+// sparseStep updates only the given rows of the weights. The cache of the rows that are not updated do not decay.
+func (s *RMSPropSolver) sparseStep(dv, cached *dualValue, rows []int) error {
+	return updateRows(dv, []Value{cached.Value}, rows, func(w, g float64, c []float64) float64 {
+		c[0] = c[0]*s.decay + (1-s.decay)*g*g
+		if s.useClip {
+			g = clipGrad(g, s.clip)
+		}
+
+		upd := -s.eta * g / math.Sqrt(c[0]+s.eps)
+		if s.useL2Reg {
+			upd -= s.l2reg * w
+		}
+		return w + upd
+	})
+}
+
 // AdamSolver is the Adaptive Moment Estimation solver (basically RMSProp on steroids).
 // Paper: http://arxiv.org/abs/1412.6980
 //
@@ -461,6 +485,13 @@ func (s *AdamSolver) Step(model Nodes) (err error) {
 			s.cache[i] = cached
 		}
 
+		if rows, ok := sparseRows(n); ok {
+			if err = s.sparseStep(dv, cached, rows, correction1, correction2); err != nil {
+				return
+			}
+			continue
+		}
+
 		var dt Dtype
 		if dt, err = dtypeOf(dv.Type()); err != nil {
 			return
@@ -858,6 +889,24 @@ func (s *AdamSolver) Step(model Nodes) (err error) {
 	return
 }
 
+// sparseStep updates only the given rows of the weights. Like the lazy variants of Adam found elsewhere,
+// the means and variances of the rows that are not updated do not decay.
+func (s *AdamSolver) sparseStep(dv, cached *dualValue, rows []int, correction1, correction2 float64) error {
+	return updateRows(dv, []Value{cached.Value, cached.d}, rows, func(w, g float64, c []float64) float64 {
+		g = regularizeGrad(w, g, s.useL1Reg, s.useL2Reg, s.l1reg, s.l2reg, s.batch)
+		if s.useClip && s.clip > 0 {
+			g = clipGrad(g, s.clip)
+		}
+
+		c[0] = s.beta1*c[0] + (1-s.beta1)*g
+		c[1] = s.beta2*c[1] + (1-s.beta2)*g*g
+
+		mHat := c[0] / correction1
+		vHat := c[1] / correction2
+		return w - s.eta*mHat/(math.Sqrt(vHat)+s.eps)
+	})
+}
+
 // VanillaSolver is your bog standard stochastic gradient descent optimizer. There are no fancy features to this
 type VanillaSolver struct {
 	eta   float64 // learn rate
@@ -889,6 +938,13 @@ func (s *VanillaSolver) Step(model Nodes) (err error) {
 			return
 		}
 
+		if rows, ok := sparseRows(n); ok {
+			if err = s.sparseStep(dv, rows); err != nil {
+				return
+			}
+			continue
+		}
+
 		var dt Dtype
 		dt, err = dtypeOf(dv.Type())
 		if err != nil {
@@ -1120,6 +1176,17 @@ func (s *VanillaSolver) Step(model Nodes) (err error) {
 	return
 }
 
+// sparseStep updates only the given rows of the weights.
+func (s *VanillaSolver) sparseStep(dv *dualValue, rows []int) error {
+	return updateRows(dv, nil, rows, func(w, g float64, _ []float64) float64 {
+		g = regularizeGrad(w, g, s.useL1Reg, s.useL2Reg, s.l1reg, s.l2reg, s.batch)
+		if s.useClip && s.clip > 0 {
+			g = clipGrad(g, s.clip)
+		}
+		return w - s.eta*g
+	})
+}
+
 // AdaGradSolver is the solver that does adaptive gradient descent. Read the paper: http://jmlr.org/papers/v12/duchi11a.html
 type AdaGradSolver struct {
 	eta   float64 // learn rate
@@ -1166,6 +1233,13 @@ func (s *AdaGradSolver) Step(model Nodes) (err error) {
 			s.cache[i] = cached
 		}
 
+		if rows, ok := sparseRows(n); ok {
+			if err = s.sparseStep(dv, cached, rows); err != nil {
+				return
+			}
+			continue
+		}
+
 		var dt Dtype
 		if dt, err = dtypeOf(dv.Type()); err != nil {
 			return
@@ -1393,3 +1467,154 @@ func (s *AdaGradSolver) Step(model Nodes) (err error) {
 
 	return
 }
+
+// sparseStep updates only the given rows of the weights.
+func (s *AdaGradSolver) sparseStep(dv, cached *dualValue, rows []int) error {
+	return updateRows(dv, []Value{cached.Value}, rows, func(w, g float64, c []float64) float64 {
+		c[0] += g * g
+		if s.useClip {
+			g = clipGrad(g, s.clip)
+		}
+
+		upd := -s.eta * g / math.Sqrt(c[0]+s.eps)
+		if s.useL2Reg {
+			upd -= w * s.l2reg
+		}
+		return w + upd
+	})
+}
+
+// sparseRows returns the rows of n that have gradients, if the gradients of n are row-sparse.
+// This is the case when n is only used as the params of Gather along axis 0: only the rows that were gathered have gradients.
+func sparseRows(n *Node) (rows []int, ok bool) {
+	if n.g == nil || n.IsScalar() {
+		return nil, false
+	}
+
+	parents := n.g.To(n)
+	if len(parents) == 0 {
+		return nil, false
+	}
+
+	seen := make(map[int]struct{})
+	for _, p := range parents {
+		pn := p.(*Node)
+		op, isGather := pn.op.(gatherOp)
+		if !isGather || op.axis != 0 || pn.children[0] != n || pn.children[1] == n {
+			return nil, false
+		}
+
+		idx, err := indicesOf(op, pn.children[1].Value(), n.shape[0])
+		if err != nil {
+			return nil, false
+		}
+
+		for _, i := range idx {
+			if _, ok := seen[i]; !ok {
+				seen[i] = struct{}{}
+				rows = append(rows, i)
+			}
+		}
+	}
+	sort.Ints(rows)
+	return rows, true
+}
+
+// updateRows applies a row-sparse update to the weights held by dv. Only the given rows of the weights and caches are touched.
+// For each element, fn is given the weight, its gradient and the cached values, and returns the new weight. fn may modify
+// the cached values. The gradients of the rows are zeroed afterwards.
+func updateRows(dv *dualValue, caches []Value, rows []int, fn func(w, g float64, c []float64) float64) (err error) {
+	wt, ok := dv.Value.(Tensor)
+	if !ok {
+		return NewError(typeError, "Expected the weights to be a Tensor. Got %T instead", dv.Value)
+	}
+	gt, ok := dv.d.(Tensor)
+	if !ok {
+		return NewError(typeError, "Expected the gradients to be a Tensor. Got %T instead", dv.d)
+	}
+
+	cts := make([]types.Tensor, len(caches))
+	for i, c := range caches {
+		ct, ok := c.(Tensor)
+		if !ok {
+			return NewError(typeError, "Expected the cache to be a Tensor. Got %T instead", c)
+		}
+		cts[i] = ct.Tensor
+	}
+
+	rowSize := wt.Size() / wt.Shape()[0]
+	cs := make([]float64, len(caches))
+	switch wd := wt.Data().(type) {
+	case []float64:
+		gd := gt.Data().([]float64)
+		cds := make([][]float64, len(cts))
+		for i, ct := range cts {
+			cds[i] = ct.Data().([]float64)
+		}
+
+		for _, r := range rows {
+			for i := r * rowSize; i < (r+1)*rowSize; i++ {
+				for j, cd := range cds {
+					cs[j] = cd[i]
+				}
+				wd[i] = fn(wd[i], gd[i], cs)
+				for j, cd := range cds {
+					cd[i] = cs[j]
+				}
+				gd[i] = 0
+			}
+		}
+	case []float32:
+		gd := gt.Data().([]float32)
+		cds := make([][]float32, len(cts))
+		for i, ct := range cts {
+			cds[i] = ct.Data().([]float32)
+		}
+
+		for _, r := range rows {
+			for i := r * rowSize; i < (r+1)*rowSize; i++ {
+				for j, cd := range cds {
+					cs[j] = float64(cd[i])
+				}
+				wd[i] = float32(fn(float64(wd[i]), float64(gd[i]), cs))
+				for j, cd := range cds {
+					cd[i] = float32(cs[j])
+				}
+				gd[i] = 0
+			}
+		}
+	default:
+		err = nyi("row-sparse update", wt.Dtype())
+	}
+	return
+}
+
+// regularizeGrad adds the L1 and L2 regularization terms to a gradient, and scales it by the batch size
+func regularizeGrad(w, g float64, useL1Reg, useL2Reg bool, l1reg, l2reg, batch float64) float64 {
+	if useL1Reg {
+		switch {
+		case w > 0:
+			g += l1reg
+		case w < 0:
+			g -= l1reg
+		}
+	}
+
+	if useL2Reg {
+		g += l2reg * w
+	}
+
+	if batch > 1 {
+		g /= batch
+	}
+	return g
+}
+
+func clipGrad(g, clip float64) float64 {
+	if g > clip {
+		return clip
+	} else if g < -clip {
+		return -clip
+	}
+	return g
+}
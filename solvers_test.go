@@ -1,10 +1,12 @@
 package gorgonia
 
 import (
+	"fmt"
 	"math"
 	"testing"
 
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
+	ti "github.com/chewxy/gorgonia/tensor/i"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -65,3 +67,77 @@ func TestRMSPropSolver(t *testing.T) {
 
 	}
 }
+
+func TestSparseStep(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		newSolver func() Solver
+		expected  func(w, g float64) float64 // after one step
+	}{
+		{
+			func() Solver { return NewVanillaSolver(WithLearnRate(0.1), WithL2Reg(0.01)) },
+			func(w, g float64) float64 { return w - 0.1*(g+0.01*w) },
+		},
+		{
+			func() Solver { return NewAdamSolver(WithLearnRate(0.1)) },
+			func(w, g float64) float64 { return w - 0.1*g/(math.Abs(g)+1e-8) },
+		},
+		{
+			func() Solver { return NewRMSPropSolver(WithLearnRate(0.1)) },
+			func(w, g float64) float64 { return w - 0.1*g/math.Sqrt(0.001*g*g+1e-8) },
+		},
+		{
+			func() Solver { return NewAdaGradSolver() },
+			func(w, g float64) float64 { return w - 0.001*g/math.Sqrt(g*g+1e-8) },
+		},
+	}
+
+	for _, c := range cases {
+		// for the tape machine, the graph also holds the nodes that Grad adds. Only the gathered rows must be updated either way
+		for _, machine := range []string{"LispMachine", "TapeMachine"} {
+			g := NewGraph()
+			E := NewMatrix(g, Float64, WithName("E"), WithShape(5, 2), WithInit(RangedFrom(0)))
+			idx := NewVector(g, Int, WithName("idx"), WithShape(3), WithValue(ti.NewTensor(ti.WithShape(3), ti.WithBacking([]int{1, 3, 1}))))
+			cost := Must(Sum(Must(Gather(E, idx, 0))))
+
+			var m vm
+			switch machine {
+			case "LispMachine":
+				m = NewLispMachine(g)
+			case "TapeMachine":
+				if _, err := Grad(cost, E); err != nil {
+					t.Fatal(err)
+				}
+				prog, locMap, err := Compile(g)
+				if err != nil {
+					t.Fatal(err)
+				}
+				m = NewTapeMachine(prog, locMap)
+			}
+			if err := m.RunAll(); err != nil {
+				t.Fatal(err)
+			}
+
+			s := c.newSolver()
+			if err := s.Step(Nodes{E}); err != nil {
+				t.Fatal(err)
+			}
+
+			grads := []float64{0, 2, 0, 1, 0} // the number of times each row was gathered
+			name := fmt.Sprintf("%T: %s", s, machine)
+			w := E.Value().(Tensor).Tensor.Data().([]float64)
+			for i, v := range w {
+				orig := float64(i)
+				if g := grads[i/2]; g == 0 {
+					assert.Equal(orig, v, name)
+				} else {
+					assert.InDelta(c.expected(orig, g), v, 1e-6, name)
+				}
+			}
+
+			eG, _ := E.Grad()
+			assert.Equal(make([]float64, 10), eG.(Tensor).Tensor.Data(), name)
+		}
+	}
+}
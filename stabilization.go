@@ -4,7 +4,7 @@ var unaryOpStabilizationFns = make(map[ʘUnaryOperatorType][]func(*Node) (*Node,
 var binOpStabilizationFns = make(map[ʘBinaryOperatorType][]func(*Node, *Node) (*Node, error))
 
 func init() {
-	unaryOpStabilizationFns[lnOpType] = []func(*Node) (*Node, error){logSigmoidStabilization, logStabilization}
+	unaryOpStabilizationFns[lnOpType] = []func(*Node) (*Node, error){logSigmoidStabilization, logSoftMaxStabilization, logStabilization}
 	binOpStabilizationFns[subOpType] = []func(*Node, *Node) (*Node, error){expStabilization}
 	unaryOpStabilizationFns[log1pOpType] = []func(*Node) (*Node, error){log1pExpStabilization, log1pNegSigmoidStabilization}
 }
@@ -52,9 +52,11 @@ func logStabilization(a *Node) (retVal *Node, err error) {
 		return a, noStabilizationErr{}
 	}
 
-	g := a.g
-	g.removeAllEdgesFrom(a) // remove all references
-	defer returnNode(a)     // send it back to the pool, since it is literally useless now
+	// a is only useless once the log replaces it. If anything else uses it (say, the gradient of another op), it has to stay
+	if g := a.g; len(g.To(a)) == 0 {
+		g.removeAllEdgesFrom(a) // remove all references
+		defer returnNode(a)     // send it back to the pool, since it is literally useless now
+	}
 
 	if bot == subOpType {
 		if retVal, err = Neg(x); err == nil {
@@ -127,6 +129,75 @@ func logSigmoidStabilization(a *Node) (retVal *Node, err error) {
 	return
 }
 
+// logSoftMaxStabilization stabilizes log(softmax(x)) by computing it as logsoftmax(x).
+// Both SoftMax and StableSoftMax are recognized.
+// place before log; a should be exp(x)/sum(exp(x))
+func logSoftMaxStabilization(a *Node) (retVal *Node, err error) {
+	stabLogf("Stabilizing log softmax of %v", a)
+	enterLoggingContext()
+	defer leaveLoggingContext()
+
+	if ebo, ok := a.op.(elemBinOp); !ok || ebo.binOpType() != divOpType {
+		return a, noStabilizationErr{}
+	}
+
+	exp, den := a.children[0], a.children[1]
+	if euo, ok := exp.op.(elemUnaryOp); !ok || euo.unaryOpType() != expOpType {
+		return a, noStabilizationErr{}
+	}
+
+	// the sum may have been broadcast
+	switch den.op.(type) {
+	case *repeatOp, broadcastOp:
+		den = den.children[0]
+	}
+
+	sum, ok := den.op.(sumOp)
+	if !ok || len(sum.along) != 1 || den.children[0] != exp {
+		return a, noStabilizationErr{}
+	}
+	axis := sum.along[0]
+
+	// StableSoftMax subtracts the max first. logsoftmax is invariant to that.
+	x := exp.children[0]
+	if ebo, ok := x.op.(elemBinOp); ok && ebo.binOpType() == subOpType {
+		if max, ok := x.children[1].op.(maxOp); ok && x.children[1].children[0] == x.children[0] {
+			for _, along := range max.along {
+				if along == axis {
+					x = x.children[0]
+					break
+				}
+			}
+		}
+	}
+
+	stabLogf("x : %v", x.Name())
+	if checkSoftMaxAxis("LogSoftMax", x, axis) != nil {
+		return a, noStabilizationErr{}
+	}
+
+	// a is kept, as the caller may still want to differentiate with regards to it
+	op := newLogOfSoftMaxOp(axis, x.shape, x.Dims())
+	return applyOp(op, x, a)
+}
+
+// unstabilize undoes the stabilization of log(softmax(x)) for the nodes in wrt, by differentiating it through softmax(x)
+// again. Otherwise the softmax nodes in wrt wouldn't affect anything.
+func unstabilize(wrt Nodes) {
+	for _, n := range wrt {
+		if n.g == nil {
+			continue
+		}
+		for _, p := range n.g.To(n) {
+			pn := p.(*Node)
+			if op, ok := pn.op.(logOfSoftMaxOp); ok && pn.children[1] == n {
+				op.viaSoftMax = true
+				pn.op = op
+			}
+		}
+	}
+}
+
 // log1pExpStabilization stabilizes log1p(exp(x)) by substituting it with softplus(x)
 // place before log1p; a should be exp(x)
 func log1pExpStabilization(a *Node) (retVal *Node, err error) {
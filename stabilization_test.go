@@ -2,7 +2,10 @@ package gorgonia
 
 import (
 	"io/ioutil"
+	"math"
 	"testing"
+
+	tf64 "github.com/chewxy/gorgonia/tensor/f64"
 )
 
 func TestLogStabilization(t *testing.T) {
@@ -105,3 +108,73 @@ func TestLogSigmoidStabilization(t *testing.T) {
 		ioutil.WriteFile("logY.dot", []byte(logY.ToDot()), 0644)
 	}
 }
+
+func TestLogSoftMaxStabilization(t *testing.T) {
+	stabilization = true
+
+	build := func() (g *ExprGraph, x, sm, logsm, cost *Node) {
+		g = NewGraph()
+		xT := tf64.NewTensor(tf64.WithBacking([]float64{0, 1000, -1000}))
+		x = NewVector(g, Float64, WithName("x"), WithShape(3), WithValue(xT))
+		sm = Must(SoftMax(x))
+		logsm = Must(Log(sm))
+		cost = Must(Sum(logsm))
+		return
+	}
+
+	// the gradient is 1 - 3 softmax(x). Through softmax(x), it would be NaN where softmax(x) underflows to 0
+	correctG := []float64{1, -2, 1}
+	checkGrad := func(x *Node, machine string) {
+		xG, err := x.Grad()
+		if err != nil {
+			t.Fatalf("%s: %v", machine, err)
+		}
+		for i, v := range extractF64s(xG) {
+			if math.Abs(v-correctG[i]) > 1e-10 {
+				t.Errorf("%s: Expected a gradient of %v at %d. Got %v instead", machine, correctG[i], i, v)
+			}
+		}
+	}
+
+	g, x, sm, logsm, cost := build()
+	if _, ok := logsm.op.(logOfSoftMaxOp); !ok {
+		t.Fatalf("Expected Log(SoftMax(x)) to be stabilized. Got %v instead", logsm.op)
+	}
+
+	if logsm.children[0] != x || logsm.children[1] != sm {
+		t.Errorf("Expected the children to be x and SoftMax(x). Got %v instead", logsm.children)
+	}
+
+	if _, err := Grad(cost, x); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = NewTapeMachine(prog, locMap).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := cost.Value().(Scalar).v.(float64); math.Abs(v+3000) > 1e-10 {
+		t.Errorf("Expected a cost of -3000. Got %v instead", v)
+	}
+	checkGrad(x, "TapeMachine")
+
+	// lisp machine
+	g, x, _, logsm, _ = build()
+	if err = NewLispMachine(g).RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// log(softmax(x)) underflows to -Inf for -1000 when computed naively
+	correct := []float64{-1000, 0, -2000}
+	for i, v := range extractF64s(logsm.Value()) {
+		if math.Abs(v-correct[i]) > 1e-10 {
+			t.Errorf("Expected %v at %d. Got %v instead", correct[i], i, v)
+		}
+	}
+	checkGrad(x, "LispMachine")
+}
@@ -0,0 +1,139 @@
+package tensorb
+
+import "github.com/chewxy/gorgonia/tensor/types"
+
+// public API for logical ops
+
+type logicOp byte
+
+const (
+	and logicOp = iota
+	or
+	xor
+)
+
+func (op logicOp) String() string {
+	switch op {
+	case and:
+		return "and"
+	case or:
+		return "or"
+	case xor:
+		return "xor"
+	}
+	return "unknown logical op"
+}
+
+func (op logicOp) do(a, b bool) bool {
+	switch op {
+	case and:
+		return a && b
+	case or:
+		return a || b
+	case xor:
+		return a != b
+	}
+	panic("unreachable")
+}
+
+// And performs a pointwise logical and (a && b). a and b can either be bool or *Tensor.
+//
+// If both operands are *Tensor, shape is checked first.
+//
+// If the Unsafe flag is passed in, the data of the first tensor will be overwritten
+func And(a, b interface{}, opts ...types.FuncOpt) (retVal *Tensor, err error) {
+	return logic(and, a, b, opts...)
+}
+
+// Or performs a pointwise logical or (a || b). a and b can either be bool or *Tensor.
+//
+// If both operands are *Tensor, shape is checked first.
+//
+// If the Unsafe flag is passed in, the data of the first tensor will be overwritten
+func Or(a, b interface{}, opts ...types.FuncOpt) (retVal *Tensor, err error) {
+	return logic(or, a, b, opts...)
+}
+
+// Xor performs a pointwise logical exclusive or (a != b). a and b can either be bool or *Tensor.
+//
+// If both operands are *Tensor, shape is checked first.
+//
+// If the Unsafe flag is passed in, the data of the first tensor will be overwritten
+func Xor(a, b interface{}, opts ...types.FuncOpt) (retVal *Tensor, err error) {
+	return logic(xor, a, b, opts...)
+}
+
+// Not performs a pointwise logical not (!a).
+//
+// If the Unsafe flag is passed in, the data of the tensor will be overwritten
+func Not(a *Tensor, opts ...types.FuncOpt) (retVal *Tensor, err error) {
+	return a.Apply(func(v bool) bool { return !v }, opts...)
+}
+
+func logic(op logicOp, a, b interface{}, opts ...types.FuncOpt) (retVal *Tensor, err error) {
+	safe, incr, reuse := parseSafeReuse(opts...)
+	if incr {
+		err = notyetimplemented("Incr is not supported for logical %v", op)
+		return
+	}
+
+	at, atok := a.(*Tensor)
+	bt, btok := b.(*Tensor)
+	ab, abok := a.(bool)
+	bb, bbok := b.(bool)
+
+	// materialize any views
+	if atok && at.IsView() {
+		at = at.Materialize().(*Tensor)
+	}
+	if btok && bt.IsView() {
+		bt = bt.Materialize().(*Tensor)
+	}
+
+	var t *Tensor // the tensor that determines the shape of the result
+	switch {
+	case atok && btok:
+		if !at.Shape().Eq(bt.Shape()) {
+			err = types.NewError(types.ShapeMismatch, "Cannot %v tensors with shapes %v and %v", op, at.Shape(), bt.Shape())
+			return
+		}
+		t = at
+	case atok && bbok:
+		t = at
+	case abok && btok:
+		t = bt
+	default:
+		err = types.NewError(types.DtypeMismatch, "Logical %v cannot be done on %T and %T", op, a, b)
+		return
+	}
+
+	switch {
+	case reuse != nil:
+		if !t.Shape().Eq(reuse.Shape()) {
+			err = types.NewError(types.ShapeMismatch, "Reused Tensor does not have expected shape %v. Got %v instead", t.Shape(), reuse.Shape())
+			return
+		}
+		retVal = reuse
+	case !safe:
+		retVal = t
+	default:
+		retVal = NewTensor(WithShape(t.Shape()...))
+	}
+
+	res := retVal.data
+	switch {
+	case atok && btok:
+		for i, v := range at.data {
+			res[i] = op.do(v, bt.data[i])
+		}
+	case atok && bbok:
+		for i, v := range at.data {
+			res[i] = op.do(v, bb)
+		}
+	case abok && btok:
+		for i, v := range bt.data {
+			res[i] = op.do(ab, v)
+		}
+	}
+	return
+}
@@ -0,0 +1,58 @@
+package tensorb
+
+import (
+	"testing"
+
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogic(t *testing.T) {
+	assert := assert.New(t)
+	a := NewTensor(WithBacking([]bool{true, true, false, false}))
+	b := NewTensor(WithBacking([]bool{true, false, true, false}))
+
+	got, err := And(a, b)
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]bool{true, false, false, false}, got.data)
+
+	if got, err = Or(a, b); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]bool{true, true, true, false}, got.data)
+
+	if got, err = Xor(a, b); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]bool{false, true, true, false}, got.data)
+
+	if got, err = Not(a); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]bool{false, false, true, true}, got.data)
+
+	// scalars
+	if got, err = And(a, false); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]bool{false, false, false, false}, got.data)
+
+	if got, err = Or(true, b); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]bool{true, true, true, true}, got.data)
+
+	// unsafe
+	if got, err = Xor(a, true, types.UseUnsafe()); err != nil {
+		t.Error(err)
+	}
+	assert.True(got == a)
+	assert.Equal([]bool{false, false, true, true}, a.data)
+
+	// bad shapes
+	c := NewTensor(WithShape(2, 2))
+	_, err = And(a, c)
+	assert.NotNil(err)
+}
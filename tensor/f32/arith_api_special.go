@@ -0,0 +1,69 @@
+package tensorf32
+
+import (
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/chewxy/math32"
+)
+
+/*
+This file contains the special arithmetic functions that the unary and binary operations don't cover.
+*/
+
+// Atan2 computes atan2(a, b) elementwise. Either a or b can be a float32 - it will be used with every element of the other.
+func Atan2(a, b interface{}, opts ...types.FuncOpt) (retVal *Tensor, err error) {
+	safe, incr, reuse := parseSafeReuse(opts...)
+
+	at, atok := a.(*Tensor)
+	bt, btok := b.(*Tensor)
+	af, afok := a.(float32)
+	bf, bfok := b.(float32)
+
+	// t is the tensor that decides the shape of the result
+	var t *Tensor
+	switch {
+	case atok && btok:
+		if !at.Shape().Eq(bt.Shape()) {
+			err = types.NewError(types.ShapeMismatch, "Cannot atan2 tensors with shapes %v and %v", at.Shape(), bt.Shape())
+			return
+		}
+		t = at
+	case atok && bfok:
+		t = at
+	case afok && btok:
+		t = bt
+	default:
+		err = types.NewError(types.DtypeMismatch, "Atan2 cannot be done on %T and %T", a, b)
+		return
+	}
+
+	switch {
+	case reuse != nil:
+		if !t.Shape().Eq(reuse.Shape()) {
+			err = types.NewError(types.ShapeMismatch, "Reused Tensor does not have expected shape %v. Got %v instead", t.Shape(), reuse.Shape())
+			return
+		}
+		retVal = reuse
+	case safe:
+		retVal = newBorrowedTensor(len(t.data))
+		retVal.setShape(t.Shape()...)
+	default:
+		retVal = t
+	}
+
+	for i := range retVal.data {
+		y, x := af, bf
+		if atok {
+			y = at.data[i]
+		}
+		if btok {
+			x = bt.data[i]
+		}
+
+		if incr {
+			retVal.data[i] += math32.Atan2(y, x)
+		} else {
+			retVal.data[i] = math32.Atan2(y, x)
+		}
+	}
+	return
+}
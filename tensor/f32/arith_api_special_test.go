@@ -0,0 +1,75 @@
+package tensorf32
+
+import (
+	"testing"
+
+	"github.com/chewxy/gorgonia/tensor/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtan2(t *testing.T) {
+	assert := assert.New(t)
+	var got *Tensor
+	var err error
+
+	Ta := NewTensor(WithBacking([]float32{1, -1, 0, 2}))
+	Tb := NewTensor(WithBacking([]float32{1, 1, -1, 0}))
+	correct := []float32{0.7853981633974483, -0.7853981633974483, 3.141592653589793, 1.5707963267948966}
+
+	// safe
+	if got, err = Atan2(Ta, Tb); err != nil {
+		t.Fatal(err)
+	}
+	if got == Ta || got == Tb {
+		t.Error(safeOpErr)
+	}
+	for i, v := range got.data {
+		assert.InDelta(correct[i], v, 1e-6)
+	}
+
+	// tensor-scalar and scalar-tensor
+	if got, err = Atan2(Ta, float32(1)); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []float32{0.7853981633974483, -0.7853981633974483, 0, 1.1071487177940904} {
+		assert.InDelta(v, got.data[i], 1e-6)
+	}
+	if got, err = Atan2(float32(1), Tb); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []float32{0.7853981633974483, 0.7853981633974483, 2.356194490192345, 1.5707963267948966} {
+		assert.InDelta(v, got.data[i], 1e-6)
+	}
+
+	// incr
+	incr := NewTensor(WithBacking([]float32{1, 1, 1, 1}))
+	if got, err = Atan2(Ta, Tb, types.WithIncr(incr)); err != nil {
+		t.Fatal(err)
+	}
+	if got != incr {
+		t.Error(reuseOpErr)
+	}
+	for i, v := range got.data {
+		assert.InDelta(correct[i]+1, v, 1e-6)
+	}
+
+	// unsafe
+	if got, err = Atan2(Ta, Tb, types.UseUnsafe()); err != nil {
+		t.Fatal(err)
+	}
+	if got != Ta {
+		t.Error(unsafeOpErr)
+	}
+	for i, v := range got.data {
+		assert.InDelta(correct[i], v, 1e-6)
+	}
+
+	/* Idiots */
+
+	if _, err = Atan2(Ta, NewTensor(WithShape(2))); err == nil {
+		t.Error("Expected a ShapeMismatch error")
+	}
+	if _, err = Atan2(float32(1), float32(1)); err == nil {
+		t.Error("Expected an error when neither operand is a *Tensor")
+	}
+}
@@ -219,3 +219,32 @@ func TestCmp(t *testing.T) {
 	}
 
 }
+
+func TestCmpAsSameType(t *testing.T) {
+	assert := assert.New(t)
+
+	Ta := NewTensor(WithBacking([]float64{1, 2, 3, 4, 5}))
+	Tb := NewTensor(WithBacking([]float64{5, 4, 3, 2, 1}))
+
+	got, err := Lt(Ta, Tb, types.AsSameType())
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]float64{1, 1, 0, 0, 0}, got.Data())
+
+	if got, err = Lt(Ta, float64(3), types.AsSameType()); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]float64{1, 1, 0, 0, 0}, got.Data())
+
+	// scalar on the left
+	if got, err = Lt(float64(3), Ta, types.AsSameType()); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]float64{0, 0, 0, 1, 1}, got.Data())
+
+	if got, err = Ne(Ta, Tb, types.AsSameType()); err != nil {
+		t.Error(err)
+	}
+	assert.Equal([]float64{1, 1, 0, 1, 1}, got.Data())
+}
@@ -50,6 +50,8 @@ var boolignores = []string{
 
 var intignores = []string{
 	"blas.go",
+	"arith_api_special.go",
+	"arith_api_special_test.go",
 	"arith_floats.go",
 	"arith_linalg_api.go",
 	"arith_linalg_api_test.go",
@@ -169,6 +171,9 @@ var replacements = map[string]map[string]string{
 		// arith_api_unary_test.go
 		"correct[i] = math.Sqrt(v)": "correct[i] = math32.Sqrt(v)",
 
+		// arith_api_special.go
+		"math.Atan2(y, x)": "math32.Atan2(y, x)",
+
 		// arith_floats.go
 		"math.IsNaN(d)":      "math32.IsNaN(d)",
 		"if !math.IsNaN(v) ": "if !math32.IsNaN(v) ",
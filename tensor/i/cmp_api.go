@@ -27,23 +27,26 @@ func Lt(a, b interface{}, opts ...types.FuncOpt) (retVal types.Tensor, err error
 		return bt.scalarCmp(op, false, af)
 
 	// returns TensorF64
+	case !boolT && atok && btok:
+		return at.tensorCmp(op, bt, boolT)
 	case !boolT && atok && bfok:
 		var b []bool
 		if b, err = scalarCmpBacking(op, true, bf, at.data); err == nil {
 			backing := boolsToInts(b)
 			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
 		}
+		return
 	case !boolT && afok && btok:
 		var b []bool
-		if b, err = scalarCmpBacking(op, true, af, bt.data); err == nil {
+		if b, err = scalarCmpBacking(op, false, af, bt.data); err == nil {
 			backing := boolsToInts(b)
-			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
+			retVal = NewTensor(WithShape(bt.Shape()...), WithBacking(backing))
 		}
+		return
 	default:
 		err = types.NewError(types.DtypeMismatch, "Comparison cannot be done on %T and %T", a, b)
 		return
 	}
-	panic("unreachable")
 }
 
 // Gt performs a pointwise greater than comparison (a > b). a and b can either be int or *Tensor.
@@ -67,23 +70,26 @@ func Gt(a, b interface{}, opts ...types.FuncOpt) (retVal types.Tensor, err error
 		return at.scalarCmp(op, true, bf)
 	case boolT && afok && btok:
 		return bt.scalarCmp(op, false, af)
+	case !boolT && atok && btok:
+		return at.tensorCmp(op, bt, boolT)
 	case !boolT && atok && bfok:
 		var b []bool
 		if b, err = scalarCmpBacking(op, true, bf, at.data); err == nil {
 			backing := boolsToInts(b)
 			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
 		}
+		return
 	case !boolT && afok && btok:
 		var b []bool
-		if b, err = scalarCmpBacking(op, true, af, bt.data); err == nil {
+		if b, err = scalarCmpBacking(op, false, af, bt.data); err == nil {
 			backing := boolsToInts(b)
-			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
+			retVal = NewTensor(WithShape(bt.Shape()...), WithBacking(backing))
 		}
+		return
 	default:
 		err = types.NewError(types.DtypeMismatch, "Comparison cannot be done on %T and %T", a, b)
 		return
 	}
-	panic("unreachable")
 }
 
 // Lte performs a pointwise less than eq comparison (a <= b). a and b can either be int or *Tensor.
@@ -109,23 +115,26 @@ func Lte(a, b interface{}, opts ...types.FuncOpt) (retVal types.Tensor, err erro
 		return bt.scalarCmp(op, false, af)
 
 	// returns TensorF64
+	case !boolT && atok && btok:
+		return at.tensorCmp(op, bt, boolT)
 	case !boolT && atok && bfok:
 		var b []bool
 		if b, err = scalarCmpBacking(op, true, bf, at.data); err == nil {
 			backing := boolsToInts(b)
 			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
 		}
+		return
 	case !boolT && afok && btok:
 		var b []bool
-		if b, err = scalarCmpBacking(op, true, af, bt.data); err == nil {
+		if b, err = scalarCmpBacking(op, false, af, bt.data); err == nil {
 			backing := boolsToInts(b)
-			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
+			retVal = NewTensor(WithShape(bt.Shape()...), WithBacking(backing))
 		}
+		return
 	default:
 		err = types.NewError(types.DtypeMismatch, "Comparison cannot be done on %T and %T", a, b)
 		return
 	}
-	panic("unreachable")
 }
 
 // Gte performs a pointwise greater than eq comparison (a >= b). a and b can either be int or *Tensor.
@@ -151,23 +160,26 @@ func Gte(a, b interface{}, opts ...types.FuncOpt) (retVal types.Tensor, err erro
 		return bt.scalarCmp(op, false, af)
 
 	// returns TensorF64
+	case !boolT && atok && btok:
+		return at.tensorCmp(op, bt, boolT)
 	case !boolT && atok && bfok:
 		var b []bool
 		if b, err = scalarCmpBacking(op, true, bf, at.data); err == nil {
 			backing := boolsToInts(b)
 			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
 		}
+		return
 	case !boolT && afok && btok:
 		var b []bool
-		if b, err = scalarCmpBacking(op, true, af, bt.data); err == nil {
+		if b, err = scalarCmpBacking(op, false, af, bt.data); err == nil {
 			backing := boolsToInts(b)
-			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
+			retVal = NewTensor(WithShape(bt.Shape()...), WithBacking(backing))
 		}
+		return
 	default:
 		err = types.NewError(types.DtypeMismatch, "Comparison cannot be done on %T and %T", a, b)
 		return
 	}
-	panic("unreachable")
 }
 
 // Eq performs a pointwise equality comparison (a == b). a and b can either be int or *Tensor.
@@ -193,23 +205,26 @@ func Eq(a, b interface{}, opts ...types.FuncOpt) (retVal types.Tensor, err error
 		return bt.scalarCmp(op, false, af)
 
 	// returns TensorF64
+	case !boolT && atok && btok:
+		return at.tensorCmp(op, bt, boolT)
 	case !boolT && atok && bfok:
 		var b []bool
 		if b, err = scalarCmpBacking(op, true, bf, at.data); err == nil {
 			backing := boolsToInts(b)
 			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
 		}
+		return
 	case !boolT && afok && btok:
 		var b []bool
-		if b, err = scalarCmpBacking(op, true, af, bt.data); err == nil {
+		if b, err = scalarCmpBacking(op, false, af, bt.data); err == nil {
 			backing := boolsToInts(b)
-			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
+			retVal = NewTensor(WithShape(bt.Shape()...), WithBacking(backing))
 		}
+		return
 	default:
 		err = types.NewError(types.DtypeMismatch, "Comparison cannot be done on %T and %T", a, b)
 		return
 	}
-	panic("unreachable")
 }
 
 // Ne performs a pointwise equality comparison (a != b). a and b can either be int or *Tensor.
@@ -235,21 +250,24 @@ func Ne(a, b interface{}, opts ...types.FuncOpt) (retVal types.Tensor, err error
 		return bt.scalarCmp(op, false, af)
 
 	// returns TensorF64
+	case !boolT && atok && btok:
+		return at.tensorCmp(op, bt, boolT)
 	case !boolT && atok && bfok:
 		var b []bool
 		if b, err = scalarCmpBacking(op, true, bf, at.data); err == nil {
 			backing := boolsToInts(b)
 			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
 		}
+		return
 	case !boolT && afok && btok:
 		var b []bool
-		if b, err = scalarCmpBacking(op, true, af, bt.data); err == nil {
+		if b, err = scalarCmpBacking(op, false, af, bt.data); err == nil {
 			backing := boolsToInts(b)
-			retVal = NewTensor(WithShape(at.Shape()...), WithBacking(backing))
+			retVal = NewTensor(WithShape(bt.Shape()...), WithBacking(backing))
 		}
+		return
 	default:
 		err = types.NewError(types.DtypeMismatch, "Comparison cannot be done on %T and %T", a, b)
 		return
 	}
-	panic("unreachable")
 }
@@ -52,7 +52,11 @@ var apPool [maxAPDims]sync.Pool
 
 func BorrowAP(dims int) *AP {
 	if dims >= maxAPDims {
-		return new(AP)
+		return &AP{
+			dims:    dims,
+			shape:   make(Shape, dims),
+			strides: make([]int, dims),
+		}
 	}
 
 	ap := apPool[dims].Get().(*AP)
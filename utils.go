@@ -147,7 +147,7 @@ func ones(dt Dtype, sizes ...int) (retVal Value) {
 		}
 	case Float32:
 		if len(sizes) == 0 {
-			retVal = NewScalarValue(float64(1.0))
+			retVal = NewScalarValue(float32(1.0))
 		} else {
 			t := tf32.Ones(sizes...)
 			retVal = FromTensor(t)
@@ -17,7 +17,7 @@ const (
 	watchInf
 	allocVals
 	spare2
-	spare3
+	inferMode
 	watchAll
 )
 
@@ -220,3 +220,38 @@ func TraceExec() VMOpt {
 	}
 	return f
 }
+
+// TrainingMode creates a VM that executes the ops that behave differently when training and when doing inference (like BatchNorm) in training mode.
+// This is the default.
+//
+// Like any VMOpt, it may also be applied to an existing VM between runs (TrainingMode()(m)), so that one compiled graph is used for both modes.
+func TrainingMode() VMOpt {
+	f := func(m vm) {
+		switch v := m.(type) {
+		case *lispMachine:
+			v.dontInfer()
+		case *tapeMachine:
+			v.dontInfer()
+		default:
+			panic(nyi("TrainingMode", v))
+		}
+	}
+	return f
+}
+
+// InferenceMode creates a VM that executes the ops that behave differently when training and when doing inference (like BatchNorm) in inference mode.
+//
+// Like any VMOpt, it may also be applied to an existing VM between runs (InferenceMode()(m)), so that one compiled graph is used for both modes.
+func InferenceMode() VMOpt {
+	f := func(m vm) {
+		switch v := m.(type) {
+		case *lispMachine:
+			v.doInfer()
+		case *tapeMachine:
+			v.doInfer()
+		default:
+			panic(nyi("InferenceMode", v))
+		}
+	}
+	return f
+}
@@ -78,6 +78,10 @@ func (m *lispMachine) dealloc() bool { return (m.runFlags>>allocVals)&byte(1) ==
 func (m *lispMachine) doDealloc()    { m.runFlags |= byte(1) << allocVals }
 func (m *lispMachine) dontDealloc()  { m.runFlags &= (^(byte(1) << allocVals)) }
 
+func (m *lispMachine) infer() bool { return (m.runFlags>>inferMode)&byte(1) == 1 }
+func (m *lispMachine) doInfer()    { m.runFlags |= byte(1) << inferMode }
+func (m *lispMachine) dontInfer()  { m.runFlags &= (^(byte(1) << inferMode)) }
+
 // check roots only applies if you want to run a backprop as well
 func (m *lispMachine) checkRoots() (err error) {
 	if !m.checkedRoots && m.runBwd() {
@@ -128,6 +132,9 @@ func (m *lispMachine) forward() (err error) {
 	// other wise it's time to execute the op
 	machineLogf("execute Op")
 	op := n.op
+	if mo, ok := op.(modalOp); ok {
+		mo.setTraining(!m.infer())
+	}
 	var output *dualValue
 
 	inputs := make([]*dualValue, len(n.children))
@@ -151,6 +158,11 @@ func (m *lispMachine) forward() (err error) {
 			if err = dvBind0(op, dv, inputs); err != nil {
 				return
 			}
+
+			// dvBind0 zeroes the derivative, but the derivative of a root wrt itself is 1
+			if err = dv.SetDeriv(ones(dv.Value.Dtype(), dv.Value.Shape()...)); err != nil {
+				return
+			}
 		}
 
 	case n.isStmt:
@@ -198,7 +210,8 @@ func (m *lispMachine) forward() (err error) {
 	}
 	m.watchedLogf(m.valueFmt, n.boundTo)
 
-	if aop, ok := op.(AdOp); ok && m.runBwd() {
+	// ops that aren't differentiable wrt any of their inputs (like comparisons that produce masks) aren't backpropagated through
+	if aop, ok := op.(AdOp); ok && m.runBwd() && isDifferentiable(n) {
 		instr := adInstr{
 			AdOp: aop,
 
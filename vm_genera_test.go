@@ -2,9 +2,11 @@ package gorgonia
 
 import (
 	"bytes"
+	"fmt"
 	"log"
 	"testing"
 
+	tf32 "github.com/chewxy/gorgonia/tensor/f32"
 	tf64 "github.com/chewxy/gorgonia/tensor/f64"
 	"github.com/stretchr/testify/assert"
 )
@@ -138,6 +140,13 @@ func TestLispMachineMechanics(t *testing.T) {
 		t.Error("A new value for szp3dv.Value has been allocated. That ain't supposed to happen")
 	}
 
+	// a new machine on the same root: the derivative of the root wrt itself is still 1
+	machine = NewLispMachine(g.SubgraphRoots(szp3))
+	if err = machine.RunAll(); err != nil {
+		t.Error(err)
+	}
+	assert.Equal(1.0, extractF64(szp3.boundTo.(*dualValue).d))
+
 	// idiotsville
 
 	// non scalar costs
@@ -150,6 +159,41 @@ func TestLispMachineMechanics(t *testing.T) {
 	}
 }
 
+// The derivative of a root wrt itself is reset to 1 every time a machine runs the graph, and not only the first time
+func TestLispMachineRerun(t *testing.T) {
+	assert := assert.New(t)
+	xs := []Value{
+		FromTensor(tf64.NewTensor(tf64.WithBacking([]float64{1, 2, 3}))),
+		FromTensor(tf32.NewTensor(tf32.WithBacking([]float32{1, 2, 3}))),
+	}
+	for _, xV := range xs {
+		dt := xV.Dtype()
+		g := NewGraph()
+		x := NewVector(g, dt, WithName("x"), WithShape(3), WithValue(xV))
+		Must(Sum(Must(Square(x))))
+
+		for i := 0; i < 2; i++ {
+			name := fmt.Sprintf("%v, run %d", dt, i)
+			if err := NewLispMachine(g).RunAll(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			xG, err := x.Grad()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			// the gradients of the inputs accumulate over the runs
+			k := float64(i + 1)
+			switch data := xG.(Tensor).Data().(type) {
+			case []float64:
+				assert.Equal([]float64{2 * k, 4 * k, 6 * k}, data, name)
+			case []float32:
+				assert.Equal([]float32{float32(2 * k), float32(4 * k), float32(6 * k)}, data, name)
+			}
+		}
+	}
+}
+
 func TestLispMachineCorrectness(t *testing.T) {
 
 }
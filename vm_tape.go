@@ -27,7 +27,7 @@ type tapeMachine struct {
 	tabcount   int
 	logFlags   byte
 
-	runFlags byte //  spare2: trace(copy values and put into nodes)
+	runFlags byte //  spare2: trace(copy values and put into nodes), inferMode: run modal ops in inference mode
 }
 
 func NewTapeMachine(prog *program, locMap map[*Node]register, opts ...VMOpt) *tapeMachine {
@@ -79,6 +79,10 @@ func (m *tapeMachine) trace() bool { return (m.runFlags>>spare2)&byte(1) == 1 }
 func (m *tapeMachine) doTrace()    { m.runFlags |= byte(1) << spare2 }
 func (m *tapeMachine) dontTrace()  { m.runFlags &= (^(byte(1) << spare2)) }
 
+func (m *tapeMachine) infer() bool { return (m.runFlags>>inferMode)&byte(1) == 1 }
+func (m *tapeMachine) doInfer()    { m.runFlags |= byte(1) << inferMode }
+func (m *tapeMachine) dontInfer()  { m.runFlags &= (^(byte(1) << inferMode)) }
+
 // Let wraps the Let() function of the package, with additional checks that n is in the machine
 func (m *tapeMachine) Let(n *Node, be interface{}) (err error) {
 	if !m.p.g.Has(n) {
@@ -461,6 +465,10 @@ func (instr execOp) exec(m *tapeMachine) (err error) {
 	}
 	m.leaveLoggingContext()
 
+	if mo, ok := instr.op.(modalOp); ok {
+		mo.setTraining(!m.infer())
+	}
+
 	// Execute
 	var v Value
 	switch {
@@ -51,3 +51,31 @@ func TestTapeVMPutsGrads(t *testing.T) {
 	assert.Equal(xdv.d, grads[0].boundTo)
 	assert.Equal(ydv.d, grads[1].boundTo)
 }
+
+func TestTapeVMKeepsGrads(t *testing.T) {
+	assert := assert.New(t)
+	g := NewGraph()
+	x := NewVector(g, Float64, WithName("x"), WithShape(3), WithInit(RangedFrom(0)))
+	y := NewVector(g, Float64, WithName("y"), WithShape(3), WithInit(RangedFrom(0)))
+
+	// the gradient of x is the gradient of x-y, which the gradient of y (its negation) must not overwrite
+	cost := Must(Sum(Must(Sub(x, y))))
+	if _, err := Grad(cost, x, y); err != nil {
+		t.Fatal(err)
+	}
+
+	prog, locMap, err := Compile(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machine := NewTapeMachine(prog, locMap)
+	if err = machine.RunAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	xG, _ := x.Grad()
+	yG, _ := y.Grad()
+	assert.Equal([]float64{1, 1, 1}, extractF64s(xG))
+	assert.Equal([]float64{-1, -1, -1}, extractF64s(yG))
+}